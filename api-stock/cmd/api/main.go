@@ -4,14 +4,17 @@ import (
 	_ "api-stock/docs"
 	"api-stock/internal/config"
 	httpservice "api-stock/internal/delivery/http"
+	"api-stock/internal/domain"
 	"api-stock/internal/repository"
 	"api-stock/internal/repository/api"
 	"api-stock/internal/repository/cockroachdb"
+	"api-stock/internal/repository/migrate"
 	"api-stock/internal/service"
 	"api-stock/pkg/errors"
 	"api-stock/pkg/logger"
 	"api-stock/pkg/metrics"
 	"context"
+	stderrors "errors"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"net/http"
@@ -77,26 +80,78 @@ func main() {
 
 	// 5. Ejecutar migraciones
 	logger.Logger.Info("Ejecutando migraciones...")
-	if err := repository.RunMigrations(db); err != nil {
+	migrator, err := migrate.New(db)
+	if err != nil {
+		logger.Logger.Fatal("Error al cargar migraciones", zap.Error(err))
+	}
+	if err := migrator.Up(context.Background()); err != nil {
 		logger.Logger.Fatal("Error al ejecutar migraciones", zap.Error(err))
 	}
 
 	// 6. Inicializar repositorios
 	logger.Logger.Info("Inicializando repositorios...")
 	stockRepo := repository.NewStockRepository(db)
-	apiClient := api.NewRecommendationClient(cfg.APIToken, cfg.APIBaseURL)
+	brokerageRepo := repository.NewBrokerageRepository(db)
+	checkpointRepo := repository.NewCheckpointRepository(db)
+	syncRunRepo := repository.NewSyncRunRepository(db)
+	quarantineRepo := repository.NewQuarantineRepository(db)
+	archiveRepo := repository.NewArchiveRepository(db)
+	leaseRepo := repository.NewLeaseRepository(db)
+	securityRepo := repository.NewSecurityRepository(db)
+	quotaRepo := repository.NewQuotaRepository(db)
+	providerConfigs, err := cfg.LoadProviders()
+	if err != nil {
+		logger.Logger.Fatal("Error al cargar proveedores", zap.Error(err))
+	}
+	providers, err := api.NewRegistryFromConfig(providerConfigs, api.RetryConfig{
+		MaxRetries:   cfg.MaxRetries,
+		InitialDelay: cfg.InitialDelay,
+		MaxDelay:     cfg.RetryMaxDelay,
+	}, api.FixtureConfig{RecordDir: cfg.APIRecordDir, ReplayDir: cfg.APIReplayDir}, quotaRepo)
+	if err != nil {
+		logger.Logger.Fatal("Error al cargar proveedores", zap.Error(err))
+	}
 
 	// 7. Inicializar servicios
 	logger.Logger.Info("Inicializando servicios...")
-	stockService := service.NewStockService(stockRepo)
-	recommendationService := service.NewRecommendationService(stockRepo)
-	apiService := service.NewExternalAPIService(apiClient, stockRepo)
+	ratingAliases, err := cfg.LoadRatingAliases()
+	if err != nil {
+		logger.Logger.Fatal("Error al cargar alias de calificaciones", zap.Error(err))
+	}
+	ratings, err := domain.NewRatingNormalizer(ratingAliases)
+	if err != nil {
+		logger.Logger.Fatal("Error en alias de calificaciones", zap.Error(err))
+	}
+	stockService := service.NewStockService(stockRepo, securityRepo, ratings)
+	recommendationService := service.NewRecommendationService(stockRepo, brokerageRepo)
+	brokerageService := service.NewBrokerageService(brokerageRepo)
+	securityService := service.NewSecurityService(securityRepo)
+	syncRunService := service.NewSyncRunService(syncRunRepo)
+	exportService := service.NewExportService(stockRepo, ratings)
+	quarantineService := service.NewQuarantineService(quarantineRepo, stockRepo, service.NewNormalizer(ratings, brokerageRepo), providers.Providers())
+	// Los reingresos escriben en recommendations: toman el lease de sincronización sin esperar
+	quarantineService = service.NewLeasedQuarantineService(quarantineService, leaseRepo, service.LeaseConfig{TTL: cfg.SyncLeaseTTL})
+	if cfg.SyncPrefetchPages < 0 {
+		logger.Logger.Fatal("SYNC_PREFETCH_PAGES no puede ser negativo", zap.Int("prefetch", cfg.SyncPrefetchPages))
+	}
+	syncConfig := service.DefaultSyncConfig()
+	syncConfig.MinRowRatio = cfg.SyncMinRowRatio
+	syncConfig.Prefetch = cfg.SyncPrefetchPages
+	syncConfig.CheckpointMaxAge = cfg.SyncCheckpointMaxAge
+	syncConfig.WatermarkOverlap = cfg.SyncWatermarkOverlap
+	syncConfig.LookbackPages = cfg.SyncLookbackPages
+	syncConfig.FullSyncMaxAge = cfg.SyncFullMaxAge
+	apiService := service.NewProviderSyncService(providers.Providers(), stockRepo, checkpointRepo, syncRunRepo, quarantineRepo, archiveRepo, service.NewNormalizer(ratings, brokerageRepo), syncConfig)
+	// Un solo proceso sincroniza a la vez (réplicas del worker y sincronización inicial de cmd/api)
+	apiService = service.NewLeasedSyncService(apiService, leaseRepo, service.LeaseConfig{TTL: cfg.SyncLeaseTTL, Wait: cfg.SyncLeaseWait})
 
 	// 8. Sincronización inicial de datos
 	logger.Logger.Info("Ejecutando sincronización inicial con la API externa...")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := apiService.SyncRecommendations(ctx); err != nil {
+	if err := apiService.SyncRecommendations(ctx); stderrors.Is(err, domain.ErrLeaseHeld) {
+		logger.Logger.Info("Sincronización inicial omitida: otro proceso está sincronizando", zap.Error(err))
+	} else if err != nil {
 		logger.Logger.Error("Error durante la sincronización inicial", zap.Error(err))
 	} else {
 		logger.Logger.Info("Sincronización inicial completada")
@@ -117,7 +172,12 @@ func main() {
 
 	// 11. Configurar rutas
 	logger.Logger.Info("Configurando rutas HTTP...")
-	httpservice.SetupRoutes(router, stockService, recommendationService)
+	httpservice.SetupRoutes(router, stockService, recommendationService, exportService, cfg.ExportTimeout)
+	if cfg.AdminToken != "" {
+		httpservice.SetupAdminRoutes(router, cfg.AdminToken, brokerageService, securityService, syncRunService, quarantineService)
+	} else {
+		logger.Logger.Warn("ADMIN_TOKEN no configurado: endpoints de administración deshabilitados")
+	}
 
 	// 12. Rutas adicionales
 	router.GET("/metrics", gin.WrapH(metrics.Handler()))
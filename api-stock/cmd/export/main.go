@@ -0,0 +1,124 @@
+package main
+
+import (
+	"api-stock/internal/config"
+	"api-stock/internal/domain"
+	"api-stock/internal/repository"
+	"api-stock/internal/repository/cockroachdb"
+	"api-stock/internal/service"
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const usage = `Uso: export [opciones]
+
+Escribe las recomendaciones que cumplen los filtros en CSV, NDJSON o Parquet, de la más reciente a la
+más antigua, leyéndolas de la base de datos con un cursor.
+
+Opciones:`
+
+func main() {
+	format := flag.String("format", "", "Formato: csv, ndjson o parquet (por defecto según la extensión de -o, o csv)")
+	output := flag.String("o", "-", `Archivo de salida ("-" escribe en la salida estándar)`)
+	ticker := flag.String("ticker", "", "Símbolo del ticker")
+	brokerage := flag.String("brokerage", "", "ID o nombre de la firma de corretaje")
+	rating := flag.String("rating", "", "Calificación canónica o alias (ejemplo: outperform)")
+	sector := flag.String("sector", "", "Sector del registro maestro de valores")
+	source := flag.String("source", "", "Proveedor de datos")
+	from := flag.String("from", "", "Inicio del rango, inclusive (2006-01-02 o RFC 3339)")
+	to := flag.String("to", "", "Fin del rango (una fecha incluye el día completo; RFC 3339 es exclusivo)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *format == "" && *output != "-" {
+		*format = strings.TrimPrefix(filepath.Ext(*output), ".")
+	}
+	exportFormat, err := domain.ParseExportFormat(*format)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+	filter := domain.RecommendationFilter{
+		Ticker:    *ticker,
+		Brokerage: *brokerage,
+		Rating:    domain.Rating(*rating),
+		Sector:    *sector,
+		Source:    *source,
+	}
+	if filter.From, err = domain.ParseTimeBound(*from, false); err != nil {
+		log.Fatalf("Invalid -from: %v", err)
+	}
+	if filter.To, err = domain.ParseTimeBound(*to, true); err != nil {
+		log.Fatalf("Invalid -to: %v", err)
+	}
+
+	// Configuración
+	cfg := config.Load()
+
+	// Conexión a la base de datos
+	db, err := cockroachdb.Connect(cfg.DBURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Diccionario de calificaciones para interpretar el filtro de calificación
+	ratingAliases, err := cfg.LoadRatingAliases()
+	if err != nil {
+		log.Fatalf("Failed to load rating aliases: %v", err)
+	}
+	ratings, err := domain.NewRatingNormalizer(ratingAliases)
+	if err != nil {
+		log.Fatalf("Invalid rating aliases: %v", err)
+	}
+	exportService := service.NewExportService(repository.NewStockRepository(db), ratings)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	rows, err := export(ctx, exportService, *output, exportFormat, filter)
+	if err != nil {
+		log.Fatalf("Export failed after %d rows: %v", rows, err)
+	}
+	log.Printf("Exported %d recommendations as %s", rows, exportFormat)
+}
+
+// export escribe la exportación en el archivo indicado (o en la salida estándar con "-").
+// Un archivo incompleto se elimina para no dejar exportaciones truncadas.
+func export(ctx context.Context, exportService domain.ExportService, path string, format domain.ExportFormat, filter domain.RecommendationFilter) (int, error) {
+	if path == "-" {
+		out := bufio.NewWriter(os.Stdout)
+		rows, err := exportService.ExportRecommendations(ctx, out, format, filter)
+		if err != nil {
+			return rows, err
+		}
+		return rows, out.Flush()
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	out := bufio.NewWriter(file)
+	rows, err := exportService.ExportRecommendations(ctx, out, format, filter)
+	if err == nil {
+		err = out.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return rows, err
+}
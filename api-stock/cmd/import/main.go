@@ -0,0 +1,157 @@
+package main
+
+import (
+	"api-stock/internal/config"
+	"api-stock/internal/domain"
+	"api-stock/internal/repository"
+	"api-stock/internal/repository/cockroachdb"
+	"api-stock/internal/service"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+const usage = `Uso: import [opciones] <archivo>... ("-" lee de la entrada estándar)
+
+Carga archivos CSV, JSON o NDJSON de recomendaciones con la misma normalización e inserción que la
+sincronización. Sin -map, cada campo se busca con su nombre canónico:
+  ticker, target_from, target_to, currency, company, action, brokerage, rating_from, rating_to, time
+
+Opciones:`
+
+func main() {
+	format := flag.String("format", "", "Formato del archivo: csv, json o ndjson (por defecto según la extensión)")
+	mapping := flag.String("map", "", `Mapeo de columnas campo=origen separado por comas (ejemplo: "ticker=symbol,time=date")`)
+	timeLayout := flag.String("time-layout", "", `Formato Go de la fecha si no es RFC 3339 (ejemplo: "2006-01-02")`)
+	source := flag.String("source", service.ImportSource, "Proveedor con el que se guardan las recomendaciones")
+	dryRun := flag.Bool("dry-run", false, "Solo lee y valida los archivos, sin escribir en la base de datos")
+	rejects := flag.String("rejects", "", "Archivo CSV donde se escriben los registros rechazados")
+	batchSize := flag.Int("batch", service.DefaultSyncConfig().BatchSize, "Registros por lote de inserción")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fieldMapping, err := domain.ParseFieldMapping(*mapping)
+	if err != nil {
+		log.Fatalf("Invalid column mapping: %v", err)
+	}
+
+	// Configuración
+	cfg := config.Load()
+
+	// Conexión a la base de datos (también en dry-run, para validar la configuración antes de una carga real)
+	db, err := cockroachdb.Connect(cfg.DBURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Diccionario de calificaciones para normalizar lo que se ingiere
+	ratingAliases, err := cfg.LoadRatingAliases()
+	if err != nil {
+		log.Fatalf("Failed to load rating aliases: %v", err)
+	}
+	ratings, err := domain.NewRatingNormalizer(ratingAliases)
+	if err != nil {
+		log.Fatalf("Invalid rating aliases: %v", err)
+	}
+	importService := service.NewImportService(repository.NewStockRepository(db), service.NewNormalizer(ratings, repository.NewBrokerageRepository(db)))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var rejectsWriter *csv.Writer
+	if *rejects != "" {
+		file, err := os.Create(*rejects)
+		if err != nil {
+			log.Fatalf("Failed to create rejects report: %v", err)
+		}
+		defer file.Close()
+		rejectsWriter = csv.NewWriter(file)
+		rejectsWriter.Write([]string{"file", "row", "reason", "record"})
+	}
+
+	var total domain.ImportReport
+	failed := false
+	for _, path := range flag.Args() {
+		opts := domain.ImportOptions{
+			Format:     domain.ImportFormat(*format),
+			Mapping:    fieldMapping,
+			TimeLayout: *timeLayout,
+			Source:     *source,
+			DryRun:     *dryRun,
+			BatchSize:  *batchSize,
+		}
+		if opts.Format == "" {
+			detected, ok := domain.ImportFormatFromPath(path)
+			if !ok {
+				log.Fatalf("Cannot infer the format of %s; use -format", path)
+			}
+			opts.Format = detected
+		}
+
+		report, err := importFile(ctx, importService, path, opts)
+		if report != nil {
+			log.Printf("%s: read %d, accepted %d, rejected %d, inserted %d, updated %d, unchanged %d",
+				path, report.Read, report.Accepted, len(report.Rejected), report.Stats.Inserted, report.Stats.Updated, report.Stats.Skipped)
+			total.Read += report.Read
+			total.Accepted += report.Accepted
+			total.Stats.Add(report.Stats)
+			total.Rejected = append(total.Rejected, report.Rejected...)
+			if rejectsWriter != nil {
+				for _, rejected := range report.Rejected {
+					rejectsWriter.Write([]string{path, strconv.Itoa(rejected.Row), rejected.Reason, rejected.Raw})
+				}
+			}
+		}
+		if err != nil {
+			log.Printf("Import of %s failed: %v", path, err)
+			failed = true
+			break
+		}
+	}
+
+	if rejectsWriter != nil {
+		rejectsWriter.Flush()
+		if err := rejectsWriter.Error(); err != nil {
+			log.Printf("Failed to write rejects report: %v", err)
+		}
+	}
+
+	mode := "Import"
+	if *dryRun {
+		mode = "Dry run"
+	}
+	log.Printf("%s finished: read %d, accepted %d, rejected %d, inserted %d, updated %d, unchanged %d",
+		mode, total.Read, total.Accepted, len(total.Rejected), total.Stats.Inserted, total.Stats.Updated, total.Stats.Skipped)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// importFile abre el archivo (o la entrada estándar con "-") y lo importa.
+func importFile(ctx context.Context, importService domain.ImportService, path string, opts domain.ImportOptions) (*domain.ImportReport, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+	return importService.ImportRecommendations(ctx, r, opts)
+}
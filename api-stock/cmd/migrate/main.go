@@ -0,0 +1,109 @@
+package main
+
+import (
+	"api-stock/internal/config"
+	"api-stock/internal/repository/cockroachdb"
+	"api-stock/internal/repository/migrate"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Uso: migrate <comando> [argumento]
+
+Comandos:
+  up               Aplica todas las migraciones pendientes
+  down [n]         Revierte las últimas n migraciones (por defecto 1)
+  status           Muestra el estado de cada migración
+  goto <versión>   Aplica o revierte migraciones hasta la versión indicada
+  force <versión>  Marca la versión como aplicada sin ejecutar SQL (tras reparar una migración dirty)`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	// Configuración
+	cfg := config.Load()
+
+	// Conexión a la base de datos
+	db, err := cockroachdb.Connect(cfg.DBURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	switch command {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil {
+				log.Fatalf("Invalid number of steps: %s", os.Args[2])
+			}
+		}
+		err = migrator.Down(ctx, steps)
+	case "goto", "force":
+		if len(os.Args) < 3 {
+			log.Fatalf("Command %s requires a version", command)
+		}
+		version, parseErr := strconv.ParseInt(os.Args[2], 10, 64)
+		if parseErr != nil {
+			log.Fatalf("Invalid version: %s", os.Args[2])
+		}
+		if command == "goto" {
+			err = migrator.Goto(ctx, version)
+		} else {
+			err = migrator.Force(ctx, version)
+		}
+	case "status":
+		err = printStatus(ctx, migrator)
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("Migration command %s failed: %v", command, err)
+	}
+}
+
+// printStatus muestra una tabla con el estado de cada migración.
+func printStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Dirty {
+			state = "dirty"
+		} else if s.Applied {
+			state = "applied"
+		}
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Valores con los que se generan recomendaciones verosímiles.
+var (
+	companies = []struct{ ticker, name string }{
+		{"AAPL", "Apple Inc."}, {"MSFT", "Microsoft Corporation"}, {"NVDA", "NVIDIA Corporation"},
+		{"AMZN", "Amazon.com, Inc."}, {"GOOGL", "Alphabet Inc."}, {"META", "Meta Platforms, Inc."},
+		{"TSLA", "Tesla, Inc."}, {"JPM", "JPMorgan Chase & Co."}, {"V", "Visa Inc."},
+		{"XOM", "Exxon Mobil Corporation"}, {"UNH", "UnitedHealth Group Incorporated"}, {"KO", "The Coca-Cola Company"},
+	}
+	brokerages = []string{
+		"Goldman Sachs", "Morgan Stanley", "JPMorgan Chase & Co.", "Barclays", "Wells Fargo & Company",
+		"The Goldman Sachs Group", "Citigroup", "UBS Group", "Raymond James", "Needham & Company LLC",
+	}
+	ratings = []string{"Buy", "Outperform", "Overweight", "Neutral", "Market Perform", "Equal Weight", "Underweight", "Sell"}
+)
+
+// generateRecords genera count registros, más recientes primero, el primero en now y cada uno
+// entre 1 y 90 minutos anterior al siguiente.
+func generateRecords(rng *rand.Rand, count int, now time.Time) []record {
+	records := make([]record, 0, count)
+	at := now
+	for i := 0; i < count; i++ {
+		records = append(records, generateRecord(rng, at))
+		at = at.Add(-time.Duration(1+rng.Intn(90)) * time.Minute)
+	}
+	return records
+}
+
+// generateRecord genera un registro con el formato del proveedor original en el momento at.
+func generateRecord(rng *rand.Rand, at time.Time) record {
+	company := companies[rng.Intn(len(companies))]
+	from := rng.Intn(len(ratings))
+	to := from
+	target := 20 + rng.Float64()*480
+	change := 1 + rng.Float64()*0.15 // El objetivo sube salvo en "target lowered by"
+	action := "target raised by"
+	switch rng.Intn(5) {
+	case 0:
+		action, change = "target lowered by", 1/change
+	case 1:
+		action = "reiterated by"
+	case 2:
+		if from > 0 {
+			to, action = from-1, "upgraded by"
+		}
+	case 3:
+		if from < len(ratings)-1 {
+			to, action = from+1, "downgraded by"
+		}
+	}
+
+	return record{
+		"ticker":      company.ticker,
+		"target_from": formatPrice(target),
+		"target_to":   formatPrice(target * change),
+		"company":     company.name,
+		"action":      action,
+		"brokerage":   brokerages[rng.Intn(len(brokerages))],
+		"rating_from": ratings[from],
+		"rating_to":   ratings[to],
+		"time":        at.UTC().Truncate(time.Second).Format(time.RFC3339Nano),
+	}
+}
+
+// formatPrice escribe un precio como el proveedor original ("$1,234.50").
+func formatPrice(value float64) string {
+	cents := int64(value*100 + 0.5)
+	whole, frac := cents/100, cents%100
+	digits := fmt.Sprint(whole)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return fmt.Sprintf("$%s.%02d", digits, frac)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const usage = `Uso: mockprovider [opciones]
+
+Sirve recomendaciones con el contrato del proveedor original ({"items": [...], "next_page": "..."}), más
+recientes primero, para ejecutar cmd/api y cmd/worker sin el proveedor real. Los registros se leen de un
+archivo de fixtures (un arreglo JSON de registros del proveedor, o páginas {"items": [...]}) o se generan.
+Permite simular latencia, errores (429, 500, JSON malformado) y comprobar el token.
+
+Ejemplo:
+  go run ./cmd/mockprovider -token dev -error-rate 0.05
+  API_BASE_URL=http://localhost:9090/recommendations API_TOKEN=dev go run ./cmd/worker
+
+Opciones:`
+
+func main() {
+	addr := flag.String("addr", ":9090", "Dirección en la que escucha el servidor")
+	path := flag.String("path", "/recommendations", "Ruta del listado de recomendaciones")
+	fixtures := flag.String("fixtures", "", "Archivo JSON con los registros a servir (por defecto se generan)")
+	count := flag.Int("generate", 500, "Registros a generar si no hay -fixtures")
+	seed := flag.Int64("seed", 1, "Semilla de los datos generados y de la inyección de errores")
+	pageSize := flag.Int("page-size", 20, "Registros por página")
+	token := flag.String("token", "", `Token esperado en "Authorization: Bearer <token>" (vacío no lo comprueba)`)
+	latency := flag.Duration("latency", 0, "Latencia añadida a cada respuesta")
+	latencyJitter := flag.Duration("latency-jitter", 0, "Latencia aleatoria adicional máxima")
+	errorRate := flag.Float64("error-rate", 0, "Proporción de peticiones que fallan (0 a 1)")
+	errorKinds := flag.String("errors", "429,500,malformed", "Errores a inyectar separados por comas: 429, 500, malformed")
+	retryAfter := flag.Duration("retry-after", time.Second, "Retry-After de las respuestas 429 (0 no lo envía)")
+	feed := flag.Duration("feed", 0, "Publica una recomendación nueva con este intervalo (0 lo desactiva)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *pageSize <= 0 {
+		log.Fatalf("Invalid -page-size %d", *pageSize)
+	}
+	if *errorRate < 0 || *errorRate > 1 {
+		log.Fatalf("Invalid -error-rate %v", *errorRate)
+	}
+	kinds, err := parseErrorKinds(*errorKinds)
+	if err != nil {
+		log.Fatalf("Invalid -errors: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	var records []record
+	if *fixtures != "" {
+		if records, err = loadFixtures(*fixtures); err != nil {
+			log.Fatalf("Failed to load fixtures: %v", err)
+		}
+	} else {
+		records = generateRecords(rng, *count, time.Now())
+	}
+
+	provider := &mockProvider{
+		records:       newestLast(records),
+		generator:     rng,
+		pageSize:      *pageSize,
+		token:         *token,
+		latency:       *latency,
+		latencyJitter: *latencyJitter,
+		errorRate:     *errorRate,
+		errorKinds:    kinds,
+		retryAfter:    *retryAfter,
+		rng:           rand.New(rand.NewSource(*seed + 1)),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(*path, provider)
+	srv := &http.Server{Addr: *addr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *feed > 0 {
+		go provider.publish(ctx, *feed)
+	}
+
+	go func() {
+		log.Printf("Mock provider serving %d records on %s%s", len(records), *addr, *path)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start mock provider: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Mock provider is shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Mock provider forced to shutdown: %v", err)
+	}
+}
+
+// parseErrorKinds lee la lista de errores a inyectar.
+func parseErrorKinds(raw string) ([]string, error) {
+	var kinds []string
+	for _, kind := range strings.Split(raw, ",") {
+		kind = strings.TrimSpace(kind)
+		switch kind {
+		case "":
+			continue
+		case errorTooManyRequests, errorServer, errorMalformed:
+			kinds = append(kinds, kind)
+		default:
+			return nil, fmt.Errorf("error desconocido %q (válidos: 429, 500, malformed)", kind)
+		}
+	}
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("la lista está vacía")
+	}
+	return kinds, nil
+}
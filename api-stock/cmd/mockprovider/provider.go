@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Errores que se pueden inyectar.
+const (
+	errorTooManyRequests = "429"       // 429 con Retry-After
+	errorServer          = "500"       // 500
+	errorMalformed       = "malformed" // 200 con un cuerpo JSON truncado
+)
+
+// record es un registro tal como lo envía el proveedor.
+type record = map[string]interface{}
+
+// page es la respuesta del proveedor original.
+type page struct {
+	Items    []record `json:"items"`
+	NextPage string   `json:"next_page"`
+}
+
+// mockProvider sirve los registros paginados, del más reciente al más antiguo.
+// El token de la siguiente página es la posición (en records) del registro siguiente más uno; los
+// registros publicados durante una sincronización no desplazan las páginas que faltan por leer.
+type mockProvider struct {
+	mu        sync.Mutex
+	records   []record   // Registros del más antiguo al más reciente
+	generator *rand.Rand // Generador de los registros publicados con -feed
+
+	pageSize      int
+	token         string
+	latency       time.Duration
+	latencyJitter time.Duration
+	errorRate     float64
+	errorKinds    []string
+	retryAfter    time.Duration
+	rng           *rand.Rand // Latencia e inyección de errores
+}
+
+// ServeHTTP responde una página del listado.
+func (p *mockProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if p.token != "" && r.Header.Get("Authorization") != "Bearer "+p.token {
+		log.Printf("%s %s -> 401", r.Method, r.URL.RequestURI())
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	delay, failure := p.draw()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	switch failure {
+	case errorTooManyRequests:
+		if p.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((p.retryAfter+time.Second-1)/time.Second)))
+		}
+		log.Printf("%s %s -> 429 (injected)", r.Method, r.URL.RequestURI())
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	case errorServer:
+		log.Printf("%s %s -> 500 (injected)", r.Method, r.URL.RequestURI())
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	case errorMalformed:
+		log.Printf("%s %s -> 200 malformed (injected)", r.Method, r.URL.RequestURI())
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"items": [{"ticker": "AAPL", "target_from": "$1`)
+		return
+	}
+
+	resp, err := p.page(r.URL.Query().Get("next_page"))
+	if err != nil {
+		log.Printf("%s %s -> 400 (%v)", r.Method, r.URL.RequestURI(), err)
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Printf("%s %s -> 200 (%d items, next_page=%q)", r.Method, r.URL.RequestURI(), len(resp.Items), resp.NextPage)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+// writeError responde un error con cuerpo {"error": message}.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// draw decide la latencia y el error inyectado ("" si ninguno) de una petición.
+func (p *mockProvider) draw() (time.Duration, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delay := p.latency
+	if p.latencyJitter > 0 {
+		delay += time.Duration(p.rng.Int63n(int64(p.latencyJitter) + 1))
+	}
+	if p.errorRate > 0 && p.rng.Float64() < p.errorRate {
+		return delay, p.errorKinds[p.rng.Intn(len(p.errorKinds))]
+	}
+	return delay, ""
+}
+
+// page devuelve la página que termina en la posición del token (vacío es la primera página).
+func (p *mockProvider) page(token string) (page, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	end := len(p.records)
+	if token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil || n < 1 || n > len(p.records) {
+			return page{}, fmt.Errorf("next_page inválido %q", token)
+		}
+		end = n
+	}
+	start := end - p.pageSize
+	if start < 0 {
+		start = 0
+	}
+
+	resp := page{Items: make([]record, 0, end-start)}
+	for i := end - 1; i >= start; i-- {
+		resp.Items = append(resp.Items, p.records[i])
+	}
+	if start > 0 {
+		resp.NextPage = strconv.Itoa(start)
+	}
+	return resp, nil
+}
+
+// publish añade una recomendación nueva cada interval, hasta que se cancele ctx.
+func (p *mockProvider) publish(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.mu.Lock()
+			rec := generateRecord(p.generator, now)
+			p.records = append(p.records, rec)
+			p.mu.Unlock()
+			log.Printf("Published %s from %s", rec["ticker"], rec["brokerage"])
+		}
+	}
+}
+
+// loadFixtures lee los registros de un archivo JSON, más recientes primero: un arreglo de registros,
+// una página {"items": [...]} o un arreglo de páginas.
+func loadFixtures(path string) ([]record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo fixtures: %v", err)
+	}
+
+	var single page
+	if err := json.Unmarshal(data, &single); err == nil {
+		return single.Items, nil
+	}
+	var items []record
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("error decodificando fixtures: %v", err)
+	}
+	var records []record
+	for _, item := range items {
+		// Un arreglo de páginas: cada elemento trae sus registros en items
+		if nested, ok := item["items"].([]interface{}); ok && len(item) <= 2 {
+			for _, raw := range nested {
+				if rec, ok := raw.(record); ok {
+					records = append(records, rec)
+				}
+			}
+			continue
+		}
+		records = append(records, item)
+	}
+	return records, nil
+}
+
+// newestLast invierte los registros, que llegan más recientes primero.
+func newestLast(records []record) []record {
+	reversed := make([]record, len(records))
+	for i, rec := range records {
+		reversed[len(records)-1-i] = rec
+	}
+	return reversed
+}
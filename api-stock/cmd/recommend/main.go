@@ -24,7 +24,8 @@ func main() {
 
 	// Inicializar repositorio y servicio
 	stockRepo := repository.NewStockRepository(db)
-	recommendationService := service.NewRecommendationService(stockRepo)
+	brokerageRepo := repository.NewBrokerageRepository(db)
+	recommendationService := service.NewRecommendationService(stockRepo, brokerageRepo)
 
 	// Obtener las mejores recomendaciones
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -42,8 +43,8 @@ func main() {
 		fmt.Printf("%d. %s (%s)\n", i+1, rec.Ticker, rec.Company)
 		fmt.Printf("   Recommendation: %s -> %s\n", rec.RatingFrom, rec.RatingTo)
 		fmt.Printf("   Broker: %s, Action: %s\n", rec.Brokerage, rec.Action)
-		fmt.Printf("   Target: %s - %s, Date: %s\n\n",
-			rec.TargetFrom, rec.TargetTo, rec.Time.Format("2006-01-02"))
+		fmt.Printf("   Target: %s - %s %s, Date: %s\n\n",
+			rec.TargetFrom, rec.TargetTo, rec.Currency, rec.Time.Format("2006-01-02"))
 	}
 
 	// Mostrar acciones similares para la primera recomendación
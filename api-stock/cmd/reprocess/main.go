@@ -0,0 +1,101 @@
+package main
+
+import (
+	"api-stock/internal/config"
+	"api-stock/internal/domain"
+	"api-stock/internal/repository"
+	"api-stock/internal/repository/api"
+	"api-stock/internal/repository/cockroachdb"
+	"api-stock/internal/service"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const usage = `Uso: reprocess [opciones]
+
+Reconstruye las recomendaciones de cada proveedor a partir de sus páginas archivadas, aplicando el mapeo
+de campos, la validación y la normalización actuales. Se usan las páginas recibidas desde la última
+sincronización completa que terminó bien; el resultado se valida y reemplaza de una vez las filas del
+proveedor, igual que una sincronización completa. Toma el lease de sincronización, así que espera
+(hasta -wait) a que termine la sincronización del worker en curso.
+
+Opciones:`
+
+func main() {
+	sources := flag.String("source", "", "Proveedores a reprocesar separados por comas (por defecto todos)")
+	wait := flag.Duration("wait", 10*time.Minute, "Espera máxima a que termine otra sincronización en curso")
+	minRowRatio := flag.Float64("min-ratio", -1, "Filas reconstruidas / filas actuales mínimas para aplicar el resultado (por defecto SYNC_MIN_ROW_RATIO; 0 desactiva)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Configuración
+	cfg := config.Load()
+
+	providerConfigs, err := cfg.LoadProviders()
+	if err != nil {
+		log.Fatalf("Failed to load providers: %v", err)
+	}
+	// Solo se usan para decodificar el archivo: no hacen peticiones ni consumen cuota
+	registry, err := api.NewRegistryFromConfig(providerConfigs, api.DefaultRetryConfig(), api.FixtureConfig{}, nil)
+	if err != nil {
+		log.Fatalf("Failed to load providers: %v", err)
+	}
+	providers := registry.Providers()
+	if *sources != "" {
+		providers = nil
+		for _, name := range strings.Split(*sources, ",") {
+			provider, ok := registry.Get(strings.TrimSpace(name))
+			if !ok {
+				log.Fatalf("Unknown provider %q", name)
+			}
+			providers = append(providers, provider)
+		}
+	}
+
+	// Conexión a la base de datos
+	db, err := cockroachdb.Connect(cfg.DBURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Diccionario de calificaciones para normalizar lo que se reconstruye
+	ratingAliases, err := cfg.LoadRatingAliases()
+	if err != nil {
+		log.Fatalf("Failed to load rating aliases: %v", err)
+	}
+	ratings, err := domain.NewRatingNormalizer(ratingAliases)
+	if err != nil {
+		log.Fatalf("Invalid rating aliases: %v", err)
+	}
+
+	stockRepo := repository.NewStockRepository(db)
+	syncConfig := service.DefaultSyncConfig()
+	syncConfig.MinRowRatio = cfg.SyncMinRowRatio
+	if *minRowRatio >= 0 {
+		syncConfig.MinRowRatio = *minRowRatio
+	}
+	syncConfig.CheckpointMaxAge = cfg.SyncCheckpointMaxAge
+	syncService := service.NewProviderSyncService(providers, stockRepo, repository.NewCheckpointRepository(db),
+		repository.NewSyncRunRepository(db), repository.NewQuarantineRepository(db), repository.NewArchiveRepository(db),
+		service.NewNormalizer(ratings, repository.NewBrokerageRepository(db)), syncConfig)
+	syncService = service.NewLeasedSyncService(syncService, repository.NewLeaseRepository(db), service.LeaseConfig{TTL: cfg.SyncLeaseTTL, Wait: *wait})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := syncService.ReprocessArchive(ctx); err != nil {
+		log.Fatalf("Reprocess failed: %v", err)
+	}
+	log.Println("Reprocess completed successfully")
+}
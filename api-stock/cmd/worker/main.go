@@ -2,23 +2,35 @@ package main
 
 import (
 	"api-stock/internal/config"
+	httpservice "api-stock/internal/delivery/http"
+	"api-stock/internal/domain"
 	"api-stock/internal/repository"
 	"api-stock/internal/repository/api"
 	"api-stock/internal/repository/cockroachdb"
+	"api-stock/internal/repository/migrate"
 	"api-stock/internal/service"
+	"api-stock/pkg/errors"
+	"api-stock/pkg/metrics"
 	"context"
 	_ "database/sql"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	// Configuración
 	cfg := config.Load()
 
+	// Métricas: se registran antes de crear los clientes, que publican su presupuesto de peticiones, y
+	// de arrancar los trabajos
+	metrics.Init()
+
 	// Conexión a la base de datos
 	db, err := cockroachdb.Connect(cfg.DBURL)
 	if err != nil {
@@ -26,42 +38,129 @@ func main() {
 	}
 	defer db.Close()
 
+	// Migraciones (protegidas por lock si cmd/api arranca al mismo tiempo)
+	migrator, err := migrate.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
 	// Inicializar repositorios
 	stockRepo := repository.NewStockRepository(db)
-	apiClient := api.NewRecommendationClient(cfg.APIToken, cfg.APIBaseURL)
+	brokerageRepo := repository.NewBrokerageRepository(db)
+	checkpointRepo := repository.NewCheckpointRepository(db)
+	syncRunRepo := repository.NewSyncRunRepository(db)
+	quarantineRepo := repository.NewQuarantineRepository(db)
+	archiveRepo := repository.NewArchiveRepository(db)
+	leaseRepo := repository.NewLeaseRepository(db)
+	jobRunRepo := repository.NewJobRunRepository(db)
+	quotaRepo := repository.NewQuotaRepository(db)
+	scoreSnapshotRepo := repository.NewScoreSnapshotRepository(db)
+	providerConfigs, err := cfg.LoadProviders()
+	if err != nil {
+		log.Fatalf("Failed to load providers: %v", err)
+	}
+	providers, err := api.NewRegistryFromConfig(providerConfigs, api.RetryConfig{
+		MaxRetries:   cfg.MaxRetries,
+		InitialDelay: cfg.InitialDelay,
+		MaxDelay:     cfg.RetryMaxDelay,
+	}, api.FixtureConfig{RecordDir: cfg.APIRecordDir, ReplayDir: cfg.APIReplayDir}, quotaRepo)
+	if err != nil {
+		log.Fatalf("Failed to load providers: %v", err)
+	}
+
+	// Diccionario de calificaciones para normalizar lo que se ingiere
+	ratingAliases, err := cfg.LoadRatingAliases()
+	if err != nil {
+		log.Fatalf("Failed to load rating aliases: %v", err)
+	}
+	ratings, err := domain.NewRatingNormalizer(ratingAliases)
+	if err != nil {
+		log.Fatalf("Invalid rating aliases: %v", err)
+	}
 
-	// Inicializar servicio
-	apiService := service.NewExternalAPIService(apiClient, stockRepo)
+	// Inicializar servicio; el tracker conserva el progreso de las ejecuciones para el endpoint de estado
+	runTracker := service.NewSyncRunTracker(syncRunRepo)
+	if cfg.SyncPrefetchPages < 0 {
+		log.Fatalf("Invalid SYNC_PREFETCH_PAGES %d: must be 0 or more", cfg.SyncPrefetchPages)
+	}
+	syncConfig := service.DefaultSyncConfig()
+	syncConfig.MinRowRatio = cfg.SyncMinRowRatio
+	syncConfig.Prefetch = cfg.SyncPrefetchPages
+	syncConfig.CheckpointMaxAge = cfg.SyncCheckpointMaxAge
+	syncConfig.WatermarkOverlap = cfg.SyncWatermarkOverlap
+	syncConfig.LookbackPages = cfg.SyncLookbackPages
+	syncConfig.FullSyncMaxAge = cfg.SyncFullMaxAge
+	apiService := service.NewProviderSyncService(providers.Providers(), stockRepo, checkpointRepo, runTracker, quarantineRepo, archiveRepo, service.NewNormalizer(ratings, brokerageRepo), syncConfig)
+	// Un solo proceso sincroniza a la vez (réplicas del worker y sincronización inicial de cmd/api)
+	apiService = service.NewLeasedSyncService(apiService, leaseRepo, service.LeaseConfig{TTL: cfg.SyncLeaseTTL, Wait: cfg.SyncLeaseWait})
 
-	// Canal para manejar señales de terminación
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	// Trabajos programados: los del archivo WORKER_JOBS_FILE o, si no hay, completa al arrancar e incremental cada WORKER_INTERVAL
+	kinds := service.SyncJobKinds(apiService)
+	kinds[service.RetentionJobKind] = service.NewRetentionService(providers.Providers(), syncRunRepo, jobRunRepo, quarantineRepo, archiveRepo, cfg.RetentionMaxAge).Purge
+	kinds[service.ScoreSnapshotJobKind] = service.NewScoreSnapshotService(service.NewRecommendationService(stockRepo, brokerageRepo), scoreSnapshotRepo).SnapshotScores
+	jobs := service.DefaultJobs(cfg.WorkerInterval)
+	jobConfigs, err := cfg.LoadJobs()
+	if err != nil {
+		log.Fatalf("Failed to load worker jobs: %v", err)
+	}
+	if jobConfigs != nil {
+		jobs = make([]service.JobDefinition, 0, len(jobConfigs))
+		for _, job := range jobConfigs {
+			jobs = append(jobs, service.JobDefinition{
+				Name:       job.Name,
+				Kind:       job.Kind,
+				Schedule:   job.Schedule,
+				Timeout:    time.Duration(job.Timeout),
+				Jitter:     time.Duration(job.Jitter),
+				Overlap:    domain.OverlapPolicy(job.Overlap),
+				RunOnStart: job.RunOnStart,
+			})
+		}
+	}
+	scheduler, err := service.NewScheduler(jobs, kinds, runTracker, jobRunRepo)
+	if err != nil {
+		log.Fatalf("Invalid worker jobs: %v", err)
+	}
 
-	// Ticker para sincronización periódica
-	ticker := time.NewTicker(cfg.WorkerInterval)
-	defer ticker.Stop()
+	// Contexto cancelado por señales de terminación: cancela los trabajos en curso; una sincronización
+	// completa interrumpida se reanuda desde su checkpoint en el siguiente arranque
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	scheduler.Start(ctx)
 
-	// Sincronización inicial
-	if err := apiService.SyncRecommendations(context.Background()); err != nil {
-		log.Printf("Initial sync failed: %v", err)
+	// Servidor HTTP del worker: salud, métricas y control de trabajos
+	router := gin.New()
+	router.Use(gin.Recovery(), errors.ErrorHandler, metrics.PrometheusMiddleware())
+	httpservice.SetupWorkerRoutes(router, cfg.AdminToken, scheduler, stockRepo.Ping)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	if cfg.AdminToken == "" {
+		log.Println("ADMIN_TOKEN not set: worker job control endpoints disabled")
+	}
+	srv := &http.Server{
+		Addr:         ":" + cfg.WorkerPort,
+		Handler:      router,
+		ReadTimeout:  cfg.HTTPReadTimeout,
+		WriteTimeout: cfg.HTTPWriteTimeout,
+		IdleTimeout:  60 * time.Second,
 	}
+	go func() {
+		log.Printf("Worker HTTP server listening on :%s", cfg.WorkerPort)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start worker HTTP server: %v", err)
+		}
+	}()
 
 	log.Println("Worker started successfully")
+	<-ctx.Done()
 
-	// Bucle principal del worker
-	for {
-		select {
-		case <-ticker.C:
-			log.Println("Starting incremental sync...")
-			if err := apiService.IncrementalSync(context.Background()); err != nil {
-				log.Printf("Incremental sync failed: %v", err)
-			} else {
-				log.Println("Incremental sync completed successfully")
-			}
-
-		case <-done:
-			log.Println("Worker is shutting down...")
-			return
-		}
+	log.Println("Worker is shutting down...")
+	scheduler.Wait() // Espera a que los trabajos cancelados cierren su registro
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down worker HTTP server: %v", err)
 	}
 }
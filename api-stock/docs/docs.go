@@ -77,6 +77,830 @@ const docTemplate = `{
                 }
             }
         },
+        "/http/v1/admin/brokerages": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the brokerage master data with aliases and reputation weights",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List brokerages",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Brokerage"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/brokerages/unresolved": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List provider brokerage names that did not match any alias during ingestion, most frequent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List unresolved brokerage names",
+                "parameters": [
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of names",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.UnresolvedBrokerage"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/brokerages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a brokerage by canonical ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a brokerage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Brokerage ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Brokerage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a brokerage with the given canonical ID, or update its name and reputation weight",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create or update a brokerage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Brokerage ID (lowercase letters, digits and underscores)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Brokerage data",
+                        "name": "brokerage",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.BrokerageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Brokerage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/brokerages/{id}/aliases": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Map a provider brokerage name to the brokerage; existing unresolved records with that name are linked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Add a brokerage alias",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Brokerage ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Alias to add",
+                        "name": "alias",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.AliasRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Brokerage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/brokerages/{id}/aliases/{alias}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove an alias from the brokerage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove a brokerage alias",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Brokerage ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Alias to remove (URL-encoded)",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Brokerage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/quarantine": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List provider records rejected by ingestion validation, most recently seen first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List quarantined records",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "reingested",
+                            "discarded"
+                        ],
+                        "type": "string",
+                        "default": "pending",
+                        "description": "Record status; empty lists every status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data provider name",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of records",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.QuarantinedRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/quarantine/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a quarantined record by ID, with its rejection reason and provider payload",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a quarantined record",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quarantined record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuarantinedRecord"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the provider payload of a pending record. The reason is recomputed with the validation rules and is empty once the payload can be reingested.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Fix a quarantined record",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quarantined record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Corrected provider record, with the provider's field names",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuarantinedRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/quarantine/{id}/discard": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Mark a pending record as discarded; it stays discarded if the provider sends it again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Discard a quarantined record",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quarantined record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuarantinedRecord"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/quarantine/{id}/reingest": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Decode the payload with the provider field mapping, validate it and store it as a recommendation. Later syncs keep using the corrected payload when the provider sends the original record again. The reingest takes the sync lease and fails with 409 while a sync is running.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reingest a quarantined record",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quarantined record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StockRecommendation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/securities/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create or update securities master data from a CSV with header. Columns: ticker (or symbol, required), company, exchange, sector, industry, active. Send the CSV as the request body or as the \"file\" field of a multipart form.",
+                "consumes": [
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Import securities from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file (multipart)",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of imported securities",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/syncs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the most recent synchronization runs with the external API, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List sync runs",
+                "parameters": [
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum number of runs",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SyncRun"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/admin/syncs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a synchronization run by ID, including its counters and error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a sync run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sync run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SyncRun"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
         "/http/v1/health": {
             "get": {
                 "description": "Check if service is healthy",
@@ -89,16 +913,225 @@ const docTemplate = `{
                 "tags": [
                     "health"
                 ],
-                "summary": "Health check endpoint",
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "Service status and version",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "503": {
+                        "description": "Service unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/recommendations": {
+            "get": {
+                "description": "Get paginated list of stock recommendations, filterable by ticker, canonical rating, sector and data provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Get stock recommendations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ticker to filter by",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Canonical rating (strong_sell, sell, hold, buy, strong_buy, unknown) or a known alias such as outperform",
+                        "name": "rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sector from the securities master data (case-insensitive)",
+                        "name": "sector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data provider name (e.g. default)",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns recommendations and pagination info",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/recommendations/best": {
+            "get": {
+                "description": "Get top stock recommendations based on scoring model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Get best stock recommendations",
+                "parameters": [
+                    {
+                        "maximum": 20,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of recommendations to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns best recommendations and generation timestamp",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/http/v1/recommendations/export": {
+            "get": {
+                "description": "Stream every recommendation matching the filters as CSV, NDJSON or Parquet, newest first. The file is written while it is read from the database; if the export fails midway the response is truncated and the X-Export-Error trailer carries the error. X-Export-Rows is sent as a trailer with the number of rows written.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Export recommendations",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stock ticker to filter by",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Brokerage ID or name as sent by the provider (case-insensitive)",
+                        "name": "brokerage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Canonical rating or a known alias such as outperform",
+                        "name": "rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sector from the securities master data (case-insensitive)",
+                        "name": "sector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data provider name (e.g. default)",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the date range, inclusive (2006-01-02 or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the date range (a date includes the whole day; RFC 3339 is exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Service status and version",
+                        "description": "Exported recommendations",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "type": "file"
                         }
                     },
-                    "503": {
-                        "description": "Service unavailable",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/errors.AppError"
                         }
@@ -106,9 +1139,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/http/v1/recommendations": {
+        "/http/v1/recommendations/tickers": {
             "get": {
-                "description": "Get paginated list of stock recommendations, filterable by ticker",
+                "description": "Get active securities that have recommendations, with company, exchange, sector and industry",
                 "consumes": [
                     "application/json"
                 ],
@@ -118,48 +1151,27 @@ const docTemplate = `{
                 "tags": [
                     "recommendations"
                 ],
-                "summary": "Get stock recommendations",
+                "summary": "Get available stock tickers",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Stock ticker to filter by",
-                        "name": "ticker",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 100,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 50,
-                        "description": "Items per page",
-                        "name": "limit",
+                        "description": "Sector to filter by (case-insensitive)",
+                        "name": "sector",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns recommendations and pagination info",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "List of securities",
                         "schema": {
-                            "$ref": "#/definitions/errors.AppError"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Security"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/errors.AppError"
                         }
@@ -167,9 +1179,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/http/v1/recommendations/best": {
+        "/http/v1/recommendations/{id}": {
             "get": {
-                "description": "Get top stock recommendations based on scoring model",
+                "description": "Get a single recommendation by its stable ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -179,27 +1191,31 @@ const docTemplate = `{
                 "tags": [
                     "recommendations"
                 ],
-                "summary": "Get best stock recommendations",
+                "summary": "Get a stock recommendation",
                 "parameters": [
                     {
-                        "maximum": 20,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 5,
-                        "description": "Number of recommendations to return",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Recommendation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns best recommendations and generation timestamp",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/domain.StockRecommendation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/errors.AppError"
                         }
@@ -207,9 +1223,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/http/v1/recommendations/tickers": {
+        "/http/v1/recommendations/{id}/revisions": {
             "get": {
-                "description": "Get list of all available stock tickers with recommendations",
+                "description": "List the previous versions of a recommendation, newest first. Each revision records when it was replaced, by which sync run, the fields that changed in the version that replaced it and whether the change came from the provider (correction) or only from normalization.",
                 "consumes": [
                     "application/json"
                 ],
@@ -219,19 +1235,34 @@ const docTemplate = `{
                 "tags": [
                     "recommendations"
                 ],
-                "summary": "Get available stock tickers",
+                "summary": "Get the revision history of a recommendation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recommendation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "List of tickers",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/domain.RecommendationRevision"
                             }
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/errors.AppError"
                         }
@@ -244,6 +1275,482 @@ const docTemplate = `{
         "big.Int": {
             "type": "object"
         },
+        "domain.ActionType": {
+            "type": "string",
+            "enum": [
+                "unknown",
+                "initiate",
+                "upgrade",
+                "downgrade",
+                "target_raised",
+                "target_lowered",
+                "reiterate",
+                "maintain",
+                "drop_coverage"
+            ],
+            "x-enum-varnames": [
+                "ActionUnknown",
+                "ActionInitiate",
+                "ActionUpgrade",
+                "ActionDowngrade",
+                "ActionTargetRaised",
+                "ActionTargetLowered",
+                "ActionReiterate",
+                "ActionMaintain",
+                "ActionDropCoverage"
+            ]
+        },
+        "domain.Brokerage": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Textos del proveedor que se resuelven a esta firma",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "morgan stanley",
+                        "morgan stanley \u0026 co"
+                    ]
+                },
+                "created_at": {
+                    "description": "Fecha de creación",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Identificador canónico (slug estable)",
+                    "type": "string",
+                    "example": "morgan_stanley"
+                },
+                "name": {
+                    "description": "Nombre para mostrar",
+                    "type": "string",
+                    "example": "Morgan Stanley"
+                },
+                "reputation_weight": {
+                    "description": "Peso de reputación usado por el modelo de recomendación",
+                    "type": "number",
+                    "example": 1.2
+                },
+                "updated_at": {
+                    "description": "Fecha de última modificación",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.QuarantineStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "reingested",
+                "discarded"
+            ],
+            "x-enum-comments": {
+                "QuarantineDiscarded": "Descartado por un administrador",
+                "QuarantinePending": "Pendiente de revisión",
+                "QuarantineReingested": "Corregido y guardado como recomendación"
+            },
+            "x-enum-varnames": [
+                "QuarantinePending",
+                "QuarantineReingested",
+                "QuarantineDiscarded"
+            ]
+        },
+        "domain.QuarantinedRecord": {
+            "type": "object",
+            "properties": {
+                "fingerprint": {
+                    "description": "Huella del payload original (SHA-256); el mismo registro recibido varias veces ocupa una sola fila",
+                    "type": "string",
+                    "example": "3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b"
+                },
+                "first_seen": {
+                    "description": "Primera vez que se recibió",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Identificador del registro en cuarentena",
+                    "type": "string",
+                    "example": "0b7e4f2c-1d5a-4c3e-9f61-7a2d8c9e4b10"
+                },
+                "last_seen": {
+                    "description": "Última vez que se recibió",
+                    "type": "string"
+                },
+                "occurrences": {
+                    "description": "Veces que se recibió el registro",
+                    "type": "integer",
+                    "example": 3
+                },
+                "payload": {
+                    "description": "Payload del proveedor (el original, o el corregido por un administrador)",
+                    "type": "object"
+                },
+                "reason": {
+                    "description": "Motivo del rechazo (vacío si el payload corregido ya es válido)",
+                    "type": "string",
+                    "example": "recomendación inválida: falta el ticker"
+                },
+                "source": {
+                    "description": "Proveedor que envió el registro",
+                    "type": "string",
+                    "example": "default"
+                },
+                "status": {
+                    "description": "Estado del registro",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.QuarantineStatus"
+                        }
+                    ],
+                    "example": "pending"
+                },
+                "sync_run_id": {
+                    "description": "Última ejecución de sincronización que recibió el registro",
+                    "type": "string",
+                    "example": "5f0c1e9a-6d2b-4c51-9a55-1c7f3e0b2a44"
+                },
+                "updated_at": {
+                    "description": "Fecha de la última corrección o cambio de estado",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Rating": {
+            "type": "string",
+            "enum": [
+                "unknown",
+                "strong_sell",
+                "sell",
+                "hold",
+                "buy",
+                "strong_buy"
+            ],
+            "x-enum-varnames": [
+                "RatingUnknown",
+                "RatingStrongSell",
+                "RatingSell",
+                "RatingHold",
+                "RatingBuy",
+                "RatingStrongBuy"
+            ]
+        },
+        "domain.RecommendationRevision": {
+            "type": "object",
+            "properties": {
+                "changed_fields": {
+                    "description": "Campos que difieren en la versión siguiente",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "target_to",
+                        "rating_to"
+                    ]
+                },
+                "correction": {
+                    "description": "Indica si el reemplazo cambió datos del proveedor; false si solo cambió la normalización",
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "description": "Identificador de la revisión",
+                    "type": "string",
+                    "example": "0b7a4c1e-2f5d-4e8a-9c3b-6d1f0e2a7b94"
+                },
+                "recommendation": {
+                    "description": "Datos de la recomendación en esta versión",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.StockRecommendation"
+                        }
+                    ]
+                },
+                "revised_at": {
+                    "description": "Momento en que esta versión fue reemplazada",
+                    "type": "string"
+                },
+                "sync_run_id": {
+                    "description": "Ejecución de sincronización que reemplazó esta versión (vacío si fue una importación o un reingreso)",
+                    "type": "string",
+                    "example": "5f0c1e9a-6d2b-4c51-9a55-1c7f3e0b2a44"
+                }
+            }
+        },
+        "domain.Security": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Indica si el valor sigue cotizando",
+                    "type": "boolean",
+                    "example": true
+                },
+                "company": {
+                    "description": "Nombre de la empresa",
+                    "type": "string",
+                    "example": "Apple Inc."
+                },
+                "exchange": {
+                    "description": "Bolsa donde cotiza",
+                    "type": "string",
+                    "example": "NASDAQ"
+                },
+                "industry": {
+                    "description": "Industria dentro del sector",
+                    "type": "string",
+                    "example": "Consumer Electronics"
+                },
+                "sector": {
+                    "description": "Sector económico",
+                    "type": "string",
+                    "example": "Technology"
+                },
+                "ticker": {
+                    "description": "Símbolo del ticker",
+                    "type": "string",
+                    "example": "AAPL"
+                },
+                "updated_at": {
+                    "description": "Fecha de última modificación",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.StockRecommendation": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Acción tomada (por ejemplo: aumento, reducción)",
+                    "type": "string",
+                    "example": "aumentado"
+                },
+                "action_type": {
+                    "description": "Tipo de acción canónico derivado del texto y de los cambios de calificación y precio objetivo",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ActionType"
+                        }
+                    ],
+                    "example": "target_raised"
+                },
+                "brokerage": {
+                    "description": "Nombre de la firma de corretaje que emitió la recomendación",
+                    "type": "string",
+                    "example": "Goldman Sachs"
+                },
+                "brokerage_id": {
+                    "description": "ID canónico de la firma resuelto a partir de sus alias (vacío si no se reconoció)",
+                    "type": "string",
+                    "example": "goldman_sachs"
+                },
+                "company": {
+                    "description": "Nombre de la empresa",
+                    "type": "string",
+                    "example": "Apple Inc."
+                },
+                "currency": {
+                    "description": "Divisa de los precios objetivo (código ISO 4217)",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "id": {
+                    "description": "Identificador estable derivado de la identidad del proveedor (proveedor, ticker, firma, momento y acción)",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "normalized_rating_from": {
+                    "description": "Calificación anterior normalizada a la taxonomía canónica",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Rating"
+                        }
+                    ],
+                    "example": "hold"
+                },
+                "normalized_rating_to": {
+                    "description": "Nueva calificación normalizada a la taxonomía canónica",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Rating"
+                        }
+                    ],
+                    "example": "buy"
+                },
+                "rating_from": {
+                    "description": "Calificación anterior",
+                    "type": "string",
+                    "example": "neutral"
+                },
+                "rating_to": {
+                    "description": "Nueva calificación",
+                    "type": "string",
+                    "example": "comprar"
+                },
+                "source": {
+                    "description": "Proveedor de datos que envió la recomendación",
+                    "type": "string",
+                    "example": "default"
+                },
+                "target_from": {
+                    "description": "Precio objetivo inferior",
+                    "type": "number",
+                    "example": 150
+                },
+                "target_to": {
+                    "description": "Precio objetivo superior",
+                    "type": "number",
+                    "example": 175
+                },
+                "ticker": {
+                    "description": "Símbolo del ticker de la acción",
+                    "type": "string",
+                    "example": "AAPL"
+                },
+                "time": {
+                    "description": "Momento de la recomendación",
+                    "type": "string",
+                    "example": "2023-01-15T00:00:00Z"
+                }
+            }
+        },
+        "domain.SyncRun": {
+            "type": "object",
+            "properties": {
+                "corrected": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "deleted": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "error": {
+                    "description": "Error que hizo fallar la ejecución",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "description": "Fin de la ejecución (vacío mientras está en curso)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Identificador de la ejecución",
+                    "type": "string",
+                    "example": "5f0c1e9a-6d2b-4c51-9a55-1c7f3e0b2a44"
+                },
+                "inserted": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "pages": {
+                    "description": "Páginas pedidas al proveedor",
+                    "type": "integer",
+                    "example": 10
+                },
+                "quarantined": {
+                    "description": "Registros rechazados por la validación y enviados a cuarentena",
+                    "type": "integer",
+                    "example": 2
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 872
+                },
+                "source": {
+                    "description": "Proveedor sincronizado",
+                    "type": "string",
+                    "example": "default"
+                },
+                "started_at": {
+                    "description": "Inicio de la ejecución",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Estado de la ejecución",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SyncStatus"
+                        }
+                    ],
+                    "example": "succeeded"
+                },
+                "type": {
+                    "description": "Tipo de sincronización",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SyncType"
+                        }
+                    ],
+                    "example": "incremental"
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "watermark": {
+                    "description": "Marca de agua usada: fecha de la recomendación más reciente antes de una sincronización incremental,\no inicio de las páginas archivadas usadas en un reprocesamiento",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SyncStatus": {
+            "type": "string",
+            "enum": [
+                "running",
+                "succeeded",
+                "failed",
+                "quota_exceeded"
+            ],
+            "x-enum-comments": {
+                "SyncStatusQuota": "Detenida al agotarse la cuota diaria; la completa conserva su checkpoint"
+            },
+            "x-enum-varnames": [
+                "SyncStatusRunning",
+                "SyncStatusSucceeded",
+                "SyncStatusFailed",
+                "SyncStatusQuota"
+            ]
+        },
+        "domain.SyncType": {
+            "type": "string",
+            "enum": [
+                "full",
+                "incremental",
+                "reprocess"
+            ],
+            "x-enum-comments": {
+                "SyncTypeReprocess": "Reconstrucción desde las páginas archivadas"
+            },
+            "x-enum-varnames": [
+                "SyncTypeFull",
+                "SyncTypeIncremental",
+                "SyncTypeReprocess"
+            ]
+        },
+        "domain.UnresolvedBrokerage": {
+            "type": "object",
+            "properties": {
+                "first_seen": {
+                    "description": "Primera vez que se recibió",
+                    "type": "string"
+                },
+                "last_seen": {
+                    "description": "Última vez que se recibió",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Texto tal como lo envió el proveedor",
+                    "type": "string",
+                    "example": "Morgan Stanly"
+                },
+                "occurrences": {
+                    "description": "Número de registros ingeridos con este nombre",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
         "errors.AppError": {
             "type": "object",
             "properties": {
@@ -333,6 +1840,37 @@ const docTemplate = `{
                 }
             }
         },
+        "http.AliasRequest": {
+            "type": "object",
+            "required": [
+                "alias"
+            ],
+            "properties": {
+                "alias": {
+                    "description": "Texto del proveedor que debe resolverse a la firma",
+                    "type": "string",
+                    "example": "Morgan Stanley \u0026 Co."
+                }
+            }
+        },
+        "http.BrokerageRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "Nombre para mostrar",
+                    "type": "string",
+                    "example": "Morgan Stanley"
+                },
+                "reputation_weight": {
+                    "description": "Peso de reputación usado por el modelo de recomendación",
+                    "type": "number",
+                    "example": 1.2
+                }
+            }
+        },
         "http.Header": {
             "type": "object",
             "additionalProperties": {
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"api-stock/internal/domain"
+	"encoding/json"
+	"fmt"
 	"github.com/joho/godotenv" // Permite cargar variables de entorno desde un archivo .env
 	"log"
 	"os"
@@ -10,17 +13,38 @@ import (
 
 // Config estructura las configuraciones que usará toda la aplicación.
 type Config struct {
-	Environment      string        // Entorno de ejecución (development, production, etc.)
-	DBURL            string        // URL de conexión a la base de datos CockroachDB
-	APIToken         string        // Token de autenticación para la API externa
-	APIBaseURL       string        // URL base de la API de acciones
-	HTTPPort         string        // Puerto en el que corre el servidor HTTP
-	HTTPReadTimeout  time.Duration // Tiempo máximo de espera para lectura de peticiones
-	HTTPWriteTimeout time.Duration // Tiempo máximo de espera para escritura de respuestas
-	WorkerInterval   time.Duration // Intervalo entre ejecuciones del worker
-	MaxPages         int           // Límite de páginas a consultar en la API
-	MaxRetries       int           // Número máximo de reintentos para peticiones fallidas
-	InitialDelay     time.Duration // Retardo inicial antes de comenzar a consultar la API
+	Environment          string        // Entorno de ejecución (development, production, etc.)
+	DBURL                string        // URL de conexión a la base de datos CockroachDB
+	APIToken             string        // Token de autenticación para la API externa
+	APIBaseURL           string        // URL base de la API de acciones
+	HTTPPort             string        // Puerto en el que corre el servidor HTTP
+	HTTPReadTimeout      time.Duration // Tiempo máximo de espera para lectura de peticiones
+	HTTPWriteTimeout     time.Duration // Tiempo máximo de espera para escritura de respuestas
+	ExportTimeout        time.Duration // Tiempo máximo de escritura de una exportación de recomendaciones
+	WorkerInterval       time.Duration // Intervalo entre ejecuciones del worker
+	WorkerPort           string        // Puerto del servidor HTTP del worker (salud, métricas y control de trabajos)
+	WorkerJobsFile       string        // Archivo JSON opcional con los trabajos programados del worker; vacío usa WORKER_INTERVAL
+	RetentionMaxAge      time.Duration // Antigüedad a partir de la que el trabajo de retención purga el historial
+	MaxPages             int           // Límite de páginas a consultar en la API
+	MaxRetries           int           // Número máximo de reintentos para peticiones fallidas a la API externa
+	InitialDelay         time.Duration // Espera antes del primer reintento; crece exponencialmente en los siguientes
+	RetryMaxDelay        time.Duration // Espera máxima entre reintentos calculada por backoff
+	RatingAliasFile      string        // Archivo JSON opcional con alias de calificaciones adicionales ({"alias": "buy"})
+	ProvidersFile        string        // Archivo JSON opcional con los proveedores de recomendaciones; vacío usa API_TOKEN/API_BASE_URL
+	APIRecordDir         string        // Directorio donde se graban los intercambios con los proveedores (vacío no graba)
+	APIReplayDir         string        // Directorio desde el que se reproducen los intercambios grabados en lugar de llamar a los proveedores
+	AdminToken           string        // Token para los endpoints de administración; vacío los deshabilita
+	SyncMinRowRatio      float64       // Proporción mínima de filas (nuevas/actuales) para aplicar una sincronización completa
+	APIRateLimit         float64       // Peticiones por segundo a cada proveedor que no configura las suyas
+	APIRateBurst         int           // Peticiones seguidas permitidas antes de aplicar el ritmo
+	APIDailyQuota        int           // Peticiones diarias máximas a cada proveedor que no configura la suya (0 sin límite)
+	SyncPrefetchPages    int           // Páginas que la sincronización pide por adelantado mientras guarda la actual (0 ninguna)
+	SyncCheckpointMaxAge time.Duration // Antigüedad máxima de un checkpoint de sincronización para reanudarlo
+	SyncWatermarkOverlap time.Duration // Ventana que la sincronización incremental relee por debajo de la marca de agua
+	SyncLookbackPages    int           // Páginas extra que la sincronización incremental lee por debajo de la marca de agua
+	SyncFullMaxAge       time.Duration // Antigüedad de la última sincronización completa a partir de la cual la incremental hace una completa
+	SyncLeaseTTL         time.Duration // Vigencia del lease de sincronización sin renovar (un solo proceso sincroniza a la vez)
+	SyncLeaseWait        time.Duration // Espera máxima por el lease de sincronización antes de omitir el trabajo
 }
 
 // Load carga las variables de entorno desde un archivo .env (si existe) y las encapsula en una instancia Config.
@@ -32,20 +56,191 @@ func Load() *Config {
 
 	// Retorna una instancia de Config con valores leídos de variables de entorno o valores por defecto
 	return &Config{
-		Environment:      getEnv("ENVIRONMENT", "development"),
-		DBURL:            getEnv("COCKROACHDB_URL", ""),
-		APIToken:         getEnv("API_TOKEN", ""),
-		APIBaseURL:       getEnv("API_BASE_URL", ""),
-		HTTPPort:         getEnv("PORT", "8080"),
-		HTTPReadTimeout:  getEnvAsDuration("HTTP_READ_TIMEOUT", 15*time.Second),
-		HTTPWriteTimeout: getEnvAsDuration("HTTP_WRITE_TIMEOUT", 30*time.Second),
-		WorkerInterval:   getEnvAsDuration("WORKER_INTERVAL", 1*time.Hour),
-		MaxPages:         getEnvAsInt("MAX_PAGES", 20),
-		MaxRetries:       getEnvAsInt("MAX_RETRIES", 3),
-		InitialDelay:     getEnvAsDuration("INITIAL_DELAY", 1*time.Second),
+		Environment:          getEnv("ENVIRONMENT", "development"),
+		DBURL:                getEnv("COCKROACHDB_URL", ""),
+		APIToken:             getEnv("API_TOKEN", ""),
+		APIBaseURL:           getEnv("API_BASE_URL", ""),
+		HTTPPort:             getEnv("PORT", "8080"),
+		HTTPReadTimeout:      getEnvAsDuration("HTTP_READ_TIMEOUT", 15*time.Second),
+		HTTPWriteTimeout:     getEnvAsDuration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+		ExportTimeout:        getEnvAsDuration("EXPORT_TIMEOUT", 30*time.Minute),
+		WorkerInterval:       getEnvAsDuration("WORKER_INTERVAL", 1*time.Hour),
+		WorkerPort:           getEnv("WORKER_PORT", "8081"),
+		WorkerJobsFile:       getEnv("WORKER_JOBS_FILE", ""),
+		RetentionMaxAge:      getEnvAsDuration("RETENTION_MAX_AGE", 90*24*time.Hour),
+		MaxPages:             getEnvAsInt("MAX_PAGES", 20),
+		MaxRetries:           getEnvAsInt("MAX_RETRIES", 3),
+		InitialDelay:         getEnvAsDuration("INITIAL_DELAY", 1*time.Second),
+		RetryMaxDelay:        getEnvAsDuration("RETRY_MAX_DELAY", 30*time.Second),
+		RatingAliasFile:      getEnv("RATING_ALIASES_FILE", ""),
+		ProvidersFile:        getEnv("PROVIDERS_FILE", ""),
+		APIRecordDir:         getEnv("API_RECORD_DIR", ""),
+		APIReplayDir:         getEnv("API_REPLAY_DIR", ""),
+		AdminToken:           getEnv("ADMIN_TOKEN", ""),
+		SyncMinRowRatio:      getEnvAsFloat("SYNC_MIN_ROW_RATIO", 0.5),
+		APIRateLimit:         getEnvAsFloat("API_RATE_LIMIT", rateFromDelay(getEnvAsDuration("SYNC_PAGE_DELAY", 2*time.Second))),
+		APIRateBurst:         getEnvAsInt("API_RATE_BURST", 1),
+		APIDailyQuota:        getEnvAsInt("API_DAILY_QUOTA", 0),
+		SyncPrefetchPages:    getEnvAsInt("SYNC_PREFETCH_PAGES", 2),
+		SyncCheckpointMaxAge: getEnvAsDuration("SYNC_CHECKPOINT_MAX_AGE", 24*time.Hour),
+		SyncWatermarkOverlap: getEnvAsDuration("SYNC_WATERMARK_OVERLAP", 24*time.Hour),
+		SyncLookbackPages:    getEnvAsInt("SYNC_LOOKBACK_PAGES", 2),
+		SyncFullMaxAge:       getEnvAsDuration("SYNC_FULL_MAX_AGE", 24*time.Hour),
+		SyncLeaseTTL:         getEnvAsDuration("SYNC_LEASE_TTL", 1*time.Minute),
+		SyncLeaseWait:        getEnvAsDuration("SYNC_LEASE_WAIT", 0),
 	}
 }
 
+// LoadRatingAliases lee el archivo JSON de alias de calificaciones indicado en RatingAliasFile.
+// El archivo es un objeto {"texto del proveedor": "código canónico"}; si no se configuró, devuelve un mapa vacío.
+func (c *Config) LoadRatingAliases() (map[string]string, error) {
+	aliases := make(map[string]string)
+	if c.RatingAliasFile == "" {
+		return aliases, nil
+	}
+	data, err := os.ReadFile(c.RatingAliasFile)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo alias de calificaciones: %v", err)
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("error decodificando alias de calificaciones: %v", err)
+	}
+	return aliases, nil
+}
+
+// ProviderConfig describe un proveedor de recomendaciones: dónde está, cómo autenticarse,
+// cómo pagina y cómo se llaman sus campos.
+type ProviderConfig struct {
+	Name       string            `json:"name"`        // Nombre del proveedor (minúsculas, dígitos y guiones bajos); se guarda como source
+	BaseURL    string            `json:"base_url"`    // URL del listado de recomendaciones
+	Token      string            `json:"token"`       // Token de autenticación
+	TokenEnv   string            `json:"token_env"`   // Variable de entorno con el token (preferible a escribirlo en el archivo)
+	AuthHeader string            `json:"auth_header"` // Cabecera del token (por defecto Authorization)
+	AuthScheme string            `json:"auth_scheme"` // Prefijo del token (por defecto Bearer; "none" envía el token solo)
+	Pagination PaginationConfig  `json:"pagination"`  // Estilo de paginación
+	ItemsField string            `json:"items_field"` // Campo de la respuesta con la lista de registros (por defecto items)
+	Fields     map[string]string `json:"fields"`      // Campo canónico -> ruta en el registro del proveedor ("a.b" para anidados)
+	TimeLayout string            `json:"time_layout"` // Formato Go del campo time si no es RFC 3339
+	RateLimit  RateLimitConfig   `json:"rate_limit"`  // Ritmo y cuota de peticiones (por defecto API_RATE_LIMIT, API_RATE_BURST y API_DAILY_QUOTA)
+}
+
+// RateLimitConfig limita las peticiones a un proveedor. Los campos en cero toman el valor por defecto.
+type RateLimitConfig struct {
+	RPS        float64 `json:"rps"`         // Peticiones por segundo sostenidas; negativo no limita el ritmo
+	Burst      int     `json:"burst"`       // Peticiones seguidas permitidas antes de aplicar el ritmo
+	DailyQuota int     `json:"daily_quota"` // Peticiones máximas por día UTC; negativo no limita
+}
+
+// PaginationConfig describe cómo pagina un proveedor.
+type PaginationConfig struct {
+	Style         string `json:"style"`          // "token" (por defecto): la respuesta trae el token de la siguiente página; "page": número de página
+	Param         string `json:"param"`          // Parámetro de query con el token o el número de página (next_page / page)
+	ResponseField string `json:"response_field"` // Campo de la respuesta con el token de la siguiente página (estilo token)
+	SizeParam     string `json:"size_param"`     // Parámetro de query con el tamaño de página (estilo page, opcional)
+	Size          int    `json:"size"`           // Tamaño de página; una página más corta es la última (estilo page)
+	FirstPage     int    `json:"first_page"`     // Número de la primera página (estilo page, por defecto 1)
+}
+
+// LoadProviders devuelve los proveedores configurados en ProvidersFile (un arreglo JSON de ProviderConfig).
+// Si no se configuró, devuelve el proveedor original con API_TOKEN y API_BASE_URL.
+func (c *Config) LoadProviders() ([]ProviderConfig, error) {
+	if c.ProvidersFile == "" {
+		return []ProviderConfig{{Name: domain.DefaultSource, BaseURL: c.APIBaseURL, Token: c.APIToken, RateLimit: c.rateLimit(RateLimitConfig{})}}, nil
+	}
+	data, err := os.ReadFile(c.ProvidersFile)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo proveedores: %v", err)
+	}
+	var providers []ProviderConfig
+	if err := json.Unmarshal(data, &providers); err != nil {
+		return nil, fmt.Errorf("error decodificando proveedores: %v", err)
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("el archivo de proveedores no define ninguno")
+	}
+	for i := range providers {
+		if providers[i].Token == "" && providers[i].TokenEnv != "" {
+			providers[i].Token = os.Getenv(providers[i].TokenEnv)
+		}
+		providers[i].RateLimit = c.rateLimit(providers[i].RateLimit)
+	}
+	return providers, nil
+}
+
+// rateLimit completa con los valores por defecto los campos en cero de la configuración de un proveedor.
+func (c *Config) rateLimit(limits RateLimitConfig) RateLimitConfig {
+	if limits.RPS == 0 {
+		limits.RPS = c.APIRateLimit
+	}
+	if limits.Burst == 0 {
+		limits.Burst = c.APIRateBurst
+	}
+	if limits.DailyQuota == 0 {
+		limits.DailyQuota = c.APIDailyQuota
+	}
+	return limits
+}
+
+// rateFromDelay convierte la antigua pausa entre páginas (SYNC_PAGE_DELAY) en peticiones por segundo.
+func rateFromDelay(delay time.Duration) float64 {
+	if delay <= 0 {
+		return -1
+	}
+	return 1 / delay.Seconds()
+}
+
+// JobConfig describe un trabajo programado del worker.
+type JobConfig struct {
+	Name       string   `json:"name"`         // Nombre único del trabajo
+	Kind       string   `json:"kind"`         // Tipo de trabajo: full, incremental, reprocess, retention o score_snapshot
+	Schedule   string   `json:"schedule"`     // Expresión cron ("*/15 9-16 * * MON-FRI", "@daily", "@every 15m", prefijo CRON_TZ=); vacía: solo manual
+	Timeout    Duration `json:"timeout"`      // Tiempo máximo de una ejecución ("30m"); vacío no la limita
+	Jitter     Duration `json:"jitter"`       // Retraso aleatorio máximo sobre la hora programada ("1m")
+	Overlap    string   `json:"overlap"`      // Si la ejecución anterior sigue en curso: skip (por defecto), queue o allow
+	RunOnStart bool     `json:"run_on_start"` // Ejecuta el trabajo al arrancar el worker
+}
+
+// Duration es un time.Duration que en JSON se escribe como texto ("15m", "1h30m").
+type Duration time.Duration
+
+// UnmarshalJSON lee la duración en el formato de time.ParseDuration.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duración inválida %s: se espera un texto como \"15m\"", data)
+	}
+	if text == "" {
+		*d = 0
+		return nil
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return fmt.Errorf("duración inválida %q: %v", text, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadJobs devuelve los trabajos configurados en WorkerJobsFile (un arreglo JSON de JobConfig),
+// o nil si no se configuró.
+func (c *Config) LoadJobs() ([]JobConfig, error) {
+	if c.WorkerJobsFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(c.WorkerJobsFile)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo trabajos del worker: %v", err)
+	}
+	var jobs []JobConfig
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("error decodificando trabajos del worker: %v", err)
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("el archivo de trabajos no define ninguno")
+	}
+	return jobs, nil
+}
+
 // getEnv obtiene una variable de entorno como string, o retorna un valor por defecto si no existe.
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -73,3 +268,13 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsFloat obtiene una variable de entorno como float64, o retorna un valor por defecto si no es válida.
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
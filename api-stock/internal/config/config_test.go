@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDurationUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: `"1m"`, want: time.Minute},
+		{raw: `"1h30m"`, want: 90 * time.Minute},
+		{raw: `"250ms"`, want: 250 * time.Millisecond},
+		{raw: `""`, want: 0},
+		{raw: `"-1m"`, want: -time.Minute}, // NewScheduler rechaza los negativos
+		{raw: `"15"`, wantErr: true},
+		{raw: `"quince minutos"`, wantErr: true},
+		{raw: `60`, wantErr: true},
+		{raw: `null`, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			var got Duration
+			err := json.Unmarshal([]byte(tt.raw), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %v, want error", tt.raw, time.Duration(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error: %v", tt.raw, err)
+			}
+			if time.Duration(got) != tt.want {
+				t.Errorf("Unmarshal(%s) = %v, want %v", tt.raw, time.Duration(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadJobsSchedule(t *testing.T) {
+	path := t.TempDir() + "/jobs.json"
+	data := []byte(`[{"name": "mercado", "kind": "incremental", "schedule": "CRON_TZ=America/New_York */15 9-16 * * MON-FRI", "jitter": "1m", "timeout": "30m"}]`)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := (&Config{WorkerJobsFile: path}).LoadJobs()
+	if err != nil {
+		t.Fatalf("LoadJobs error: %v", err)
+	}
+	want := JobConfig{Name: "mercado", Kind: "incremental", Schedule: "CRON_TZ=America/New_York */15 9-16 * * MON-FRI",
+		Jitter: Duration(time.Minute), Timeout: Duration(30 * time.Minute)}
+	if len(jobs) != 1 || jobs[0] != want {
+		t.Errorf("LoadJobs() = %+v, want [%+v]", jobs, want)
+	}
+}
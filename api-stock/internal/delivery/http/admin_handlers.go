@@ -0,0 +1,459 @@
+package http
+
+import (
+	"api-stock/internal/domain"
+	"api-stock/pkg/errors"
+	"crypto/subtle"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler agrupa los endpoints de administración de datos maestros.
+type AdminHandler struct {
+	brokerageService  domain.BrokerageService
+	securityService   domain.SecurityService
+	syncRunService    domain.SyncRunService
+	quarantineService domain.QuarantineService
+}
+
+func NewAdminHandler(brokerageService domain.BrokerageService, securityService domain.SecurityService, syncRunService domain.SyncRunService, quarantineService domain.QuarantineService) *AdminHandler {
+	return &AdminHandler{
+		brokerageService:  brokerageService,
+		securityService:   securityService,
+		syncRunService:    syncRunService,
+		quarantineService: quarantineService,
+	}
+}
+
+// BrokerageRequest es el cuerpo para crear o actualizar una firma de corretaje.
+type BrokerageRequest struct {
+	// Nombre para mostrar
+	Name string `json:"name" binding:"required" example:"Morgan Stanley"`
+	// Peso de reputación usado por el modelo de recomendación
+	ReputationWeight float64 `json:"reputation_weight" example:"1.2"`
+}
+
+// AliasRequest es el cuerpo para agregar un alias a una firma de corretaje.
+type AliasRequest struct {
+	// Texto del proveedor que debe resolverse a la firma
+	Alias string `json:"alias" binding:"required" example:"Morgan Stanley & Co."`
+}
+
+// AdminAuth exige el token de administración en la cabecera Authorization ("Bearer <token>").
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.Error(errors.NewAppError(http.StatusUnauthorized, "Invalid admin token", nil))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ListBrokerages godoc
+// @Summary List brokerages
+// @Description List the brokerage master data with aliases and reputation weights
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} domain.Brokerage
+// @Failure 401 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/brokerages [get]
+func (h *AdminHandler) ListBrokerages(c *gin.Context) {
+	brokerages, err := h.brokerageService.ListBrokerages(c.Request.Context())
+	if err != nil {
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to list brokerages", err))
+		return
+	}
+	if brokerages == nil {
+		brokerages = []domain.Brokerage{}
+	}
+	c.JSON(http.StatusOK, brokerages)
+}
+
+// GetBrokerage godoc
+// @Summary Get a brokerage
+// @Description Get a brokerage by canonical ID
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Brokerage ID"
+// @Success 200 {object} domain.Brokerage
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/brokerages/{id} [get]
+func (h *AdminHandler) GetBrokerage(c *gin.Context) {
+	brokerage, err := h.brokerageService.GetBrokerage(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(brokerageError(err, "Failed to get brokerage"))
+		return
+	}
+	c.JSON(http.StatusOK, brokerage)
+}
+
+// SaveBrokerage godoc
+// @Summary Create or update a brokerage
+// @Description Create a brokerage with the given canonical ID, or update its name and reputation weight
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Brokerage ID (lowercase letters, digits and underscores)"
+// @Param brokerage body BrokerageRequest true "Brokerage data"
+// @Success 200 {object} domain.Brokerage
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/brokerages/{id} [put]
+func (h *AdminHandler) SaveBrokerage(c *gin.Context) {
+	var req BrokerageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewAppError(http.StatusBadRequest, "Invalid brokerage payload", err))
+		return
+	}
+
+	brokerage, err := h.brokerageService.SaveBrokerage(c.Request.Context(), domain.Brokerage{
+		ID:               c.Param("id"),
+		Name:             req.Name,
+		ReputationWeight: req.ReputationWeight,
+	})
+	if err != nil {
+		c.Error(brokerageError(err, "Failed to save brokerage"))
+		return
+	}
+	c.JSON(http.StatusOK, brokerage)
+}
+
+// AddBrokerageAlias godoc
+// @Summary Add a brokerage alias
+// @Description Map a provider brokerage name to the brokerage; existing unresolved records with that name are linked
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Brokerage ID"
+// @Param alias body AliasRequest true "Alias to add"
+// @Success 200 {object} domain.Brokerage
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 409 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/brokerages/{id}/aliases [post]
+func (h *AdminHandler) AddBrokerageAlias(c *gin.Context) {
+	var req AliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewAppError(http.StatusBadRequest, "Invalid alias payload", err))
+		return
+	}
+
+	brokerage, err := h.brokerageService.AddAlias(c.Request.Context(), c.Param("id"), req.Alias)
+	if err != nil {
+		c.Error(brokerageError(err, "Failed to add alias"))
+		return
+	}
+	c.JSON(http.StatusOK, brokerage)
+}
+
+// RemoveBrokerageAlias godoc
+// @Summary Remove a brokerage alias
+// @Description Remove an alias from the brokerage
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Brokerage ID"
+// @Param alias path string true "Alias to remove (URL-encoded)"
+// @Success 200 {object} domain.Brokerage
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/brokerages/{id}/aliases/{alias} [delete]
+func (h *AdminHandler) RemoveBrokerageAlias(c *gin.Context) {
+	brokerage, err := h.brokerageService.RemoveAlias(c.Request.Context(), c.Param("id"), c.Param("alias"))
+	if err != nil {
+		c.Error(brokerageError(err, "Failed to remove alias"))
+		return
+	}
+	c.JSON(http.StatusOK, brokerage)
+}
+
+// ListUnresolvedBrokerages godoc
+// @Summary List unresolved brokerage names
+// @Description List provider brokerage names that did not match any alias during ingestion, most frequent first
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Maximum number of names" default(100) minimum(1) maximum(500)
+// @Success 200 {array} domain.UnresolvedBrokerage
+// @Failure 401 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/brokerages/unresolved [get]
+func (h *AdminHandler) ListUnresolvedBrokerages(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	unresolved, err := h.brokerageService.ListUnresolved(c.Request.Context(), limit)
+	if err != nil {
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to list unresolved brokerages", err))
+		return
+	}
+	c.JSON(http.StatusOK, unresolved)
+}
+
+// ImportSecurities godoc
+// @Summary Import securities from CSV
+// @Description Create or update securities master data from a CSV with header. Columns: ticker (or symbol, required), company, exchange, sector, industry, active. Send the CSV as the request body or as the "file" field of a multipart form.
+// @Tags admin
+// @Accept text/csv
+// @Accept mpfd
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file false "CSV file (multipart)"
+// @Success 200 {object} map[string]int "Number of imported securities"
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/securities/import [post]
+func (h *AdminHandler) ImportSecurities(c *gin.Context) {
+	body := c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.Error(errors.NewAppError(http.StatusBadRequest, "Missing CSV file", err))
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.Error(errors.NewAppError(http.StatusBadRequest, "Unreadable CSV file", err))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	imported, err := h.securityService.ImportCSV(c.Request.Context(), body)
+	if err != nil {
+		if stderrors.Is(err, domain.ErrInvalidSecurity) {
+			c.Error(errors.NewAppError(http.StatusBadRequest, err.Error(), err))
+			return
+		}
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to import securities", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"imported": imported})
+}
+
+// ListSyncRuns godoc
+// @Summary List sync runs
+// @Description List the most recent synchronization runs with the external API, newest first
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Maximum number of runs" default(50) minimum(1) maximum(500)
+// @Success 200 {array} domain.SyncRun
+// @Failure 401 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/syncs [get]
+func (h *AdminHandler) ListSyncRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	runs, err := h.syncRunService.ListSyncRuns(c.Request.Context(), limit)
+	if err != nil {
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to list sync runs", err))
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetSyncRun godoc
+// @Summary Get a sync run
+// @Description Get a synchronization run by ID, including its counters and error
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Sync run ID"
+// @Success 200 {object} domain.SyncRun
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/syncs/{id} [get]
+func (h *AdminHandler) GetSyncRun(c *gin.Context) {
+	run, err := h.syncRunService.GetSyncRun(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if stderrors.Is(err, domain.ErrSyncRunNotFound) {
+			c.Error(errors.NewAppError(http.StatusNotFound, "Sync run not found", err))
+			return
+		}
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to get sync run", err))
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// ListQuarantine godoc
+// @Summary List quarantined records
+// @Description List provider records rejected by ingestion validation, most recently seen first
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "Record status; empty lists every status" Enums(pending, reingested, discarded) default(pending)
+// @Param source query string false "Data provider name"
+// @Param limit query int false "Maximum number of records" default(100) minimum(1) maximum(500)
+// @Success 200 {array} domain.QuarantinedRecord
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/quarantine [get]
+func (h *AdminHandler) ListQuarantine(c *gin.Context) {
+	filter := domain.QuarantineFilter{
+		Status: domain.QuarantineStatus(c.DefaultQuery("status", string(domain.QuarantinePending))),
+		Source: c.Query("source"),
+	}
+	if filter.Status != "" && !filter.Status.Valid() {
+		c.Error(errors.NewAppError(http.StatusBadRequest, "Unknown quarantine status", nil))
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	records, err := h.quarantineService.ListQuarantine(c.Request.Context(), filter, limit)
+	if err != nil {
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to list quarantined records", err))
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// GetQuarantined godoc
+// @Summary Get a quarantined record
+// @Description Get a quarantined record by ID, with its rejection reason and provider payload
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Quarantined record ID"
+// @Success 200 {object} domain.QuarantinedRecord
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/quarantine/{id} [get]
+func (h *AdminHandler) GetQuarantined(c *gin.Context) {
+	record, err := h.quarantineService.GetQuarantined(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(quarantineError(err, "Failed to get quarantined record"))
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// FixQuarantined godoc
+// @Summary Fix a quarantined record
+// @Description Replace the provider payload of a pending record. The reason is recomputed with the validation rules and is empty once the payload can be reingested.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Quarantined record ID"
+// @Param payload body object true "Corrected provider record, with the provider's field names"
+// @Success 200 {object} domain.QuarantinedRecord
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 409 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/quarantine/{id} [put]
+func (h *AdminHandler) FixQuarantined(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil || !json.Valid(payload) {
+		c.Error(errors.NewAppError(http.StatusBadRequest, "Invalid payload", err))
+		return
+	}
+
+	record, err := h.quarantineService.FixQuarantined(c.Request.Context(), c.Param("id"), payload)
+	if err != nil {
+		c.Error(quarantineError(err, "Failed to fix quarantined record"))
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// ReingestQuarantined godoc
+// @Summary Reingest a quarantined record
+// @Description Decode the payload with the provider field mapping, validate it and store it as a recommendation. Later syncs keep using the corrected payload when the provider sends the original record again. The reingest takes the sync lease and fails with 409 while a sync is running.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Quarantined record ID"
+// @Success 200 {object} domain.StockRecommendation
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 409 {object} errors.AppError
+// @Failure 422 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/quarantine/{id}/reingest [post]
+func (h *AdminHandler) ReingestQuarantined(c *gin.Context) {
+	recommendation, err := h.quarantineService.ReingestQuarantined(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(quarantineError(err, "Failed to reingest quarantined record"))
+		return
+	}
+	c.JSON(http.StatusOK, recommendation)
+}
+
+// DiscardQuarantined godoc
+// @Summary Discard a quarantined record
+// @Description Mark a pending record as discarded; it stays discarded if the provider sends it again
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Quarantined record ID"
+// @Success 200 {object} domain.QuarantinedRecord
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 409 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/admin/quarantine/{id}/discard [post]
+func (h *AdminHandler) DiscardQuarantined(c *gin.Context) {
+	record, err := h.quarantineService.DiscardQuarantined(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(quarantineError(err, "Failed to discard quarantined record"))
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// quarantineError traduce los errores de la cuarentena al código HTTP correspondiente.
+func quarantineError(err error, message string) *errors.AppError {
+	switch {
+	case stderrors.Is(err, domain.ErrQuarantineNotFound):
+		return errors.NewAppError(http.StatusNotFound, "Quarantined record not found", err)
+	case stderrors.Is(err, domain.ErrQuarantineResolved):
+		return errors.NewAppError(http.StatusConflict, err.Error(), err)
+	case stderrors.Is(err, domain.ErrInvalidRecommendation):
+		return errors.NewAppError(http.StatusUnprocessableEntity, err.Error(), err)
+	case stderrors.Is(err, domain.ErrLeaseHeld), stderrors.Is(err, domain.ErrLeaseLost):
+		return errors.NewAppError(http.StatusConflict, "A sync is running; retry the reingest when it finishes", err)
+	default:
+		return errors.NewAppError(http.StatusInternalServerError, message, err)
+	}
+}
+
+// brokerageError traduce los errores del dominio de firmas al código HTTP correspondiente.
+func brokerageError(err error, message string) *errors.AppError {
+	switch {
+	case stderrors.Is(err, domain.ErrBrokerageNotFound):
+		return errors.NewAppError(http.StatusNotFound, "Brokerage not found", err)
+	case stderrors.Is(err, domain.ErrInvalidBrokerage):
+		return errors.NewAppError(http.StatusBadRequest, err.Error(), err)
+	case stderrors.Is(err, domain.ErrAliasConflict):
+		return errors.NewAppError(http.StatusConflict, err.Error(), err)
+	default:
+		return errors.NewAppError(http.StatusInternalServerError, message, err)
+	}
+}
@@ -3,6 +3,8 @@ package http
 import (
 	"api-stock/internal/domain"
 	"api-stock/pkg/errors"
+	stderrors "errors"
+	"fmt"
 	"math"
 	"net/http"
 	"strconv"
@@ -14,25 +16,34 @@ import (
 type StockHandler struct {
 	stockService          domain.StockService
 	recommendationService domain.RecommendationService
+	exportService         domain.ExportService
+	exportTimeout         time.Duration // Tiempo máximo de escritura de una exportación (reemplaza al del servidor)
 }
 
 func NewStockHandler(
 	stockService domain.StockService,
 	recommendationService domain.RecommendationService,
+	exportService domain.ExportService,
+	exportTimeout time.Duration,
 ) *StockHandler {
 	return &StockHandler{
 		stockService:          stockService,
 		recommendationService: recommendationService,
+		exportService:         exportService,
+		exportTimeout:         exportTimeout,
 	}
 }
 
 // GetRecommendations godoc
 // @Summary Get stock recommendations
-// @Description Get paginated list of stock recommendations, filterable by ticker
+// @Description Get paginated list of stock recommendations, filterable by ticker, canonical rating, sector and data provider
 // @Tags recommendations
 // @Accept json
 // @Produce json
 // @Param ticker query string false "Stock ticker to filter by"
+// @Param rating query string false "Canonical rating (strong_sell, sell, hold, buy, strong_buy, unknown) or a known alias such as outperform"
+// @Param sector query string false "Sector from the securities master data (case-insensitive)"
+// @Param source query string false "Data provider name (e.g. default)"
 // @Param page query int false "Page number" default(1) minimum(1)
 // @Param limit query int false "Items per page" default(50) minimum(1) maximum(100)
 // @Success 200 {object} map[string]interface{} "Returns recommendations and pagination info"
@@ -41,11 +52,18 @@ func NewStockHandler(
 // @Router /http/v1/recommendations [get]
 func (h *StockHandler) GetRecommendations(c *gin.Context) {
 	ticker := c.Query("ticker")
+	rating := c.Query("rating")
+	sector := c.Query("sector")
+	source := c.Query("source")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 
-	recommendations, total, err := h.stockService.GetRecommendations(c.Request.Context(), ticker, page, limit)
+	recommendations, total, err := h.stockService.GetRecommendations(c.Request.Context(), ticker, rating, sector, source, page, limit)
 	if err != nil {
+		if stderrors.Is(err, domain.ErrUnknownRating) {
+			c.Error(errors.NewAppError(http.StatusBadRequest, "Unknown rating filter", err))
+			return
+		}
 		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to get recommendations", err))
 		return
 	}
@@ -61,17 +79,142 @@ func (h *StockHandler) GetRecommendations(c *gin.Context) {
 	})
 }
 
+// GetRecommendation godoc
+// @Summary Get a stock recommendation
+// @Description Get a single recommendation by its stable ID
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param id path string true "Recommendation ID"
+// @Success 200 {object} domain.StockRecommendation
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/recommendations/{id} [get]
+func (h *StockHandler) GetRecommendation(c *gin.Context) {
+	recommendation, err := h.stockService.GetRecommendation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if stderrors.Is(err, domain.ErrRecommendationNotFound) {
+			c.Error(errors.NewAppError(http.StatusNotFound, "Recommendation not found", err))
+			return
+		}
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to get recommendation", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendation)
+}
+
+// GetRecommendationRevisions godoc
+// @Summary Get the revision history of a recommendation
+// @Description List the previous versions of a recommendation, newest first. Each revision records when it was replaced, by which sync run, the fields that changed in the version that replaced it and whether the change came from the provider (correction) or only from normalization.
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param id path string true "Recommendation ID"
+// @Success 200 {array} domain.RecommendationRevision
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/recommendations/{id}/revisions [get]
+func (h *StockHandler) GetRecommendationRevisions(c *gin.Context) {
+	revisions, err := h.stockService.GetRecommendationRevisions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if stderrors.Is(err, domain.ErrRecommendationNotFound) {
+			c.Error(errors.NewAppError(http.StatusNotFound, "Recommendation not found", err))
+			return
+		}
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to get recommendation revisions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// ExportRecommendations godoc
+// @Summary Export recommendations
+// @Description Stream every recommendation matching the filters as CSV, NDJSON or Parquet, newest first. The file is written while it is read from the database; if the export fails midway the response is truncated and the X-Export-Error trailer carries the error. X-Export-Rows is sent as a trailer with the number of rows written.
+// @Tags recommendations
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.apache.parquet
+// @Param format query string false "File format" Enums(csv, ndjson, parquet) default(csv)
+// @Param ticker query string false "Stock ticker to filter by"
+// @Param brokerage query string false "Brokerage ID or name as sent by the provider (case-insensitive)"
+// @Param rating query string false "Canonical rating or a known alias such as outperform"
+// @Param sector query string false "Sector from the securities master data (case-insensitive)"
+// @Param source query string false "Data provider name (e.g. default)"
+// @Param from query string false "Start of the date range, inclusive (2006-01-02 or RFC 3339)"
+// @Param to query string false "End of the date range (a date includes the whole day; RFC 3339 is exclusive)"
+// @Success 200 {file} file "Exported recommendations"
+// @Failure 400 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /http/v1/recommendations/export [get]
+func (h *StockHandler) ExportRecommendations(c *gin.Context) {
+	format, err := domain.ParseExportFormat(c.Query("format"))
+	if err != nil {
+		c.Error(errors.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+	filter := domain.RecommendationFilter{
+		Ticker:    c.Query("ticker"),
+		Brokerage: c.Query("brokerage"),
+		Rating:    domain.Rating(c.Query("rating")),
+		Sector:    c.Query("sector"),
+		Source:    c.Query("source"),
+	}
+	if filter.From, err = domain.ParseTimeBound(c.Query("from"), false); err == nil {
+		filter.To, err = domain.ParseTimeBound(c.Query("to"), true)
+	}
+	if err != nil {
+		c.Error(errors.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	// Las exportaciones grandes superan el WriteTimeout del servidor
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(h.exportTimeout)); err != nil {
+		c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to start export", err))
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", format.ContentType())
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="recommendations.%s"`, format))
+	header.Set("Trailer", "X-Export-Rows, X-Export-Error")
+
+	rows, err := h.exportService.ExportRecommendations(c.Request.Context(), c.Writer, format, filter)
+	if err != nil && !c.Writer.Written() {
+		// Nada enviado todavía: se responde con un error JSON normal
+		header.Del("Content-Type")
+		header.Del("Content-Disposition")
+		header.Del("Trailer")
+		switch {
+		case stderrors.Is(err, domain.ErrUnknownRating):
+			c.Error(errors.NewAppError(http.StatusBadRequest, "Unknown rating filter", err))
+		case stderrors.Is(err, domain.ErrInvalidExport):
+			c.Error(errors.NewAppError(http.StatusBadRequest, err.Error(), err))
+		default:
+			c.Error(errors.NewAppError(http.StatusInternalServerError, "Failed to export recommendations", err))
+		}
+		return
+	}
+
+	header.Set("X-Export-Rows", strconv.Itoa(rows))
+	if err != nil {
+		header.Set("X-Export-Error", err.Error())
+	}
+}
+
 // GetAvailableTickers godoc
 // @Summary Get available stock tickers
-// @Description Get list of all available stock tickers with recommendations
+// @Description Get active securities that have recommendations, with company, exchange, sector and industry
 // @Tags recommendations
 // @Accept json
 // @Produce json
-// @Success 200 {array} string "List of tickers"
+// @Param sector query string false "Sector to filter by (case-insensitive)"
+// @Success 200 {array} domain.Security "List of securities"
 // @Failure 500 {object} errors.AppError "Internal server error"
 // @Router /http/v1/recommendations/tickers [get]
 func (h *StockHandler) GetAvailableTickers(c *gin.Context) {
-	tickers, err := h.stockService.GetAvailableTickers(c.Request.Context())
+	tickers, err := h.stockService.GetAvailableTickers(c.Request.Context(), c.Query("sector"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
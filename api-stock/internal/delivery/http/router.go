@@ -2,6 +2,7 @@ package http
 
 import (
 	"api-stock/internal/domain" // Importa las interfaces de servicio del dominio
+	"context"
 	"time"
 
 	"github.com/gin-contrib/cors" // Middleware para manejo de CORS
@@ -9,19 +10,19 @@ import (
 )
 
 // SetupRoutes configura todas las rutas HTTP de la aplicación.
-func SetupRoutes(router *gin.Engine, stockService domain.StockService, recommendationService domain.RecommendationService) {
+func SetupRoutes(router *gin.Engine, stockService domain.StockService, recommendationService domain.RecommendationService, exportService domain.ExportService, exportTimeout time.Duration) {
 	// Middleware CORS para permitir solicitudes desde otros orígenes
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},                      // Permitir solicitudes desde cualquier origen
-		AllowMethods:     []string{"GET", "OPTIONS"},         // Métodos permitidos
-		AllowHeaders:     []string{"Origin", "Content-Type"}, // Cabeceras permitidas
-		ExposeHeaders:    []string{"Content-Length"},         // Cabeceras expuestas al cliente
-		AllowCredentials: true,                               // Permitir cookies y credenciales
-		MaxAge:           12 * time.Hour,                     // Tiempo de caché de la política CORS
+		AllowOrigins:     []string{"*"},                                       // Permitir solicitudes desde cualquier origen
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // Métodos permitidos
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"}, // Cabeceras permitidas
+		ExposeHeaders:    []string{"Content-Length"},                          // Cabeceras expuestas al cliente
+		AllowCredentials: true,                                                // Permitir cookies y credenciales
+		MaxAge:           12 * time.Hour,                                      // Tiempo de caché de la política CORS
 	}))
 
 	// Crea un nuevo handler pasando los servicios necesarios (inyección de dependencias)
-	handler := NewStockHandler(stockService, recommendationService)
+	handler := NewStockHandler(stockService, recommendationService, exportService, exportTimeout)
 
 	// Agrupa las rutas bajo el prefijo /http/v1 (versión de la API)
 	apiGroup := router.Group("/http/v1")
@@ -32,9 +33,69 @@ func SetupRoutes(router *gin.Engine, stockService domain.StockService, recommend
 		// Agrupa rutas relacionadas con recomendaciones bajo /recommendations
 		recGroup := apiGroup.Group("/recommendations")
 		{
-			recGroup.GET("", handler.GetRecommendations)          // Retorna todas las recomendaciones
-			recGroup.GET("/tickers", handler.GetAvailableTickers) // Retorna todos los tickers disponibles
-			recGroup.GET("/best", handler.GetBestRecommendations) // Retorna las mejores recomendaciones
+			recGroup.GET("", handler.GetRecommendations)                       // Retorna todas las recomendaciones
+			recGroup.GET("/tickers", handler.GetAvailableTickers)              // Retorna todos los tickers disponibles
+			recGroup.GET("/best", handler.GetBestRecommendations)              // Retorna las mejores recomendaciones
+			recGroup.GET("/export", handler.ExportRecommendations)             // Exporta las recomendaciones filtradas a un archivo
+			recGroup.GET("/:id", handler.GetRecommendation)                    // Retorna una recomendación por su ID
+			recGroup.GET("/:id/revisions", handler.GetRecommendationRevisions) // Historial de versiones anteriores
 		}
 	}
 }
+
+// SetupAdminRoutes configura las rutas de administración bajo /http/v1/admin, protegidas con el token de administración.
+// Debe llamarse después de SetupRoutes para que apliquen los mismos middlewares.
+func SetupAdminRoutes(router *gin.Engine, adminToken string, brokerageService domain.BrokerageService, securityService domain.SecurityService, syncRunService domain.SyncRunService, quarantineService domain.QuarantineService) {
+	handler := NewAdminHandler(brokerageService, securityService, syncRunService, quarantineService)
+
+	adminGroup := router.Group("/http/v1/admin", AdminAuth(adminToken))
+	{
+		// Registro maestro de firmas de corretaje
+		brokerageGroup := adminGroup.Group("/brokerages")
+		{
+			brokerageGroup.GET("", handler.ListBrokerages)                             // Lista firmas con sus alias
+			brokerageGroup.GET("/unresolved", handler.ListUnresolvedBrokerages)        // Nombres sin resolver
+			brokerageGroup.GET("/:id", handler.GetBrokerage)                           // Detalle de una firma
+			brokerageGroup.PUT("/:id", handler.SaveBrokerage)                          // Crea o actualiza una firma
+			brokerageGroup.POST("/:id/aliases", handler.AddBrokerageAlias)             // Agrega un alias
+			brokerageGroup.DELETE("/:id/aliases/:alias", handler.RemoveBrokerageAlias) // Quita un alias
+		}
+
+		// Registro maestro de valores
+		adminGroup.POST("/securities/import", handler.ImportSecurities) // Importa metadatos desde CSV
+
+		// Historial de sincronizaciones
+		adminGroup.GET("/syncs", handler.ListSyncRuns)   // Ejecuciones más recientes
+		adminGroup.GET("/syncs/:id", handler.GetSyncRun) // Detalle de una ejecución
+
+		// Registros rechazados por la validación de la ingesta
+		quarantineGroup := adminGroup.Group("/quarantine")
+		{
+			quarantineGroup.GET("", handler.ListQuarantine)                    // Lista registros en cuarentena
+			quarantineGroup.GET("/:id", handler.GetQuarantined)                // Detalle con motivo y payload
+			quarantineGroup.PUT("/:id", handler.FixQuarantined)                // Corrige el payload
+			quarantineGroup.POST("/:id/reingest", handler.ReingestQuarantined) // Guarda el registro corregido
+			quarantineGroup.POST("/:id/discard", handler.DiscardQuarantined)   // Descarta el registro
+		}
+	}
+}
+
+// SetupWorkerRoutes configura las rutas del servidor HTTP del worker: /healthz público y el control de
+// trabajos bajo /admin, protegido con el token de administración (vacío deshabilita el control).
+func SetupWorkerRoutes(router *gin.Engine, adminToken string, workerService domain.WorkerService, ping func(ctx context.Context) error) {
+	handler := NewWorkerHandler(workerService, ping)
+
+	router.GET("/healthz", handler.HealthCheck) // Verifica la conexión a la base de datos
+
+	if adminToken == "" {
+		return
+	}
+	adminGroup := router.Group("/admin", AdminAuth(adminToken))
+	{
+		adminGroup.GET("/jobs", handler.GetStatus)                  // Programación y progreso de cada trabajo
+		adminGroup.GET("/jobs/runs", handler.ListJobRuns)           // Historial de ejecuciones
+		adminGroup.POST("/jobs/:name", handler.TriggerJob)          // Ejecuta un trabajo ahora
+		adminGroup.POST("/schedule/pause", handler.PauseSchedule)   // Pausa la programación periódica
+		adminGroup.POST("/schedule/resume", handler.ResumeSchedule) // Reanuda la programación periódica
+	}
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"api-stock/internal/domain"
+	"api-stock/pkg/errors"
+	"context"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkerHandler agrupa los endpoints de salud y control de trabajos del worker.
+// Se sirven en el puerto del worker y no forman parte de la API documentada en Swagger.
+type WorkerHandler struct {
+	workerService domain.WorkerService
+	ping          func(ctx context.Context) error // Verifica la conexión a la base de datos
+}
+
+func NewWorkerHandler(workerService domain.WorkerService, ping func(ctx context.Context) error) *WorkerHandler {
+	return &WorkerHandler{
+		workerService: workerService,
+		ping:          ping,
+	}
+}
+
+// HealthCheck responde 200 si el worker llega a la base de datos y 503 si no.
+func (h *WorkerHandler) HealthCheck(c *gin.Context) {
+	if err := h.ping(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "unhealthy",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "healthy",
+	})
+}
+
+// GetStatus devuelve cada trabajo con su programación, sus ejecuciones en curso con su progreso y la última terminada.
+func (h *WorkerHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.workerService.Status())
+}
+
+// ListJobRuns lista las ejecuciones registradas más recientes primero, de todos los trabajos o del indicado en ?job=.
+func (h *WorkerHandler) ListJobRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	runs, err := h.workerService.ListJobRuns(c.Request.Context(), c.Query("job"), limit)
+	if err != nil {
+		c.Error(workerError(err, "Failed to list job runs"))
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// TriggerJob ejecuta un trabajo por nombre y responde 202; 409 si ya está en curso y su política de
+// solapamiento no permite otra ejecución.
+func (h *WorkerHandler) TriggerJob(c *gin.Context) {
+	if err := h.workerService.Trigger(c.Param("name")); err != nil {
+		c.Error(workerError(err, "Failed to trigger job"))
+		return
+	}
+	c.JSON(http.StatusAccepted, h.workerService.Status())
+}
+
+// PauseSchedule pausa la programación periódica; los trabajos manuales siguen permitidos.
+func (h *WorkerHandler) PauseSchedule(c *gin.Context) {
+	h.workerService.Pause()
+	c.JSON(http.StatusOK, h.workerService.Status())
+}
+
+// ResumeSchedule reanuda la programación periódica.
+func (h *WorkerHandler) ResumeSchedule(c *gin.Context) {
+	h.workerService.Resume()
+	c.JSON(http.StatusOK, h.workerService.Status())
+}
+
+// workerError traduce los errores del control de trabajos al código HTTP correspondiente.
+func workerError(err error, message string) *errors.AppError {
+	switch {
+	case stderrors.Is(err, domain.ErrUnknownJob):
+		return errors.NewAppError(http.StatusNotFound, err.Error(), err)
+	case stderrors.Is(err, domain.ErrJobRunning):
+		return errors.NewAppError(http.StatusConflict, err.Error(), err)
+	default:
+		return errors.NewAppError(http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,119 @@
+package domain
+
+import "strings"
+
+// ActionType es la clasificación canónica del evento de una recomendación.
+type ActionType string
+
+// Tipos de acción canónicos.
+const (
+	ActionUnknown       ActionType = "unknown"
+	ActionInitiate      ActionType = "initiate"
+	ActionUpgrade       ActionType = "upgrade"
+	ActionDowngrade     ActionType = "downgrade"
+	ActionTargetRaised  ActionType = "target_raised"
+	ActionTargetLowered ActionType = "target_lowered"
+	ActionReiterate     ActionType = "reiterate"
+	ActionMaintain      ActionType = "maintain"
+	ActionDropCoverage  ActionType = "drop_coverage"
+)
+
+// ActionTypes lista los tipos de acción canónicos conocidos.
+var ActionTypes = []ActionType{
+	ActionInitiate, ActionUpgrade, ActionDowngrade, ActionTargetRaised,
+	ActionTargetLowered, ActionReiterate, ActionMaintain, ActionDropCoverage,
+}
+
+// Valid indica si el tipo de acción es uno de los canónicos conocidos.
+func (a ActionType) Valid() bool {
+	for _, known := range ActionTypes {
+		if a == known {
+			return true
+		}
+	}
+	return false
+}
+
+// OrUnknown devuelve el tipo de acción, o ActionUnknown si no es válido.
+func (a ActionType) OrUnknown() ActionType {
+	if a.Valid() {
+		return a
+	}
+	return ActionUnknown
+}
+
+// actionKeywords asocia palabras del texto de la acción (inglés y español) con un tipo.
+// Se recorre en orden, así que el resultado es determinista.
+var actionKeywords = []struct {
+	Type     ActionType
+	Keywords []string
+}{
+	{ActionDropCoverage, []string{"dropped", "drops", "drop", "discontinued", "terminated", "terminates", "suspended", "retirada", "suspendida", "terminada"}},
+	{ActionInitiate, []string{"initiated", "initiates", "initiate", "resumed", "iniciado", "iniciada", "inicia", "reanudada"}},
+	{ActionUpgrade, []string{"upgraded", "upgrades", "upgrade", "mejorado", "mejora"}},
+	{ActionDowngrade, []string{"downgraded", "downgrades", "downgrade", "rebajado", "degradado", "empeorado"}},
+	{ActionTargetRaised, []string{"raised", "raises", "increased", "boosted", "aumentado", "subido", "elevado"}},
+	{ActionTargetLowered, []string{"lowered", "lowers", "cut", "cuts", "reduced", "decreased", "bajado", "reducido", "recortado"}},
+	{ActionReiterate, []string{"reiterated", "reiterates", "reiterate", "reaffirmed", "reiterado", "reafirmado"}},
+	{ActionMaintain, []string{"maintained", "maintains", "set", "updated", "mantenido", "mantiene", "establecido", "actualizado"}},
+}
+
+// ClassifyAction deriva el tipo de acción combinando el texto del proveedor con los cambios de
+// calificación (rating_from -> rating_to) y de precio objetivo (target_from -> target_to).
+//
+// Prioridad: retirada de cobertura e inicio (solo por texto), luego el cambio de calificación
+// canónica, luego upgrade/downgrade explícitos en el texto, luego el cambio de precio objetivo,
+// y por último el texto restante (subida/bajada de objetivo, reiteración, mantenimiento).
+func ClassifyAction(action string, ratingFrom, ratingTo Rating, targetFrom, targetTo Money) ActionType {
+	fromText := actionFromText(action)
+
+	switch fromText {
+	case ActionDropCoverage, ActionInitiate:
+		return fromText
+	}
+
+	// Un cambio de calificación manda sobre el texto ("target raised" con hold -> buy es un upgrade)
+	fromRank, fromOK := ratingFrom.Rank()
+	toRank, toOK := ratingTo.Rank()
+	if fromOK && toOK && fromRank != toRank {
+		if toRank > fromRank {
+			return ActionUpgrade
+		}
+		return ActionDowngrade
+	}
+
+	switch fromText {
+	case ActionUpgrade, ActionDowngrade:
+		return fromText
+	}
+
+	// El cambio de precio objetivo manda sobre el texto si ambos valores existen
+	if targetFrom.Valid && targetTo.Valid && targetFrom.Cents != targetTo.Cents {
+		if targetTo.Cents > targetFrom.Cents {
+			return ActionTargetRaised
+		}
+		return ActionTargetLowered
+	}
+
+	if fromText != ActionUnknown {
+		return fromText
+	}
+	// Sin texto reconocible pero con calificación y objetivo sin cambios: se mantiene
+	if fromOK && toOK && targetFrom.Valid && targetTo.Valid {
+		return ActionMaintain
+	}
+	return ActionUnknown
+}
+
+// actionFromText busca en el texto de la acción la primera palabra clave conocida.
+func actionFromText(action string) ActionType {
+	padded := " " + normalizeTextKey(action) + " "
+	for _, entry := range actionKeywords {
+		for _, keyword := range entry.Keywords {
+			if strings.Contains(padded, " "+keyword+" ") {
+				return entry.Type
+			}
+		}
+	}
+	return ActionUnknown
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+func TestClassifyAction(t *testing.T) {
+	usd := func(amount float64) Money { return NewMoney(amount, "USD") }
+	none := Money{}
+
+	tests := []struct {
+		name                 string
+		action               string
+		ratingFrom, ratingTo Rating
+		targetFrom, targetTo Money
+		want                 ActionType
+	}{
+		{"retirada de cobertura manda sobre todo", "coverage dropped by", RatingBuy, RatingSell, usd(10), usd(5), ActionDropCoverage},
+		{"inicio manda sobre el cambio de objetivo", "initiated by", RatingUnknown, RatingBuy, usd(10), usd(12), ActionInitiate},
+		{"inicio en español", "Cobertura iniciada por", RatingUnknown, RatingHold, none, none, ActionInitiate},
+		{"el cambio de calificación manda sobre el texto", "target raised by", RatingHold, RatingBuy, usd(10), usd(12), ActionUpgrade},
+		{"bajada de calificación", "reiterated by", RatingBuy, RatingStrongSell, none, none, ActionDowngrade},
+		{"upgrade en el texto sin cambio canónico", "upgraded by", RatingBuy, RatingBuy, usd(10), usd(8), ActionUpgrade},
+		{"downgrade en el texto con calificaciones desconocidas", "Downgraded by", RatingUnknown, RatingUnknown, none, none, ActionDowngrade},
+		{"subida de objetivo por importes", "reiterated by", RatingBuy, RatingBuy, usd(10), usd(12), ActionTargetRaised},
+		{"el cambio de importes manda sobre el texto", "target raised by", RatingBuy, RatingBuy, usd(12), usd(10), ActionTargetLowered},
+		{"subida de objetivo solo por texto", "target raised by", RatingUnknown, RatingUnknown, none, usd(12), ActionTargetRaised},
+		{"bajada de objetivo solo por texto", "price target cut by", RatingUnknown, RatingUnknown, none, none, ActionTargetLowered},
+		{"reiteración con objetivo igual", "reiterated by", RatingBuy, RatingBuy, usd(10), usd(10), ActionReiterate},
+		{"mantenimiento por texto", "target set by", RatingUnknown, RatingUnknown, none, none, ActionMaintain},
+		{"mantenimiento sin texto reconocible", "note from", RatingHold, RatingHold, usd(10), usd(10), ActionMaintain},
+		{"sin información suficiente", "note from", RatingHold, RatingHold, none, usd(10), ActionUnknown},
+		{"texto vacío", "", RatingUnknown, RatingUnknown, none, none, ActionUnknown},
+		{"las palabras clave deben ser palabras completas", "setback by", RatingUnknown, RatingUnknown, none, none, ActionUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyAction(tt.action, tt.ratingFrom, tt.ratingTo, tt.targetFrom, tt.targetTo)
+			if got != tt.want {
+				t.Errorf("ClassifyAction(%q, %q, %q, %v, %v) = %q, want %q",
+					tt.action, tt.ratingFrom, tt.ratingTo, tt.targetFrom, tt.targetTo, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrArchiveEmpty indica que no hay páginas archivadas de un proveedor para reprocesar.
+var ErrArchiveEmpty = errors.New("no hay páginas archivadas para reprocesar")
+
+// ArchivedPage es una respuesta del proveedor tal como se recibió, con los datos de la petición.
+// El archivo permite reconstruir las recomendaciones cuando cambian las reglas de decodificación,
+// validación o normalización. Una página idéntica que se vuelve a pedir se guarda una sola vez.
+type ArchivedPage struct {
+	// Identificador de la página archivada
+	ID string `json:"id"`
+	// Proveedor que la devolvió
+	Source string `json:"source"`
+	// Última ejecución de sincronización que la pidió
+	SyncRunID string `json:"sync_run_id,omitempty"`
+	// Token o número de página pedido (vacío es la primera página)
+	PageToken string `json:"page_token"`
+	// URL pedida
+	URL string `json:"url"`
+	// Código de estado HTTP de la respuesta
+	StatusCode int `json:"status_code"`
+	// Cabecera Content-Type de la respuesta
+	ContentType string `json:"content_type"`
+	// Cuerpo de la respuesta sin comprimir
+	Body []byte `json:"-"`
+	// SHA-256 del cuerpo
+	Fingerprint string `json:"fingerprint"`
+	// Primera vez que se recibió
+	FirstFetchedAt time.Time `json:"first_fetched_at"`
+	// Última vez que se recibió
+	FetchedAt time.Time `json:"fetched_at"`
+	// Número de veces que se recibió
+	Fetches int `json:"fetches"`
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrBrokerageNotFound indica que no existe una firma de corretaje con el ID indicado.
+var ErrBrokerageNotFound = errors.New("firma de corretaje no encontrada")
+
+// ErrInvalidBrokerage indica que los datos de la firma o del alias no son válidos.
+var ErrInvalidBrokerage = errors.New("firma de corretaje inválida")
+
+// ErrAliasConflict indica que el alias ya está asignado a otra firma de corretaje.
+var ErrAliasConflict = errors.New("el alias ya pertenece a otra firma de corretaje")
+
+// Brokerage es el registro maestro de una firma de corretaje.
+// @Brokerage
+type Brokerage struct {
+	// Identificador canónico (slug estable)
+	ID string `json:"id" example:"morgan_stanley"`
+	// Nombre para mostrar
+	Name string `json:"name" example:"Morgan Stanley"`
+	// Textos del proveedor que se resuelven a esta firma
+	Aliases []string `json:"aliases" example:"morgan stanley,morgan stanley & co"`
+	// Peso de reputación usado por el modelo de recomendación
+	ReputationWeight float64 `json:"reputation_weight" example:"1.2"`
+	// Fecha de creación
+	CreatedAt time.Time `json:"created_at"`
+	// Fecha de última modificación
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UnresolvedBrokerage es un nombre de firma recibido del proveedor que no coincide con ningún alias.
+// @UnresolvedBrokerage
+type UnresolvedBrokerage struct {
+	// Texto tal como lo envió el proveedor
+	Name string `json:"name" example:"Morgan Stanly"`
+	// Número de registros ingeridos con este nombre
+	Occurrences int64 `json:"occurrences" example:"12"`
+	// Primera vez que se recibió
+	FirstSeen time.Time `json:"first_seen"`
+	// Última vez que se recibió
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// brokerageKeyPunctuation elimina la puntuación habitual en nombres de firmas ("J.P. Morgan", "Co.,").
+var brokerageKeyPunctuation = regexp.MustCompile(`[.,'()&]+`)
+
+// BrokerageKey lleva un nombre de firma a la clave usada para buscar alias:
+// minúsculas, sin acentos, sin puntuación y con separadores unificados ("J.P. Morgan" -> "j p morgan").
+// La migración 0005 replica esta misma transformación en SQL.
+func BrokerageKey(name string) string {
+	return normalizeTextKey(brokerageKeyPunctuation.ReplaceAllString(name, " "))
+}
+
+// BrokerageResolver traduce el texto libre del proveedor al ID canónico de la firma.
+// Solo acepta coincidencias exactas de alias, de modo que "Morgan Stanley" y "JPMorgan"
+// nunca se confunden por compartir una subcadena.
+type BrokerageResolver struct {
+	ids map[string]string // clave de alias -> ID de la firma
+}
+
+// NewBrokerageResolver crea un resolvedor a partir de las firmas registradas.
+// El nombre para mostrar de cada firma cuenta también como alias.
+func NewBrokerageResolver(brokerages []Brokerage) *BrokerageResolver {
+	ids := make(map[string]string)
+	for _, b := range brokerages {
+		if key := BrokerageKey(b.Name); key != "" {
+			ids[key] = b.ID
+		}
+		for _, alias := range b.Aliases {
+			if key := BrokerageKey(alias); key != "" {
+				ids[key] = b.ID
+			}
+		}
+	}
+	return &BrokerageResolver{ids: ids}
+}
+
+// Resolve devuelve el ID de la firma para un nombre del proveedor; el segundo valor es false si no se reconoce.
+func (r *BrokerageResolver) Resolve(name string) (string, bool) {
+	id, ok := r.ids[BrokerageKey(name)]
+	return id, ok
+}
+
+// ValidBrokerageID indica si el texto sirve como ID de firma: minúsculas, dígitos y guiones bajos.
+func ValidBrokerageID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	return strings.Trim(id, "abcdefghijklmnopqrstuvwxyz0123456789_") == ""
+}
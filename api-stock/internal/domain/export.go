@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExport indica que el formato o los filtros de una exportación no son válidos.
+var ErrInvalidExport = errors.New("exportación inválida")
+
+// ExportFormat es el formato de archivo de una exportación de recomendaciones.
+type ExportFormat string
+
+// Formatos de exportación soportados.
+const (
+	ExportCSV     ExportFormat = "csv"     // CSV con encabezado (reimportable con cmd/import)
+	ExportNDJSON  ExportFormat = "ndjson"  // Un objeto JSON por línea, con el mismo formato que la API
+	ExportParquet ExportFormat = "parquet" // Parquet con precios DECIMAL(18,2) y fechas TIMESTAMP
+)
+
+// ParseExportFormat valida el nombre del formato; vacío equivale a CSV.
+func ParseExportFormat(raw string) (ExportFormat, error) {
+	switch format := ExportFormat(strings.ToLower(strings.TrimSpace(raw))); format {
+	case "":
+		return ExportCSV, nil
+	case ExportCSV, ExportNDJSON, ExportParquet:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: formato desconocido %q (válidos: csv, ndjson, parquet)", ErrInvalidExport, raw)
+	}
+}
+
+// ContentType devuelve el tipo MIME del formato.
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportNDJSON:
+		return "application/x-ndjson"
+	case ExportParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// ExportColumns son las columnas de la exportación CSV, en orden. Coinciden con los campos JSON de
+// StockRecommendation, de modo que el archivo se puede volver a cargar con cmd/import.
+var ExportColumns = []string{
+	"id", "source", "ticker", "target_from", "target_to", "currency", "company", "action", "action_type",
+	"brokerage", "brokerage_id", "rating_from", "rating_to", "normalized_rating_from", "normalized_rating_to", "time",
+}
+
+// ExportRow devuelve los valores de la recomendación en el orden de ExportColumns.
+func ExportRow(rec StockRecommendation) []string {
+	return []string{
+		rec.ID, rec.Source, rec.Ticker, rec.TargetFrom.String(), rec.TargetTo.String(), rec.Currency, rec.Company,
+		rec.Action, string(rec.ActionType), rec.Brokerage, rec.BrokerageID, rec.RatingFrom, rec.RatingTo,
+		string(rec.NormalizedRatingFrom), string(rec.NormalizedRatingTo), rec.Time.UTC().Format(time.RFC3339),
+	}
+}
+
+// ParseTimeBound interpreta un límite del rango de fechas: RFC 3339 o una fecha "2006-01-02" (UTC).
+// Una fecha sin hora como límite final (end) incluye el día completo. Vacío devuelve el tiempo cero.
+func ParseTimeBound(raw string, end bool) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: fecha inválida %q (se espera 2006-01-02 o RFC 3339)", ErrInvalidExport, raw)
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
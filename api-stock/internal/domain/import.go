@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidImport indica que el archivo de importación no se puede leer (formato o estructura).
+var ErrInvalidImport = errors.New("archivo de importación inválido")
+
+// ImportFormat es el formato de un archivo de recomendaciones.
+type ImportFormat string
+
+// Formatos de importación soportados.
+const (
+	ImportCSV    ImportFormat = "csv"    // CSV con encabezado
+	ImportJSON   ImportFormat = "json"   // Arreglo JSON de objetos, o un objeto con el arreglo en "items"
+	ImportNDJSON ImportFormat = "ndjson" // Un objeto JSON por línea
+)
+
+// ImportFormatFromPath deduce el formato por la extensión del archivo (.csv, .json, .ndjson o .jsonl).
+func ImportFormatFromPath(path string) (ImportFormat, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ImportCSV, true
+	case ".json":
+		return ImportJSON, true
+	case ".ndjson", ".jsonl":
+		return ImportNDJSON, true
+	default:
+		return "", false
+	}
+}
+
+// ImportOptions configura una importación de recomendaciones desde archivo.
+type ImportOptions struct {
+	Format     ImportFormat // Formato del archivo
+	Mapping    FieldMapping // Campo canónico -> columna o ruta en el archivo
+	TimeLayout string       // Formato Go de la fecha si no es RFC 3339
+	Source     string       // Proveedor con el que se guardan las recomendaciones
+	DryRun     bool         // Solo lee, mapea y valida; no escribe nada
+	BatchSize  int          // Registros por lote de inserción
+}
+
+// RejectedRow es un registro del archivo que no se pudo importar.
+type RejectedRow struct {
+	Row    int    // Número de registro (fila de datos del CSV, línea del NDJSON o posición en el arreglo JSON), desde 1
+	Reason string // Motivo del rechazo
+	Raw    string // Contenido original del registro
+}
+
+// ImportReport resume el resultado de una importación.
+type ImportReport struct {
+	Read     int           // Registros leídos
+	Accepted int           // Registros válidos (guardados, o que se guardarían en modo dry-run)
+	Stats    WriteStats    // Efecto sobre las recomendaciones (vacío en modo dry-run)
+	Rejected []RejectedRow // Registros rechazados
+}
+
+// RecordReader recorre los registros de un archivo de importación como objetos genéricos.
+type RecordReader interface {
+	// Next devuelve el siguiente registro con su número y su contenido original. Un error de tipo
+	// *RecordError afecta solo a ese registro; cualquier otro error detiene la lectura. Devuelve io.EOF al final.
+	Next() (row int, record map[string]interface{}, raw string, err error)
+}
+
+// RecordError es un registro ilegible que se rechaza sin detener la importación.
+type RecordError struct {
+	Err error
+}
+
+func (e *RecordError) Error() string { return e.Err.Error() }
+
+func (e *RecordError) Unwrap() error { return e.Err }
+
+// NewRecordReader crea el lector de registros para el formato indicado.
+func NewRecordReader(r io.Reader, format ImportFormat) (RecordReader, error) {
+	switch format {
+	case ImportCSV:
+		return newCSVRecordReader(r)
+	case ImportJSON:
+		return newJSONRecordReader(r)
+	case ImportNDJSON:
+		return newNDJSONRecordReader(r), nil
+	default:
+		return nil, fmt.Errorf("%w: formato desconocido %q", ErrInvalidImport, format)
+	}
+}
+
+// csvRecordReader lee un CSV con encabezado; cada fila es un objeto columna -> texto.
+type csvRecordReader struct {
+	reader *csv.Reader
+	header []string
+	row    int
+}
+
+func newCSVRecordReader(r io.Reader) (*csvRecordReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // Las filas con columnas de menos o de más se validan por registro
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: el CSV está vacío", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	for i, name := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	}
+	return &csvRecordReader{reader: reader, header: header}, nil
+}
+
+func (c *csvRecordReader) Next() (int, map[string]interface{}, string, error) {
+	fields, err := c.reader.Read()
+	if err == io.EOF {
+		return 0, nil, "", io.EOF
+	}
+	c.row++
+	if err != nil {
+		return c.row, nil, "", fmt.Errorf("%w: %v", ErrInvalidImport, err) // Comillas rotas: no se puede seguir
+	}
+
+	raw := strings.Join(fields, ",")
+	if len(fields) != len(c.header) {
+		return c.row, nil, raw, &RecordError{fmt.Errorf("la fila tiene %d columnas y el encabezado %d", len(fields), len(c.header))}
+	}
+	record := make(map[string]interface{}, len(fields))
+	for i, value := range fields {
+		record[c.header[i]] = strings.TrimSpace(value)
+	}
+	return c.row, record, raw, nil
+}
+
+// jsonRecordReader lee un documento JSON completo: un arreglo de objetos o un objeto con "items".
+type jsonRecordReader struct {
+	items []json.RawMessage
+	row   int
+}
+
+func newJSONRecordReader(r io.Reader) (*jsonRecordReader, error) {
+	var document json.RawMessage
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(document, &items); err != nil {
+		var wrapper struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(document, &wrapper); err != nil || wrapper.Items == nil {
+			return nil, fmt.Errorf("%w: se esperaba un arreglo de objetos o un objeto con items", ErrInvalidImport)
+		}
+		items = wrapper.Items
+	}
+	return &jsonRecordReader{items: items}, nil
+}
+
+func (j *jsonRecordReader) Next() (int, map[string]interface{}, string, error) {
+	if j.row >= len(j.items) {
+		return 0, nil, "", io.EOF
+	}
+	raw := j.items[j.row]
+	j.row++
+	record, err := decodeJSONRecord(raw)
+	return j.row, record, string(raw), err
+}
+
+// ndjsonRecordReader lee un objeto JSON por línea; las líneas vacías se saltan.
+type ndjsonRecordReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONRecordReader(r io.Reader) *ndjsonRecordReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024) // Registros de hasta 4 MB
+	return &ndjsonRecordReader{scanner: scanner}
+}
+
+func (n *ndjsonRecordReader) Next() (int, map[string]interface{}, string, error) {
+	for n.scanner.Scan() {
+		n.line++
+		raw := strings.TrimSpace(n.scanner.Text())
+		if raw == "" {
+			continue
+		}
+		record, err := decodeJSONRecord([]byte(raw))
+		return n.line, record, raw, err
+	}
+	if err := n.scanner.Err(); err != nil {
+		return n.line, nil, "", fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return 0, nil, "", io.EOF
+}
+
+// decodeJSONRecord decodifica un objeto JSON conservando los números tal cual para Money.
+func decodeJSONRecord(raw []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	var record map[string]interface{}
+	if err := decoder.Decode(&record); err != nil || record == nil {
+		return nil, &RecordError{fmt.Errorf("el registro no es un objeto JSON válido")}
+	}
+	return record, nil
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"time"
 )
 
@@ -11,23 +13,50 @@ import (
 
 // StockRepository define métodos para interactuar con la base de datos de recomendaciones de acciones.
 type StockRepository interface {
-	// Obtiene recomendaciones de acciones filtradas por ticker, con paginación.
-	GetRecommendations(ctx context.Context, ticker string, page int, limit int) ([]StockRecommendation, int, error)
+	// Obtiene recomendaciones de acciones filtradas (ticker, calificación, sector, proveedor), con paginación.
+	GetRecommendations(ctx context.Context, filter RecommendationFilter, page int, limit int) ([]StockRecommendation, int, error)
 
-	// Obtiene una lista de todos los tickers disponibles en la base de datos.
+	// Obtiene una recomendación por su ID (ErrRecommendationNotFound si no existe).
+	GetRecommendation(ctx context.Context, id string) (*StockRecommendation, error)
+
+	// Obtiene las versiones anteriores de una recomendación, la más reciente primero.
+	GetRecommendationRevisions(ctx context.Context, id string) ([]RecommendationRevision, error)
+
+	// Obtiene los tickers distintos que tienen recomendaciones, estén o no en el registro maestro.
 	GetAvailableTickers(ctx context.Context) ([]string, error)
 
-	// Inserta múltiples recomendaciones en la base de datos.
-	InsertRecommendations(ctx context.Context, recommendations []StockRecommendation) error
+	// Recorre con un cursor todas las recomendaciones que cumplen el filtro, de la más reciente a la más
+	// antigua, llamando a fn con cada una. Un error de fn detiene el recorrido y se devuelve tal cual.
+	StreamRecommendations(ctx context.Context, filter RecommendationFilter, fn func(StockRecommendation) error) error
+
+	// Inserta o actualiza múltiples recomendaciones en la base de datos y devuelve su efecto. La versión
+	// anterior de cada fila que cambia se guarda como revisión de la ejecución indicada (vacío si no hay).
+	InsertRecommendations(ctx context.Context, recommendations []StockRecommendation, syncRunID string) (WriteStats, error)
 
 	// Obtiene recomendaciones recientes según un umbral de tiempo (ej: últimas 24h).
 	GetRecentRecommendations(ctx context.Context, since time.Duration) ([]StockRecommendation, error)
 
-	// Obtiene la recomendación más reciente.
-	GetLatestRecommendation(ctx context.Context) (*StockRecommendation, error)
+	// Obtiene la recomendación más reciente del proveedor.
+	GetLatestRecommendation(ctx context.Context, source string) (*StockRecommendation, error)
+
+	// Cuenta las recomendaciones guardadas del proveedor.
+	CountRecommendations(ctx context.Context, source string) (int, error)
+
+	// Vacía la parte de staging del proveedor, donde se carga su sincronización completa.
+	ResetStaging(ctx context.Context, source string) error
+
+	// Inserta un lote de recomendaciones en staging.
+	InsertStagingRecommendations(ctx context.Context, recommendations []StockRecommendation) error
+
+	// Cuenta las recomendaciones del proveedor cargadas en staging.
+	CountStagingRecommendations(ctx context.Context, source string) (int, error)
 
-	// Elimina todas las recomendaciones (útil para reiniciar datos).
-	DeleteAllRecommendations(ctx context.Context) error
+	// Reemplaza las recomendaciones del proveedor por su contenido de staging, sin vaciarlo, y devuelve el
+	// efecto del cambio. Las consultas ven el dataset anterior o el nuevo, nunca uno a medias. Las filas que
+	// cambian se guardan como revisiones de la ejecución. Es idempotente: si falla a medias, volver a
+	// llamarlo (o escribir recomendaciones o staging del proveedor) termina el intercambio. Con un lease en ctx
+	// (domain.WithLeaseFence), cada transacción comprueba que sigue vigente (domain.ErrLeaseLost si no).
+	SwapStaging(ctx context.Context, source, syncRunID string) (WriteStats, error)
 
 	// Obtiene los features vectoriales de una acción específica (para recomendaciones basadas en similitud).
 	GetStockFeatures(ctx context.Context, ticker string) (map[string]float64, error)
@@ -42,30 +71,223 @@ type StockRepository interface {
 	Ping(ctx context.Context) error
 }
 
+// BrokerageRepository gestiona el registro maestro de firmas de corretaje y sus alias.
+type BrokerageRepository interface {
+	// Lista todas las firmas con sus alias.
+	ListBrokerages(ctx context.Context) ([]Brokerage, error)
+
+	// Obtiene una firma por ID (ErrBrokerageNotFound si no existe).
+	GetBrokerage(ctx context.Context, id string) (*Brokerage, error)
+
+	// Crea o actualiza el nombre y el peso de reputación de una firma.
+	SaveBrokerage(ctx context.Context, brokerage Brokerage) error
+
+	// Asigna un alias a una firma (ErrAliasConflict si pertenece a otra) y enlaza los registros
+	// existentes cuyo nombre coincide con el alias.
+	AddAlias(ctx context.Context, id, alias string) error
+
+	// Elimina un alias de una firma.
+	RemoveAlias(ctx context.Context, id, alias string) error
+
+	// Registra los nombres que no se pudieron resolver durante la ingesta, con su número de apariciones.
+	RecordUnresolved(ctx context.Context, counts map[string]int) error
+
+	// Lista los nombres no resueltos, de más a menos frecuente.
+	ListUnresolved(ctx context.Context, limit int) ([]UnresolvedBrokerage, error)
+}
+
+// SecurityRepository gestiona el registro maestro de valores (ticker, empresa, sector...).
+type SecurityRepository interface {
+	// Lista los valores que cumplen el filtro, ordenados por ticker.
+	ListSecurities(ctx context.Context, filter SecurityFilter) ([]Security, error)
+
+	// Crea o reemplaza los metadatos de los valores indicados.
+	UpsertSecurities(ctx context.Context, securities []Security) error
+}
+
+// QuarantineRepository persiste los registros rechazados por la ingesta.
+type QuarantineRepository interface {
+	// Guarda los registros rechazados; un registro ya conocido (misma huella y proveedor) solo actualiza
+	// su motivo, contador y última aparición, sin cambiar su estado ni el payload corregido. Devuelve los
+	// registros de la lista que ya estaban reingresados, con su payload corregido.
+	QuarantineRecords(ctx context.Context, records []QuarantinedRecord) ([]QuarantinedRecord, error)
+
+	// Lista los registros que cumplen el filtro, los vistos más recientemente primero.
+	ListQuarantine(ctx context.Context, filter QuarantineFilter, limit int) ([]QuarantinedRecord, error)
+
+	// Obtiene un registro por ID (ErrQuarantineNotFound si no existe).
+	GetQuarantined(ctx context.Context, id string) (*QuarantinedRecord, error)
+
+	// Guarda el payload, el motivo y el estado de un registro.
+	UpdateQuarantined(ctx context.Context, record QuarantinedRecord) error
+
+	// Elimina los registros ya reingresados o descartados que el proveedor no envía desde before.
+	DeleteResolvedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CheckpointRepository persiste el progreso de las sincronizaciones paginadas para poder reanudarlas.
+type CheckpointRepository interface {
+	// Obtiene el checkpoint por nombre, o nil si no hay una sincronización en curso.
+	GetCheckpoint(ctx context.Context, name string) (*SyncCheckpoint, error)
+
+	// Crea o actualiza el checkpoint.
+	SaveCheckpoint(ctx context.Context, checkpoint SyncCheckpoint) error
+
+	// Elimina el checkpoint al terminar (o descartar) la sincronización.
+	DeleteCheckpoint(ctx context.Context, name string) error
+}
+
+// SyncRunRepository guarda el historial de ejecuciones de sincronización.
+type SyncRunRepository interface {
+	// Registra una ejecución en curso; completa su ID y fecha de inicio.
+	StartSyncRun(ctx context.Context, run *SyncRun) error
+
+	// Guarda el progreso de una ejecución en curso (páginas y contadores hasta el momento).
+	UpdateSyncRun(ctx context.Context, run SyncRun) error
+
+	// Guarda el resultado final de la ejecución.
+	FinishSyncRun(ctx context.Context, run SyncRun) error
+
+	// Lista las ejecuciones más recientes primero.
+	ListSyncRuns(ctx context.Context, limit int) ([]SyncRun, error)
+
+	// Obtiene una ejecución por ID.
+	GetSyncRun(ctx context.Context, id string) (*SyncRun, error)
+
+	// Devuelve el inicio de la última sincronización completa que terminó bien, contando los intentos
+	// fallidos que reanudó; nil si el proveedor nunca completó una.
+	FullSyncStart(ctx context.Context, source string) (*time.Time, error)
+
+	// Elimina las ejecuciones terminadas del proveedor que empezaron antes de before.
+	DeleteSyncRunsBefore(ctx context.Context, source string, before time.Time) (int64, error)
+}
+
+// JobRunRepository guarda el historial de ejecuciones de los trabajos del worker.
+type JobRunRepository interface {
+	// Registra una ejecución; completa su ID y, si está vacía, su fecha de inicio.
+	StartJobRun(ctx context.Context, run *JobRun) error
+
+	// Guarda el resultado final de la ejecución.
+	FinishJobRun(ctx context.Context, run JobRun) error
+
+	// Lista las ejecuciones más recientes primero; job vacío lista las de todos los trabajos.
+	ListJobRuns(ctx context.Context, job string, limit int) ([]JobRun, error)
+
+	// Elimina las ejecuciones terminadas que empezaron antes de before.
+	DeleteJobRunsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ArchiveRepository guarda las páginas del proveedor tal como se recibieron.
+type ArchiveRepository interface {
+	// Archiva la página; si ya hay una con el mismo cuerpo del mismo proveedor, solo actualiza su
+	// última recepción, su contador y la ejecución que la pidió.
+	ArchivePage(ctx context.Context, page ArchivedPage) error
+
+	// Recorre las páginas del proveedor recibidas desde since (cero recorre todo el archivo) en el orden
+	// de su última recepción y llama a fn con cada una. Un error de fn detiene el recorrido.
+	StreamArchivedPages(ctx context.Context, source string, since time.Time, fn func(ArchivedPage) error) error
+
+	// Elimina las páginas del proveedor cuya última recepción es anterior a before.
+	DeleteArchivedPagesBefore(ctx context.Context, source string, before time.Time) (int64, error)
+}
+
+// LeaseRepository gestiona los leases que impiden que dos procesos ejecuten el mismo trabajo a la vez.
+type LeaseRepository interface {
+	// Toma el lease para owner durante ttl si está libre o vencido, con un token mayor que el de todas
+	// las tomas anteriores; devuelve nil si lo tiene otro proceso.
+	AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (*Lease, error)
+
+	// Extiende el lease de owner durante ttl; devuelve false si ya no es suyo.
+	RenewLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+
+	// Libera el lease si sigue siendo de owner; conserva su token.
+	ReleaseLease(ctx context.Context, name, owner string) error
+
+	// Obtiene el lease vigente, o nil si nadie lo tiene.
+	GetLease(ctx context.Context, name string) (*Lease, error)
+}
+
+// ScoreSnapshotRepository guarda la foto diaria de los scores de cada ticker.
+type ScoreSnapshotRepository interface {
+	// Reemplaza la foto del día indicado (UTC) por los scores dados.
+	SaveScoreSnapshot(ctx context.Context, day time.Time, scores []TickerScore) error
+}
+
+// QuotaRepository lleva la cuenta de peticiones de cada proveedor por día UTC, compartida por todos los
+// procesos, para respetar su cuota diaria.
+type QuotaRepository interface {
+	// Descuenta una petición de la cuota del día si se han hecho menos de limit, en una sola operación
+	// atómica. Devuelve las peticiones del día después de descontar, o las ya hechas y false si no quedaba cuota.
+	TakeQuota(ctx context.Context, provider string, day time.Time, limit int) (int, bool, error)
+
+	// Devuelve a la cuota del día una petición que no llegó a hacerse.
+	RefundQuota(ctx context.Context, provider string, day time.Time) error
+
+	// Obtiene las peticiones descontadas de la cuota del día.
+	GetQuotaUsed(ctx context.Context, provider string, day time.Time) (int, error)
+}
+
 //////////////////////////////
 // Interfaces para API externa
 //////////////////////////////
 
-// ExternalAPI representa un cliente que se comunica con una API externa.
+// ExternalAPI representa un cliente que se comunica con una API externa (un proveedor de datos).
 type ExternalAPI interface {
-	// Obtiene un conjunto de recomendaciones desde una API paginada.
-	GetRecommendations(ctx context.Context, nextPage string) ([]StockRecommendation, string, error)
+	// Nombre del proveedor; se guarda como source de cada recomendación.
+	Name() string
+
+	// Obtiene una página de recomendaciones desde una API paginada. Los registros que no se pueden
+	// decodificar o no superan ValidateRecommendation se devuelven en Rejected en lugar de hacer fallar la página.
+	GetRecommendations(ctx context.Context, nextPage string) (*APIResponse, error)
+
+	// Decodifica y valida un registro del proveedor (por ejemplo, el payload corregido de la cuarentena).
+	DecodeRecord(payload json.RawMessage) (StockRecommendation, error)
 
-	// Obtiene todas las recomendaciones disponibles (sin paginar, si la API lo permite).
-	GetAllRecommendations(ctx context.Context) ([]StockRecommendation, error)
+	// Decodifica una página archivada con el mapeo de campos y la validación actuales.
+	DecodePage(page ArchivedPage) (*APIResponse, error)
+
+	// Recorre las páginas a partir de nextPage ("" es la primera) pidiendo las siguientes mientras se procesan
+	// las ya recibidas. La lectura se detiene al cancelar ctx o al cerrar el stream. Las páginas deben llegar
+	// de la más reciente a la más antigua; la sincronización incremental lo comprueba y falla con ErrPageOrder.
+	StreamPages(ctx context.Context, nextPage string, options PageStreamOptions) PageStream
+}
+
+// PageStream entrega en orden las páginas de un proveedor. Solo retiene las páginas pedidas por adelantado:
+// si no se consumen, deja de pedir más.
+type PageStream interface {
+	// Next devuelve la siguiente página, o io.EOF después de la última. Tras un error el stream termina.
+	Next() (*APIResponse, error)
+
+	// Close detiene la lectura y espera a que termine la petición en curso. Se puede llamar más de una vez.
+	Close()
 }
 
 //////////////////////////////
 // Interfaces de Servicios
 //////////////////////////////
 
+// Normalizer aplica las reglas de normalización a los registros ingeridos antes de guardarlos
+// (calificaciones canónicas, etc.). Lo usan la sincronización y cualquier otra vía de ingesta.
+type Normalizer interface {
+	// Normaliza las recomendaciones recibidas y devuelve el resultado.
+	Normalize(ctx context.Context, recommendations []StockRecommendation) ([]StockRecommendation, error)
+}
+
 // StockService expone operaciones disponibles para el frontend (UI/API REST).
 type StockService interface {
-	// Retorna recomendaciones para un ticker (con paginación).
-	GetRecommendations(ctx context.Context, ticker string, page, limit int) ([]StockRecommendation, int, error)
+	// Retorna recomendaciones filtradas por ticker, calificación, sector y proveedor (con paginación).
+	// La calificación puede ser un código canónico o cualquier alias conocido ("outperform", "comprar").
+	GetRecommendations(ctx context.Context, ticker, rating, sector, source string, page, limit int) ([]StockRecommendation, int, error)
 
-	// Lista de tickers disponibles.
-	GetAvailableTickers(ctx context.Context) ([]string, error)
+	// Obtiene una recomendación por su ID.
+	GetRecommendation(ctx context.Context, id string) (*StockRecommendation, error)
+
+	// Obtiene el historial de versiones anteriores de una recomendación, la más reciente primero,
+	// con los campos que cambiaron en cada reemplazo (ErrRecommendationNotFound si no hay ni fila ni historial).
+	GetRecommendationRevisions(ctx context.Context, id string) ([]RecommendationRevision, error)
+
+	// Lista de valores activos con recomendaciones, con sus metadatos; sector vacío no filtra.
+	GetAvailableTickers(ctx context.Context, sector string) ([]Security, error)
 
 	// Verifica el estado del sistema (ej. conectividad a DB).
 	HealthCheck(ctx context.Context) error
@@ -78,6 +300,79 @@ type RecommendationService interface {
 
 	// Busca acciones similares a un ticker dado (basado en features vectoriales, KNN u otra heurística).
 	FindSimilarStocks(ctx context.Context, ticker string, k int) ([]SimilarStock, error)
+
+	// Calcula el score de cada ticker con recomendaciones recientes, del mayor al menor, sin caché.
+	ScoreTickers(ctx context.Context) ([]TickerScore, error)
+}
+
+// BrokerageService expone la administración del registro maestro de firmas de corretaje.
+type BrokerageService interface {
+	// Lista todas las firmas con sus alias.
+	ListBrokerages(ctx context.Context) ([]Brokerage, error)
+
+	// Obtiene una firma por ID.
+	GetBrokerage(ctx context.Context, id string) (*Brokerage, error)
+
+	// Crea o actualiza una firma.
+	SaveBrokerage(ctx context.Context, brokerage Brokerage) (*Brokerage, error)
+
+	// Agrega un alias a una firma.
+	AddAlias(ctx context.Context, id, alias string) (*Brokerage, error)
+
+	// Quita un alias de una firma.
+	RemoveAlias(ctx context.Context, id, alias string) (*Brokerage, error)
+
+	// Lista los nombres de firmas que la ingesta no pudo resolver.
+	ListUnresolved(ctx context.Context, limit int) ([]UnresolvedBrokerage, error)
+}
+
+// SecurityService expone la administración del registro maestro de valores.
+type SecurityService interface {
+	// Lista los valores que cumplen el filtro.
+	ListSecurities(ctx context.Context, filter SecurityFilter) ([]Security, error)
+
+	// Importa un CSV de valores y devuelve cuántos se crearon o actualizaron.
+	ImportCSV(ctx context.Context, r io.Reader) (int, error)
+}
+
+// ExportService escribe recomendaciones filtradas en un archivo sin cargarlas todas en memoria.
+type ExportService interface {
+	// Escribe en w las recomendaciones que cumplen el filtro en el formato indicado y devuelve cuántas escribió.
+	// Rating admite códigos canónicos o alias, igual que en GetRecommendations.
+	ExportRecommendations(ctx context.Context, w io.Writer, format ExportFormat, filter RecommendationFilter) (int, error)
+}
+
+// ImportService carga recomendaciones desde archivos por la misma vía de normalización e inserción que la sincronización.
+type ImportService interface {
+	// Importa las recomendaciones del archivo y devuelve el resumen con los registros rechazados.
+	ImportRecommendations(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error)
+}
+
+// QuarantineService permite revisar, corregir y reingresar los registros rechazados por la ingesta.
+type QuarantineService interface {
+	// Lista los registros en cuarentena (por defecto 100, máximo 500).
+	ListQuarantine(ctx context.Context, filter QuarantineFilter, limit int) ([]QuarantinedRecord, error)
+
+	// Obtiene un registro en cuarentena por ID.
+	GetQuarantined(ctx context.Context, id string) (*QuarantinedRecord, error)
+
+	// Reemplaza el payload de un registro pendiente y recalcula el motivo con las reglas de validación.
+	FixQuarantined(ctx context.Context, id string, payload json.RawMessage) (*QuarantinedRecord, error)
+
+	// Decodifica, valida y guarda como recomendación un registro pendiente, y lo marca como reingresado.
+	ReingestQuarantined(ctx context.Context, id string) (*StockRecommendation, error)
+
+	// Marca un registro pendiente como descartado; si el proveedor lo vuelve a enviar, sigue descartado.
+	DiscardQuarantined(ctx context.Context, id string) (*QuarantinedRecord, error)
+}
+
+// SyncRunService expone el historial de sincronizaciones.
+type SyncRunService interface {
+	// Lista las ejecuciones más recientes primero.
+	ListSyncRuns(ctx context.Context, limit int) ([]SyncRun, error)
+
+	// Obtiene una ejecución por ID.
+	GetSyncRun(ctx context.Context, id string) (*SyncRun, error)
 }
 
 // ExternalAPIService encapsula la lógica de sincronización entre la API externa y la base de datos.
@@ -87,4 +382,39 @@ type ExternalAPIService interface {
 
 	// Realiza una sincronización incremental (nuevas páginas desde la última guardada).
 	IncrementalSync(ctx context.Context) error
+
+	// Reconstruye las recomendaciones a partir de las páginas archivadas, con las reglas actuales.
+	ReprocessArchive(ctx context.Context) error
+}
+
+// WorkerService controla los trabajos programados del worker.
+type WorkerService interface {
+	// Ejecuta el trabajo ahora; domain.ErrUnknownJob si no existe y domain.ErrJobRunning si ya está
+	// en curso (salvo que su política de solapamiento lo permita).
+	Trigger(name string) error
+
+	// Pausa la programación; las ejecuciones en curso terminan normalmente.
+	Pause()
+
+	// Reanuda la programación.
+	Resume()
+
+	// Devuelve el estado de cada trabajo con el progreso de sus ejecuciones en curso.
+	Status() WorkerStatus
+
+	// Lista las ejecuciones más recientes primero; job vacío lista las de todos los trabajos.
+	ListJobRuns(ctx context.Context, job string, limit int) ([]JobRun, error)
+}
+
+// ScoreSnapshotService guarda los scores del día para seguir su evolución.
+type ScoreSnapshotService interface {
+	// Calcula los scores actuales y los guarda como la foto del día.
+	SnapshotScores(ctx context.Context) error
+}
+
+// RetentionService elimina el historial que ya no se necesita.
+type RetentionService interface {
+	// Elimina las ejecuciones, los registros de cuarentena resueltos y las páginas archivadas más antiguos
+	// que la retención, conservando lo que necesita el reprocesamiento.
+	Purge(ctx context.Context) error
 }
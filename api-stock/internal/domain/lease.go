@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLeaseHeld indica que otro proceso tiene el lease y el trabajo no se ejecutó.
+var ErrLeaseHeld = errors.New("otro proceso tiene el lease")
+
+// ErrLeaseLost indica que el lease se perdió (no se pudo renovar a tiempo o lo tomó otro proceso)
+// mientras el trabajo estaba en curso; el trabajo se canceló.
+var ErrLeaseLost = errors.New("se perdió el lease")
+
+// Lease es un permiso exclusivo y con vencimiento, guardado en la base de datos, para ejecutar un trabajo.
+// Quien lo tiene lo renueva periódicamente; si el proceso cae, vence y otro puede tomarlo.
+// @Lease
+type Lease struct {
+	// Nombre del trabajo protegido
+	Name string `json:"name" example:"sync"`
+	// Proceso que lo tiene (host, PID y sufijo aleatorio)
+	Owner string `json:"owner" example:"worker-7d9f-1-3fa2c1b0"`
+	// Momento en que se tomó
+	AcquiredAt time.Time `json:"acquired_at"`
+	// Última renovación
+	RenewedAt time.Time `json:"renewed_at"`
+	// Vencimiento si no se renueva
+	ExpiresAt time.Time `json:"expires_at"`
+	// Token de fencing: aumenta con cada toma, de modo que identifica la toma vigente
+	Token int64 `json:"token" example:"42"`
+}
+
+// LeaseFence identifica una toma concreta de un lease. Los repositorios comprueban dentro de sus
+// transacciones de escritura que sigue vigente, para que un proceso que perdió el lease sin enterarse
+// (una pausa larga, una partición de red) no escriba después de que otro lo tome.
+type LeaseFence struct {
+	Name  string // Lease tomado
+	Owner string // Quien lo tomó
+	Token int64  // Token de la toma
+}
+
+// leaseFenceKey es la clave de contexto de la toma del lease con la que se ejecuta un trabajo.
+type leaseFenceKey struct{}
+
+// WithLeaseFence devuelve un contexto cuyas escrituras se hacen con la toma del lease indicada.
+func WithLeaseFence(ctx context.Context, fence LeaseFence) context.Context {
+	return context.WithValue(ctx, leaseFenceKey{}, fence)
+}
+
+// LeaseFenceFrom devuelve la toma del lease del contexto, si el trabajo se ejecuta con un lease tomado.
+func LeaseFenceFrom(ctx context.Context) (LeaseFence, bool) {
+	fence, ok := ctx.Value(leaseFenceKey{}).(LeaseFence)
+	return fence, ok
+}
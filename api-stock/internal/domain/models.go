@@ -1,39 +1,138 @@
 package domain
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRecommendationNotFound indica que no existe una recomendación con el ID indicado.
+var ErrRecommendationNotFound = errors.New("recomendación no encontrada")
+
+// DefaultSource es el nombre del proveedor original; las recomendaciones anteriores a los proveedores
+// múltiples pertenecen a él.
+const DefaultSource = "default"
 
 // StockRecommendation representa una recomendación de una acción con todos sus detalles.
 // Esta estructura puede ser utilizada tanto para respuestas de API como para almacenamiento.
 // @StockRecommendation
 type StockRecommendation struct {
+	// Identificador estable derivado de la identidad del proveedor (proveedor, ticker, firma, momento y acción)
+	ID string `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	// Proveedor de datos que envió la recomendación
+	Source string `json:"source" example:"default"`
 	// Símbolo del ticker de la acción
 	Ticker string `json:"ticker" example:"AAPL"`
 	// Precio objetivo inferior
-	TargetFrom string `json:"target_from" example:"150.00"`
+	TargetFrom Money `json:"target_from" swaggertype:"number" example:"150.00"`
 	// Precio objetivo superior
-	TargetTo string `json:"target_to" example:"175.00"`
+	TargetTo Money `json:"target_to" swaggertype:"number" example:"175.00"`
+	// Divisa de los precios objetivo (código ISO 4217)
+	Currency string `json:"currency" example:"USD"`
 	// Nombre de la empresa
 	Company string `json:"company" example:"Apple Inc."`
 	// Acción tomada (por ejemplo: aumento, reducción)
 	Action string `json:"action" example:"aumentado"`
+	// Tipo de acción canónico derivado del texto y de los cambios de calificación y precio objetivo
+	ActionType ActionType `json:"action_type" example:"target_raised"`
 	// Nombre de la firma de corretaje que emitió la recomendación
 	Brokerage string `json:"brokerage" example:"Goldman Sachs"`
+	// ID canónico de la firma resuelto a partir de sus alias (vacío si no se reconoció)
+	BrokerageID string `json:"brokerage_id,omitempty" example:"goldman_sachs"`
 	// Calificación anterior
 	RatingFrom string `json:"rating_from" example:"neutral"`
 	// Nueva calificación
 	RatingTo string `json:"rating_to" example:"comprar"`
+	// Calificación anterior normalizada a la taxonomía canónica
+	NormalizedRatingFrom Rating `json:"normalized_rating_from" example:"hold"`
+	// Nueva calificación normalizada a la taxonomía canónica
+	NormalizedRatingTo Rating `json:"normalized_rating_to" example:"buy"`
 	// Momento de la recomendación
 	Time time.Time `json:"time" example:"2023-01-15T00:00:00Z"`
 }
 
-// APIResponse representa la estructura de respuesta de la API externa.
-// Incluye una lista de recomendaciones y un token para la siguiente página.
+// RecommendationID calcula el ID estable de una recomendación: los primeros 32 caracteres hexadecimales
+// del SHA-256 de "TICKER|firma|segundos unix|acción" (ticker en mayúsculas, firma y acción en minúsculas,
+// el momento truncado al segundo). La migración 0007 replica este cálculo en SQL.
+// Para proveedores distintos de DefaultSource la clave va precedida de "proveedor|", de modo que el mismo
+// evento enviado por dos proveedores son dos filas y los IDs del proveedor original no cambian. La acción
+// forma parte de la identidad: dos recomendaciones que solo difieren en ella son filas distintas.
+func RecommendationID(source, ticker, brokerage string, t time.Time, action string) string {
+	parts := []string{
+		strings.ToUpper(strings.TrimSpace(ticker)),
+		strings.ToLower(strings.TrimSpace(brokerage)),
+		strconv.FormatInt(t.Unix(), 10),
+		strings.ToLower(strings.TrimSpace(action)),
+	}
+	if source != "" && source != DefaultSource {
+		parts = append([]string{source}, parts...)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])[:32]
+}
+
+// IdentityID calcula el ID estable de la recomendación a partir de su proveedor y sus campos de identidad.
+func (r StockRecommendation) IdentityID() string {
+	return RecommendationID(r.Source, r.Ticker, r.Brokerage, r.Time, r.Action)
+}
+
+// ValidSource indica si el texto sirve como nombre de proveedor: minúsculas, dígitos y guiones bajos (máximo 50).
+func ValidSource(name string) bool {
+	if name == "" || len(name) > 50 {
+		return false
+	}
+	return strings.Trim(name, "abcdefghijklmnopqrstuvwxyz0123456789_") == ""
+}
+
+// PriceCurrency devuelve la divisa de los precios objetivo.
+// Usa la divisa explícita si existe; si no, la detectada al parsear los precios, o DefaultCurrency.
+func (r StockRecommendation) PriceCurrency() string {
+	switch {
+	case r.Currency != "":
+		return r.Currency
+	case r.TargetTo.Currency != "":
+		return r.TargetTo.Currency
+	case r.TargetFrom.Currency != "":
+		return r.TargetFrom.Currency
+	default:
+		return DefaultCurrency
+	}
+}
+
+// RecommendationFilter agrupa los filtros opcionales para consultar recomendaciones.
+// Los campos vacíos no filtran.
+type RecommendationFilter struct {
+	// Símbolo del ticker
+	Ticker string
+	// Calificación canónica (rating_to normalizado)
+	Rating Rating
+	// Sector del valor según el registro maestro (sin distinguir mayúsculas)
+	Sector string
+	// Proveedor de datos
+	Source string
+	// Firma de corretaje: ID canónico o nombre tal como lo envió el proveedor (sin distinguir mayúsculas)
+	Brokerage string
+	// Solo recomendaciones desde este momento (inclusive)
+	From time.Time
+	// Solo recomendaciones anteriores a este momento (exclusive)
+	To time.Time
+}
+
+// APIResponse representa una página de la API externa ya traducida al modelo de dominio.
+// Incluye las recomendaciones válidas, los registros rechazados y un token para la siguiente página.
 // @APIResponse
 type APIResponse struct {
 	// Lista de recomendaciones
 	Items []StockRecommendation `json:"items"`
 	// Token para paginación
 	NextPage string `json:"next_page"`
+	// Registros que no superaron la decodificación o la validación, con su payload original
+	Rejected []QuarantinedRecord `json:"-"`
+	// Respuesta original para el archivo; nil si la página no viene de una petición al proveedor
+	Raw *ArchivedPage `json:"-"`
 }
 
 // SimilarStock representa una acción similar con una puntuación de similitud.
@@ -46,15 +145,21 @@ type SimilarStock struct {
 	Similarity float64 `json:"similarity" example:"0.85"`
 }
 
+// TickerScore es el score de un ticker según sus recomendaciones recientes, el mismo que ordena las mejores acciones.
+type TickerScore struct {
+	Ticker          string  // Símbolo del ticker
+	Score           float64 // Score promedio de sus recomendaciones recientes
+	Recommendations int     // Recomendaciones recientes que promedia
+}
+
 // ModelWeights contiene los pesos usados en el modelo de recomendación.
 // Estos pesos determinan la importancia relativa de cada atributo.
+// El peso de cada firma de corretaje es su reputation_weight en la tabla brokerages.
 type ModelWeights struct {
-	// Pesos asignados a diferentes tipos de acciones
-	ActionWeights map[string]float64
-	// Pesos asignados a diferentes calificaciones
-	RatingWeights map[string]float64
-	// Pesos asignados a diferentes firmas de corretaje
-	BrokerageWeights map[string]float64
+	// Pesos asignados a cada tipo de acción canónico
+	ActionWeights map[ActionType]float64
+	// Pesos asignados a cada calificación canónica
+	RatingWeights map[Rating]float64
 	// Peso asignado a la recencia de la recomendación
 	RecentnessWeight float64
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecommendationID(t *testing.T) {
+	at := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	base := RecommendationID(DefaultSource, "AAPL", "Goldman Sachs", at, "upgraded by")
+
+	tests := []struct {
+		name      string
+		source    string
+		ticker    string
+		brokerage string
+		time      time.Time
+		action    string
+		same      bool
+	}{
+		{"proveedor vacío es el proveedor por defecto", "", "AAPL", "Goldman Sachs", at, "upgraded by", true},
+		{"sin distinguir mayúsculas ni espacios", DefaultSource, " aapl ", "GOLDMAN SACHS ", at, " Upgraded By", true},
+		{"fracciones de segundo", DefaultSource, "AAPL", "Goldman Sachs", at.Add(500 * time.Millisecond), "upgraded by", true},
+		{"mismo instante en otra zona horaria", DefaultSource, "AAPL", "Goldman Sachs", at.In(time.FixedZone("CET", 3600)), "upgraded by", true},
+		{"otro proveedor", "benzinga", "AAPL", "Goldman Sachs", at, "upgraded by", false},
+		{"otro ticker", DefaultSource, "MSFT", "Goldman Sachs", at, "upgraded by", false},
+		{"otra firma", DefaultSource, "AAPL", "Morgan Stanley", at, "upgraded by", false},
+		{"otro segundo", DefaultSource, "AAPL", "Goldman Sachs", at.Add(time.Second), "upgraded by", false},
+		{"otra acción", DefaultSource, "AAPL", "Goldman Sachs", at, "target raised by", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RecommendationID(tt.source, tt.ticker, tt.brokerage, tt.time, tt.action)
+			if len(got) != 32 {
+				t.Fatalf("RecommendationID() = %q, want 32 hex characters", got)
+			}
+			if (got == base) != tt.same {
+				t.Errorf("RecommendationID(%q, %q, %q, %v, %q) = %q, base %q, want same = %v",
+					tt.source, tt.ticker, tt.brokerage, tt.time, tt.action, got, base, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultCurrency es la divisa que se asume cuando el proveedor no indica ninguna.
+const DefaultCurrency = "USD"
+
+// currencySymbols asocia los símbolos que envía el proveedor con su código ISO 4217.
+// Los símbolos de varios caracteres van primero para que tengan prioridad sobre "$".
+var currencySymbols = []struct {
+	Symbol string
+	Code   string
+}{
+	{"US$", "USD"},
+	{"C$", "CAD"},
+	{"A$", "AUD"},
+	{"R$", "BRL"},
+	{"$", "USD"},
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"¥", "JPY"},
+}
+
+// Money representa un importe monetario exacto, almacenado en centavos para evitar errores de punto flotante.
+// Valid es false cuando el proveedor no envió un valor (campo vacío, nulo o "N/A").
+type Money struct {
+	// Importe expresado en centavos
+	Cents int64
+	// Código ISO 4217 de la divisa (ejemplo: "USD")
+	Currency string
+	// Indica si el importe está presente
+	Valid bool
+}
+
+// NewMoney crea un importe válido a partir de un valor en unidades (ejemplo: 150.25) y una divisa.
+func NewMoney(amount float64, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Money{Cents: int64(math.Round(amount * 100)), Currency: currency, Valid: true}
+}
+
+// ParseMoney interpreta los precios tal como los envía el proveedor: "$150.00", "$1,234.50",
+// "€ 99,90", "150 USD", "(12.00)" o valores vacíos.
+// Los valores vacíos o marcadores como "N/A" devuelven un Money inválido sin error.
+func ParseMoney(raw string) (Money, error) {
+	s := strings.TrimSpace(raw)
+	switch strings.ToLower(s) {
+	case "", "-", "--", "n/a", "na", "null", "none":
+		return Money{}, nil
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = strings.TrimSpace(s[1:])
+	}
+
+	currency := ""
+	for _, cs := range currencySymbols {
+		if strings.HasPrefix(s, cs.Symbol) {
+			currency = cs.Code
+			s = strings.TrimSpace(strings.TrimPrefix(s, cs.Symbol))
+			break
+		}
+		if strings.HasSuffix(s, cs.Symbol) {
+			currency = cs.Code
+			s = strings.TrimSpace(strings.TrimSuffix(s, cs.Symbol))
+			break
+		}
+	}
+	if currency == "" {
+		s, currency = extractCurrencyCode(s)
+	}
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = strings.TrimSpace(s[1:])
+	}
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	cents, err := parseDecimalCents(normalizeSeparators(s))
+	if err != nil {
+		return Money{}, fmt.Errorf("importe inválido %q: %v", raw, err)
+	}
+	if negative {
+		cents = -cents
+	}
+	return Money{Cents: cents, Currency: currency, Valid: true}, nil
+}
+
+// extractCurrencyCode separa un código ISO de tres letras al inicio o al final del valor ("USD 150", "150 EUR").
+func extractCurrencyCode(s string) (string, string) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return s, ""
+	}
+	if isCurrencyCode(fields[0]) {
+		return fields[1], strings.ToUpper(fields[0])
+	}
+	if isCurrencyCode(fields[1]) {
+		return fields[0], strings.ToUpper(fields[1])
+	}
+	return s, ""
+}
+
+// isCurrencyCode indica si el texto tiene la forma de un código ISO 4217.
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeSeparators elimina los separadores de miles y deja el punto como separador decimal.
+// Si aparecen punto y coma, el último en aparecer es el separador decimal ("1.234,50" o "1,234.50").
+// Si solo hay comas, se consideran separadores de miles cuando todos los grupos tienen tres dígitos.
+func normalizeSeparators(s string) string {
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.ReplaceAll(s, "_", "")
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			s = strings.ReplaceAll(s, ".", "")
+			return strings.Replace(s, ",", ".", 1)
+		}
+		return strings.ReplaceAll(s, ",", "")
+	case lastComma >= 0:
+		groups := strings.Split(s, ",")
+		thousands := true
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				thousands = false
+				break
+			}
+		}
+		if thousands {
+			return strings.ReplaceAll(s, ",", "")
+		}
+		if len(groups) == 2 {
+			return groups[0] + "." + groups[1]
+		}
+	}
+	return s
+}
+
+// parseDecimalCents convierte un decimal sin signo ("1234.5") a centavos, redondeando a dos decimales.
+func parseDecimalCents(s string) (int64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("valor vacío")
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, fmt.Errorf("carácter no numérico %q", r)
+			}
+		}
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if units > math.MaxInt64/100-1 {
+		return 0, fmt.Errorf("importe fuera de rango")
+	}
+
+	fracPart += "000"
+	cents, _ := strconv.ParseInt(fracPart[:2], 10, 64)
+	if fracPart[2] >= '5' {
+		cents++ // Redondeo al centavo más cercano
+	}
+	return units*100 + cents, nil
+}
+
+// Float64 devuelve el importe en unidades (ejemplo: 150.25).
+func (m Money) Float64() float64 {
+	return float64(m.Cents) / 100
+}
+
+// String devuelve el importe con dos decimales, o una cadena vacía si no es válido.
+func (m Money) String() string {
+	if !m.Valid {
+		return ""
+	}
+	sign := ""
+	cents := m.Cents
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON serializa el importe como número JSON, o null si no es válido.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if !m.Valid {
+		return []byte("null"), nil
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON acepta tanto números como cadenas con formato del proveedor ("$1,234.50").
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		*m = Money{}
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implementa driver.Valuer para guardar el importe en columnas NUMERIC.
+func (m Money) Value() (driver.Value, error) {
+	if !m.Valid {
+		return nil, nil
+	}
+	return m.String(), nil
+}
+
+// Scan implementa sql.Scanner para leer columnas NUMERIC. La divisa se asigna aparte.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case float64:
+		*m = NewMoney(v, m.Currency)
+		return nil
+	case int64:
+		*m = Money{Cents: v * 100, Currency: m.Currency, Valid: true}
+		return nil
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("tipo no soportado para Money: %T", src)
+	}
+}
+
+// scanString interpreta el texto decimal que devuelve la base de datos.
+func (m *Money) scanString(s string) error {
+	negative := strings.HasPrefix(s, "-")
+	cents, err := parseDecimalCents(strings.TrimPrefix(s, "-"))
+	if err != nil {
+		return fmt.Errorf("error leyendo importe %q: %v", s, err)
+	}
+	if negative {
+		cents = -cents
+	}
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	*m = Money{Cents: cents, Currency: currency, Valid: true}
+	return nil
+}
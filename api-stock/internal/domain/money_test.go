@@ -0,0 +1,104 @@
+package domain
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Money
+		wantErr bool
+	}{
+		{raw: "$150.00", want: Money{Cents: 15000, Currency: "USD", Valid: true}},
+		{raw: "$1,234.50", want: Money{Cents: 123450, Currency: "USD", Valid: true}},
+		{raw: "€99,90", want: Money{Cents: 9990, Currency: "EUR", Valid: true}},
+		{raw: "€ 99,90", want: Money{Cents: 9990, Currency: "EUR", Valid: true}},
+		{raw: "1.234,50 €", want: Money{Cents: 123450, Currency: "EUR", Valid: true}},
+		{raw: "£1,234,567.89", want: Money{Cents: 123456789, Currency: "GBP", Valid: true}},
+		{raw: "C$12.5", want: Money{Cents: 1250, Currency: "CAD", Valid: true}},
+		{raw: "A$10", want: Money{Cents: 1000, Currency: "AUD", Valid: true}},
+		{raw: "R$ 25,50", want: Money{Cents: 2550, Currency: "BRL", Valid: true}},
+		{raw: "US$5.00", want: Money{Cents: 500, Currency: "USD", Valid: true}},
+		{raw: "¥1,500", want: Money{Cents: 150000, Currency: "JPY", Valid: true}},
+		{raw: "150 USD", want: Money{Cents: 15000, Currency: "USD", Valid: true}},
+		{raw: "eur 150", want: Money{Cents: 15000, Currency: "EUR", Valid: true}},
+		{raw: "150", want: Money{Cents: 15000, Currency: "USD", Valid: true}},
+		{raw: "(12.00)", want: Money{Cents: -1200, Currency: "USD", Valid: true}},
+		{raw: "($12.00)", want: Money{Cents: -1200, Currency: "USD", Valid: true}},
+		{raw: "-$3.25", want: Money{Cents: -325, Currency: "USD", Valid: true}},
+		{raw: "$-3.25", want: Money{Cents: -325, Currency: "USD", Valid: true}},
+		{raw: "$0.005", want: Money{Cents: 1, Currency: "USD", Valid: true}},
+		{raw: "$0.004", want: Money{Cents: 0, Currency: "USD", Valid: true}},
+		{raw: ".5", want: Money{Cents: 50, Currency: "USD", Valid: true}},
+		{raw: "", want: Money{}},
+		{raw: "  N/A ", want: Money{}},
+		{raw: "--", want: Money{}},
+		{raw: "abc", wantErr: true},
+		{raw: "$12a", wantErr: true},
+		{raw: "$1.2.3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseMoney(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseMoney(%q) = %+v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney(%q) error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSeparators(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1234.50", "1234.50"},
+		{"1,234.50", "1234.50"},
+		{"1.234,50", "1234.50"},
+		{"1,234,567.89", "1234567.89"},
+		{"1.234.567,89", "1234567.89"},
+		{"1,234", "1234"},
+		{"1,234,567", "1234567"},
+		{"99,90", "99.90"},
+		{"99,9", "99.9"},
+		{"1,23,45", "1,23,45"},
+		{"1 234,50", "1234.50"},
+		{"1_234.50", "1234.50"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := normalizeSeparators(tt.in); got != tt.want {
+				t.Errorf("normalizeSeparators(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{Cents: 123450, Valid: true}, "1234.50"},
+		{Money{Cents: -5, Valid: true}, "-0.05"},
+		{Money{Cents: 0, Valid: true}, "0.00"},
+		{Money{}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrQuarantineNotFound indica que no existe un registro en cuarentena con el ID indicado.
+var ErrQuarantineNotFound = errors.New("registro en cuarentena no encontrado")
+
+// ErrQuarantineResolved indica que el registro en cuarentena ya se reingresó o se descartó.
+var ErrQuarantineResolved = errors.New("el registro en cuarentena ya está resuelto")
+
+// QuarantineStatus es el estado de un registro en cuarentena.
+type QuarantineStatus string
+
+// Estados de un registro en cuarentena.
+const (
+	QuarantinePending    QuarantineStatus = "pending"    // Pendiente de revisión
+	QuarantineReingested QuarantineStatus = "reingested" // Corregido y guardado como recomendación
+	QuarantineDiscarded  QuarantineStatus = "discarded"  // Descartado por un administrador
+)
+
+// Valid indica si el estado es uno de los conocidos.
+func (s QuarantineStatus) Valid() bool {
+	switch s {
+	case QuarantinePending, QuarantineReingested, QuarantineDiscarded:
+		return true
+	default:
+		return false
+	}
+}
+
+// QuarantinedRecord es un registro del proveedor que no superó la decodificación o la validación.
+// Se guarda con el payload tal como llegó para poder inspeccionarlo, corregirlo y reingresarlo.
+// @QuarantinedRecord
+type QuarantinedRecord struct {
+	// Identificador del registro en cuarentena
+	ID string `json:"id" example:"0b7e4f2c-1d5a-4c3e-9f61-7a2d8c9e4b10"`
+	// Proveedor que envió el registro
+	Source string `json:"source" example:"default"`
+	// Huella del payload original (SHA-256); el mismo registro recibido varias veces ocupa una sola fila
+	Fingerprint string `json:"fingerprint" example:"3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b"`
+	// Última ejecución de sincronización que recibió el registro
+	SyncRunID string `json:"sync_run_id,omitempty" example:"5f0c1e9a-6d2b-4c51-9a55-1c7f3e0b2a44"`
+	// Motivo del rechazo (vacío si el payload corregido ya es válido)
+	Reason string `json:"reason" example:"recomendación inválida: falta el ticker"`
+	// Payload del proveedor (el original, o el corregido por un administrador)
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+	// Estado del registro
+	Status QuarantineStatus `json:"status" example:"pending"`
+	// Veces que se recibió el registro
+	Occurrences int64 `json:"occurrences" example:"3"`
+	// Primera vez que se recibió
+	FirstSeen time.Time `json:"first_seen"`
+	// Última vez que se recibió
+	LastSeen time.Time `json:"last_seen"`
+	// Fecha de la última corrección o cambio de estado
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QuarantineFilter agrupa los filtros opcionales para listar la cuarentena. Los campos vacíos no filtran.
+type QuarantineFilter struct {
+	// Estado del registro
+	Status QuarantineStatus
+	// Proveedor que envió el registro
+	Source string
+}
+
+// PayloadFingerprint calcula la huella de un payload: el SHA-256 hexadecimal de sus bytes.
+func PayloadFingerprint(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
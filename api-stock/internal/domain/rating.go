@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rating es la calificación canónica de una recomendación, independiente del texto libre del proveedor.
+type Rating string
+
+// Calificaciones canónicas, de la más negativa a la más positiva.
+const (
+	RatingUnknown    Rating = "unknown"
+	RatingStrongSell Rating = "strong_sell"
+	RatingSell       Rating = "sell"
+	RatingHold       Rating = "hold"
+	RatingBuy        Rating = "buy"
+	RatingStrongBuy  Rating = "strong_buy"
+)
+
+// ErrUnknownRating indica que un texto no corresponde a ninguna calificación conocida.
+var ErrUnknownRating = errors.New("calificación desconocida")
+
+// Ratings lista las calificaciones canónicas conocidas, en orden ascendente.
+var Ratings = []Rating{RatingStrongSell, RatingSell, RatingHold, RatingBuy, RatingStrongBuy}
+
+// Rank devuelve la posición de la calificación en la escala (-2 strong sell .. 2 strong buy).
+// El segundo valor es false si la calificación es desconocida.
+func (r Rating) Rank() (int, bool) {
+	switch r {
+	case RatingStrongSell:
+		return -2, true
+	case RatingSell:
+		return -1, true
+	case RatingHold:
+		return 0, true
+	case RatingBuy:
+		return 1, true
+	case RatingStrongBuy:
+		return 2, true
+	default:
+		return 0, false
+	}
+}
+
+// Valid indica si la calificación es una de las canónicas conocidas.
+func (r Rating) Valid() bool {
+	_, ok := r.Rank()
+	return ok
+}
+
+// OrUnknown devuelve la calificación, o RatingUnknown si no es válida.
+func (r Rating) OrUnknown() Rating {
+	if r.Valid() {
+		return r
+	}
+	return RatingUnknown
+}
+
+// DefaultRatingAliases es el diccionario por defecto (inglés y español) de textos del proveedor a calificaciones.
+// Las claves están normalizadas con normalizeTextKey.
+var DefaultRatingAliases = map[string]Rating{
+	// Compra fuerte
+	"strong buy":     RatingStrongBuy,
+	"conviction buy": RatingStrongBuy,
+	"top pick":       RatingStrongBuy,
+	"compra fuerte":  RatingStrongBuy,
+	"fuerte compra":  RatingStrongBuy,
+	// Compra
+	"buy":                 RatingBuy,
+	"outperform":          RatingBuy,
+	"market outperform":   RatingBuy,
+	"sector outperform":   RatingBuy,
+	"overweight":          RatingBuy,
+	"moderate buy":        RatingBuy,
+	"speculative buy":     RatingBuy,
+	"accumulate":          RatingBuy,
+	"add":                 RatingBuy,
+	"positive":            RatingBuy,
+	"comprar":             RatingBuy,
+	"compra":              RatingBuy,
+	"superar":             RatingBuy,
+	"superior al mercado": RatingBuy,
+	"sobreponderar":       RatingBuy,
+	"acumular":            RatingBuy,
+	// Mantener
+	"hold":           RatingHold,
+	"neutral":        RatingHold,
+	"market perform": RatingHold,
+	"sector perform": RatingHold,
+	"peer perform":   RatingHold,
+	"perform":        RatingHold,
+	"equal weight":   RatingHold,
+	"sector weight":  RatingHold,
+	"market weight":  RatingHold,
+	"in line":        RatingHold,
+	"inline":         RatingHold,
+	"fair value":     RatingHold,
+	"mantener":       RatingHold,
+	"neutro":         RatingHold,
+	"en linea":       RatingHold,
+	// Venta
+	"sell":                 RatingSell,
+	"underperform":         RatingSell,
+	"market underperform":  RatingSell,
+	"sector underperform":  RatingSell,
+	"underweight":          RatingSell,
+	"moderate sell":        RatingSell,
+	"reduce":               RatingSell,
+	"negative":             RatingSell,
+	"vender":               RatingSell,
+	"venta":                RatingSell,
+	"reducir":              RatingSell,
+	"infraponderar":        RatingSell,
+	"rendimiento inferior": RatingSell,
+	"inferior al mercado":  RatingSell,
+	// Venta fuerte
+	"strong sell":  RatingStrongSell,
+	"venta fuerte": RatingStrongSell,
+	"fuerte venta": RatingStrongSell,
+}
+
+// ratingKeySeparators agrupa guiones, guiones bajos y espacios repetidos.
+var ratingKeySeparators = regexp.MustCompile(`[\s\-_/]+`)
+
+// normalizeTextKey lleva un texto libre (calificación, acción) a la forma usada como clave de diccionario:
+// minúsculas, sin acentos comunes y con separadores unificados ("Strong-Buy" -> "strong buy").
+func normalizeTextKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u").Replace(s)
+	return strings.TrimSpace(ratingKeySeparators.ReplaceAllString(s, " "))
+}
+
+// RatingNormalizer traduce el texto libre del proveedor a una calificación canónica.
+// Primero busca una coincidencia exacta; si no la hay, busca el alias más largo que aparezca
+// como palabra completa, de modo que el resultado es determinista ("underperform" nunca
+// coincide con "outperform").
+type RatingNormalizer struct {
+	aliases map[string]Rating
+	ordered []string // claves ordenadas por longitud descendente y luego alfabéticamente
+}
+
+// NewRatingNormalizer crea un normalizador con el diccionario por defecto más los alias adicionales.
+// Los alias adicionales (por ejemplo, cargados desde configuración) tienen prioridad sobre los por defecto.
+func NewRatingNormalizer(extra map[string]string) (*RatingNormalizer, error) {
+	aliases := make(map[string]Rating, len(DefaultRatingAliases)+len(extra))
+	for alias, rating := range DefaultRatingAliases {
+		aliases[normalizeTextKey(alias)] = rating
+	}
+	for alias, code := range extra {
+		rating := Rating(strings.ToLower(strings.TrimSpace(code)))
+		if !rating.Valid() {
+			return nil, fmt.Errorf("calificación %q inválida para el alias %q", code, alias)
+		}
+		aliases[normalizeTextKey(alias)] = rating
+	}
+
+	ordered := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		ordered = append(ordered, alias)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if len(ordered[i]) != len(ordered[j]) {
+			return len(ordered[i]) > len(ordered[j])
+		}
+		return ordered[i] < ordered[j]
+	})
+
+	return &RatingNormalizer{aliases: aliases, ordered: ordered}, nil
+}
+
+// Normalize devuelve la calificación canónica para un texto del proveedor, o RatingUnknown.
+func (n *RatingNormalizer) Normalize(raw string) Rating {
+	key := normalizeTextKey(raw)
+	if key == "" {
+		return RatingUnknown
+	}
+	if rating, ok := n.aliases[key]; ok {
+		return rating
+	}
+	padded := " " + key + " "
+	for _, alias := range n.ordered {
+		if strings.Contains(padded, " "+alias+" ") {
+			return n.aliases[alias]
+		}
+	}
+	return RatingUnknown
+}
@@ -0,0 +1,89 @@
+package domain
+
+import "testing"
+
+func TestRatingNormalizerNormalize(t *testing.T) {
+	normalizer, err := NewRatingNormalizer(map[string]string{
+		"Sector Perform": "buy",  // Sustituye un alias por defecto
+		"Top Rated":      "BUY ", // Alias nuevo; el código se normaliza
+	})
+	if err != nil {
+		t.Fatalf("NewRatingNormalizer: %v", err)
+	}
+
+	tests := []struct {
+		raw  string
+		want Rating
+	}{
+		{"Buy", RatingBuy},
+		{"  STRONG-BUY ", RatingStrongBuy},
+		{"strong_sell", RatingStrongSell},
+		{"Market Outperform", RatingBuy},
+		{"Underperform", RatingSell},
+		{"Outperform", RatingBuy},
+		{"Equal-Weight", RatingHold},
+		{"In-Line", RatingHold},
+		{"Compra fuerte", RatingStrongBuy},
+		{"En línea", RatingHold},
+		{"Rendimiento inferior", RatingSell},
+		// Coincidencia por palabra completa: gana el alias más largo
+		{"Sector Underperform (Reiterated)", RatingSell},
+		{"Upgrade to Strong Buy", RatingStrongBuy},
+		{"Neutral rating", RatingHold},
+		// Las subcadenas que no son palabras completas no coinciden
+		{"Buyback", RatingUnknown},
+		{"Outperformer", RatingUnknown},
+		// Alias adicionales
+		{"Sector Perform", RatingBuy},
+		{"top rated", RatingBuy},
+		{"", RatingUnknown},
+		{"   ", RatingUnknown},
+		{"N/A", RatingUnknown},
+		{"Not Rated", RatingUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := normalizer.Normalize(tt.raw); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRatingNormalizerRejectsInvalidRating(t *testing.T) {
+	tests := []map[string]string{
+		{"Top Rated": "great"},
+		{"Top Rated": "unknown"},
+		{"Top Rated": ""},
+	}
+
+	for _, extra := range tests {
+		if _, err := NewRatingNormalizer(extra); err == nil {
+			t.Errorf("NewRatingNormalizer(%v) did not fail", extra)
+		}
+	}
+}
+
+func TestRatingRank(t *testing.T) {
+	tests := []struct {
+		rating   Rating
+		wantRank int
+		wantOK   bool
+	}{
+		{RatingStrongSell, -2, true},
+		{RatingSell, -1, true},
+		{RatingHold, 0, true},
+		{RatingBuy, 1, true},
+		{RatingStrongBuy, 2, true},
+		{RatingUnknown, 0, false},
+		{Rating("BUY"), 0, false},
+	}
+
+	for _, tt := range tests {
+		rank, ok := tt.rating.Rank()
+		if rank != tt.wantRank || ok != tt.wantOK {
+			t.Errorf("%q.Rank() = %d, %v, want %d, %v", tt.rating, rank, ok, tt.wantRank, tt.wantOK)
+		}
+	}
+}
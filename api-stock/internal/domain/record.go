@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecommendationFields son los campos canónicos que se leen de un registro de origen (respuesta de un
+// proveedor o fila de un archivo). Sin mapeo explícito, cada uno se busca con su mismo nombre.
+var RecommendationFields = []string{
+	"ticker", "target_from", "target_to", "currency", "company", "action", "brokerage", "rating_from", "rating_to", "time",
+}
+
+// FieldMapping asocia cada campo canónico con su nombre (o ruta "a.b" en objetos anidados) en el origen.
+type FieldMapping map[string]string
+
+// Validate comprueba que el mapeo solo usa campos canónicos conocidos.
+func (m FieldMapping) Validate() error {
+	for field := range m {
+		known := false
+		for _, canonical := range RecommendationFields {
+			if field == canonical {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("campo desconocido %q en el mapeo (válidos: %s)", field, strings.Join(RecommendationFields, ", "))
+		}
+	}
+	return nil
+}
+
+// ParseFieldMapping lee un mapeo con el formato "campo=origen,campo=origen" (ejemplo: "ticker=symbol,time=date").
+func ParseFieldMapping(raw string) (FieldMapping, error) {
+	mapping := make(FieldMapping)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, source, ok := strings.Cut(pair, "=")
+		field, source = strings.TrimSpace(field), strings.TrimSpace(source)
+		if !ok || field == "" || source == "" {
+			return nil, fmt.Errorf("mapeo inválido %q: se esperaba campo=origen", pair)
+		}
+		mapping[field] = source
+	}
+	return mapping, mapping.Validate()
+}
+
+// DecodeRecommendation construye una recomendación a partir de un registro genérico usando el mapeo de campos.
+// Los campos se reescriben con sus nombres canónicos y se decodifican con las mismas reglas que la respuesta
+// del proveedor original (precios con formato "$1,234.50", fecha RFC 3339). Si timeLayout no está vacío,
+// la fecha se interpreta con ese formato Go. Los valores vacíos o nulos se ignoran.
+func DecodeRecommendation(record map[string]interface{}, mapping FieldMapping, timeLayout string) (StockRecommendation, error) {
+	var rec StockRecommendation
+
+	mapped := make(map[string]interface{}, len(RecommendationFields))
+	for _, field := range RecommendationFields {
+		path := field
+		if custom, ok := mapping[field]; ok {
+			path = custom
+		}
+		value, ok := LookupPath(record, path)
+		if !ok || value == nil || value == "" {
+			continue
+		}
+		mapped[field] = value
+	}
+	if raw, ok := mapped["time"].(string); ok && timeLayout != "" {
+		t, err := time.Parse(timeLayout, raw)
+		if err != nil {
+			return rec, fmt.Errorf("fecha inválida %q: %v", raw, err)
+		}
+		mapped["time"] = t.Format(time.RFC3339Nano)
+	}
+
+	data, err := json.Marshal(mapped)
+	if err != nil {
+		return rec, err
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, err
+	}
+
+	// Fija la divisa detectada al parsear los precios objetivo
+	rec.Currency = rec.PriceCurrency()
+	return rec, nil
+}
+
+// LookupPath busca un valor en un registro: primero por el nombre exacto (columnas con puntos)
+// y después como ruta con puntos ("data.items") dentro de objetos JSON anidados.
+func LookupPath(record map[string]interface{}, path string) (interface{}, bool) {
+	if value, ok := record[path]; ok {
+		return value, true
+	}
+	var current interface{} = record
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// RecommendationRevision es una versión anterior de una recomendación, guardada al ser reemplazada
+// por una versión distinta con el mismo ID (el proveedor corrigió el registro o cambió su normalización).
+// @RecommendationRevision
+type RecommendationRevision struct {
+	// Identificador de la revisión
+	ID string `json:"id" example:"0b7a4c1e-2f5d-4e8a-9c3b-6d1f0e2a7b94"`
+	// Ejecución de sincronización que reemplazó esta versión (vacío si fue una importación o un reingreso)
+	SyncRunID string `json:"sync_run_id,omitempty" example:"5f0c1e9a-6d2b-4c51-9a55-1c7f3e0b2a44"`
+	// Indica si el reemplazo cambió datos del proveedor; false si solo cambió la normalización
+	Correction bool `json:"correction" example:"true"`
+	// Campos que difieren en la versión siguiente
+	ChangedFields []string `json:"changed_fields" example:"target_to,rating_to"`
+	// Momento en que esta versión fue reemplazada
+	RevisedAt time.Time `json:"revised_at"`
+	// Datos de la recomendación en esta versión
+	Recommendation StockRecommendation `json:"recommendation"`
+}
+
+// ChangedFields devuelve los campos actualizables (nombres JSON) que difieren entre dos versiones de
+// una recomendación. Los campos de identidad (ID, proveedor, ticker y momento) no se comparan.
+func ChangedFields(previous, next StockRecommendation) []string {
+	candidates := []struct {
+		name    string
+		changed bool
+	}{
+		{"target_from", previous.TargetFrom.Valid != next.TargetFrom.Valid || previous.TargetFrom.Cents != next.TargetFrom.Cents},
+		{"target_to", previous.TargetTo.Valid != next.TargetTo.Valid || previous.TargetTo.Cents != next.TargetTo.Cents},
+		{"currency", previous.PriceCurrency() != next.PriceCurrency()},
+		{"company", previous.Company != next.Company},
+		{"action", previous.Action != next.Action},
+		{"action_type", previous.ActionType != next.ActionType},
+		{"brokerage", previous.Brokerage != next.Brokerage},
+		{"brokerage_id", previous.BrokerageID != next.BrokerageID},
+		{"rating_from", previous.RatingFrom != next.RatingFrom},
+		{"rating_to", previous.RatingTo != next.RatingTo},
+		{"normalized_rating_from", previous.NormalizedRatingFrom != next.NormalizedRatingFrom},
+		{"normalized_rating_to", previous.NormalizedRatingTo != next.NormalizedRatingTo},
+	}
+	changed := []string{}
+	for _, c := range candidates {
+		if c.changed {
+			changed = append(changed, c.name)
+		}
+	}
+	return changed
+}
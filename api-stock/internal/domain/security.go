@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSecurity indica que los datos de un valor (fila del CSV) no son válidos.
+var ErrInvalidSecurity = errors.New("valor inválido")
+
+// Security es el registro maestro de un valor cotizado: ticker, empresa y clasificación.
+// @Security
+type Security struct {
+	// Símbolo del ticker
+	Ticker string `json:"ticker" example:"AAPL"`
+	// Nombre de la empresa
+	Company string `json:"company" example:"Apple Inc."`
+	// Bolsa donde cotiza
+	Exchange string `json:"exchange" example:"NASDAQ"`
+	// Sector económico
+	Sector string `json:"sector" example:"Technology"`
+	// Industria dentro del sector
+	Industry string `json:"industry" example:"Consumer Electronics"`
+	// Indica si el valor sigue cotizando
+	Active bool `json:"active" example:"true"`
+	// Fecha de última modificación
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SecurityFilter agrupa los filtros opcionales para listar valores. Los campos vacíos no filtran.
+type SecurityFilter struct {
+	// Sector económico (sin distinguir mayúsculas)
+	Sector string
+	// Incluye también los valores inactivos
+	IncludeInactive bool
+	// Solo valores con al menos una recomendación
+	WithRecommendations bool
+}
+
+// securityCSVColumns asocia los encabezados aceptados en el CSV de importación con cada campo.
+var securityCSVColumns = map[string]string{
+	"ticker":       "ticker",
+	"symbol":       "ticker",
+	"company":      "company",
+	"company_name": "company",
+	"name":         "company",
+	"exchange":     "exchange",
+	"sector":       "sector",
+	"industry":     "industry",
+	"active":       "active",
+}
+
+// ParseSecuritiesCSV lee un CSV de valores con encabezado. La columna ticker (o symbol) es obligatoria;
+// company, exchange, sector, industry y active son opcionales y active vale true si se omite.
+// Si un ticker se repite, prevalece la última fila.
+func ParseSecuritiesCSV(r io.Reader) ([]Security, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // Las columnas opcionales al final pueden omitirse
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: el CSV está vacío", ErrInvalidSecurity)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSecurity, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := securityCSVColumns[key]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["ticker"]; !ok {
+		return nil, fmt.Errorf("%w: falta la columna ticker", ErrInvalidSecurity)
+	}
+
+	var securities []Security
+	index := make(map[string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSecurity, err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		sec := Security{
+			Ticker:   strings.ToUpper(field("ticker")),
+			Company:  field("company"),
+			Exchange: strings.ToUpper(field("exchange")),
+			Sector:   field("sector"),
+			Industry: field("industry"),
+			Active:   true,
+		}
+		if sec.Ticker == "" {
+			return nil, fmt.Errorf("%w: línea %d sin ticker", ErrInvalidSecurity, line)
+		}
+		if len(sec.Ticker) > 20 {
+			return nil, fmt.Errorf("%w: línea %d con ticker demasiado largo", ErrInvalidSecurity, line)
+		}
+		if raw := field("active"); raw != "" {
+			active, ok := parseBoolFlag(raw)
+			if !ok {
+				return nil, fmt.Errorf("%w: línea %d con active inválido %q", ErrInvalidSecurity, line, raw)
+			}
+			sec.Active = active
+		}
+
+		if i, ok := index[sec.Ticker]; ok {
+			securities[i] = sec
+			continue
+		}
+		index[sec.Ticker] = len(securities)
+		securities = append(securities, sec)
+	}
+	return securities, nil
+}
+
+// parseBoolFlag interpreta indicadores booleanos habituales en inglés y español.
+func parseBoolFlag(raw string) (bool, bool) {
+	switch strings.ToLower(raw) {
+	case "1", "true", "t", "yes", "y", "si", "sí", "s":
+		return true, true
+	case "0", "false", "f", "no", "n":
+		return false, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSyncValidation indica que una sincronización completa no superó la validación y no se aplicó;
+// el dataset anterior sigue intacto.
+var ErrSyncValidation = errors.New("la sincronización no superó la validación")
+
+// ErrProviderAuth indica que el proveedor rechazó las credenciales (401/403); reintentar no sirve.
+var ErrProviderAuth = errors.New("el proveedor rechazó las credenciales")
+
+// ErrQuotaExceeded indica que se agotó la cuota diaria de peticiones al proveedor; la sincronización se detiene
+// sin hacer más peticiones hasta que la cuota se renueve.
+var ErrQuotaExceeded = errors.New("se agotó la cuota diaria de peticiones al proveedor")
+
+// QuotaResetAt devuelve cuándo se renueva la cuota diaria en curso en t: la medianoche UTC siguiente.
+func QuotaResetAt(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// ErrPageOrder indica que el proveedor no entregó las páginas de la más reciente a la más antigua, como
+// requiere la sincronización incremental para saber cuándo dejar de leer.
+var ErrPageOrder = errors.New("el proveedor no entrega las páginas de la más reciente a la más antigua")
+
+// PageStreamOptions controla la lectura anticipada de las páginas de un proveedor.
+type PageStreamOptions struct {
+	Prefetch int // Páginas recibidas que pueden esperar a ser procesadas (0: cada página se pide al leerla; 1: la siguiente se pide mientras se procesa una)
+}
+
+// FullSyncCheckpoint devuelve el nombre del checkpoint de la sincronización completa de un proveedor.
+func FullSyncCheckpoint(source string) string {
+	return "full:" + source
+}
+
+// SyncCheckpoint es el progreso persistido de una sincronización paginada.
+// Mientras existe, la sincronización está en curso (o fue interrumpida) y puede reanudarse desde NextPage;
+// los registros de las páginas ya procesadas están en staging.
+type SyncCheckpoint struct {
+	// Nombre de la sincronización (ejemplo: "full:default")
+	Name string `json:"name"`
+	// Token de la siguiente página a pedir; vacío con Pages > 0 significa que la carga terminó
+	NextPage string `json:"next_page"`
+	// Páginas procesadas
+	Pages int `json:"pages"`
+	// Registros recibidos en las páginas procesadas
+	Records int `json:"records"`
+	// Inicio de la sincronización
+	StartedAt time.Time `json:"started_at"`
+	// Último checkpoint guardado
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoadFinished indica si ya se procesaron todas las páginas.
+func (c SyncCheckpoint) LoadFinished() bool {
+	return c.Pages > 0 && c.NextPage == ""
+}
+
+// ErrSyncRunNotFound indica que no existe una ejecución de sincronización con el ID indicado.
+var ErrSyncRunNotFound = errors.New("ejecución de sincronización no encontrada")
+
+// SyncType es el tipo de una ejecución de sincronización.
+type SyncType string
+
+// Tipos de sincronización.
+const (
+	SyncTypeFull        SyncType = "full"
+	SyncTypeIncremental SyncType = "incremental"
+	SyncTypeReprocess   SyncType = "reprocess" // Reconstrucción desde las páginas archivadas
+)
+
+// SyncStatus es el estado de una ejecución de sincronización.
+type SyncStatus string
+
+// Estados de una ejecución de sincronización.
+const (
+	SyncStatusRunning   SyncStatus = "running"
+	SyncStatusSucceeded SyncStatus = "succeeded"
+	SyncStatusFailed    SyncStatus = "failed"
+	SyncStatusQuota     SyncStatus = "quota_exceeded" // Detenida al agotarse la cuota diaria; la completa conserva su checkpoint
+)
+
+// WriteStats cuenta el efecto de escribir recomendaciones: filas nuevas, filas cambiadas,
+// registros recibidos que no cambiaron nada (repetidos o idénticos) y filas eliminadas.
+// Corrected es la parte de Updated en la que cambió algún dato del proveedor y no solo la normalización.
+type WriteStats struct {
+	Inserted  int `json:"inserted" example:"120"`
+	Updated   int `json:"updated" example:"8"`
+	Corrected int `json:"corrected" example:"2"`
+	Skipped   int `json:"skipped" example:"872"`
+	Deleted   int `json:"deleted" example:"3"`
+}
+
+// Add acumula otras estadísticas en estas.
+func (s *WriteStats) Add(other WriteStats) {
+	s.Inserted += other.Inserted
+	s.Updated += other.Updated
+	s.Corrected += other.Corrected
+	s.Skipped += other.Skipped
+	s.Deleted += other.Deleted
+}
+
+// SyncRun es el registro de una ejecución de sincronización con la API externa.
+// @SyncRun
+type SyncRun struct {
+	// Identificador de la ejecución
+	ID string `json:"id" example:"5f0c1e9a-6d2b-4c51-9a55-1c7f3e0b2a44"`
+	// Tipo de sincronización
+	Type SyncType `json:"type" example:"incremental"`
+	// Proveedor sincronizado
+	Source string `json:"source" example:"default"`
+	// Estado de la ejecución
+	Status SyncStatus `json:"status" example:"succeeded"`
+	// Inicio de la ejecución
+	StartedAt time.Time `json:"started_at"`
+	// Fin de la ejecución (vacío mientras está en curso)
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Páginas pedidas al proveedor
+	Pages int `json:"pages" example:"10"`
+	// Efecto sobre las recomendaciones
+	WriteStats
+	// Registros rechazados por la validación y enviados a cuarentena
+	Quarantined int `json:"quarantined" example:"2"`
+	// Error que hizo fallar la ejecución
+	Error string `json:"error,omitempty"`
+	// Marca de agua usada: fecha de la recomendación más reciente antes de una sincronización incremental,
+	// o inicio de las páginas archivadas usadas en un reprocesamiento
+	Watermark *time.Time `json:"watermark,omitempty"`
+}
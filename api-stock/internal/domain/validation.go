@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidRecommendation indica que una recomendación no supera las reglas de validación de la ingesta.
+var ErrInvalidRecommendation = errors.New("recomendación inválida")
+
+// Límites de las columnas de recommendations que aplica ValidateRecommendation.
+const (
+	MaxTickerLength    = 10       // ticker VARCHAR(10)
+	MaxCompanyLength   = 100      // company VARCHAR(100)
+	MaxActionLength    = 50       // action VARCHAR(50)
+	MaxBrokerageLength = 100      // brokerage VARCHAR(100)
+	MaxRatingLength    = 50       // rating_from / rating_to VARCHAR(50)
+	MaxTargetCents     = 1e14 - 1 // target_from / target_to NUMERIC(14,2)
+)
+
+// minRecommendationTime es la fecha más antigua aceptada; antes suele indicar un campo mal mapeado.
+var minRecommendationTime = time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC)
+
+// ValidateRecommendation aplica las reglas de la ingesta a una recomendación ya decodificada:
+//   - ticker obligatorio, sin espacios y de hasta 10 caracteres;
+//   - company, action, brokerage, rating_from y rating_to dentro del tamaño de su columna;
+//   - precios objetivo no negativos y dentro de NUMERIC(14,2);
+//   - divisa con forma de código ISO 4217;
+//   - fecha informada y posterior a 1970.
+//
+// Devuelve todas las reglas incumplidas en un único error que envuelve ErrInvalidRecommendation,
+// de modo que un registro malo se rechaza solo y no hace fallar el lote completo en la base de datos.
+func ValidateRecommendation(rec StockRecommendation) error {
+	var problems []string
+	check := func(failed bool, format string, args ...interface{}) {
+		if failed {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	ticker := strings.TrimSpace(rec.Ticker)
+	check(ticker == "", "falta el ticker")
+	check(utf8.RuneCountInString(ticker) > MaxTickerLength, "ticker de más de %d caracteres", MaxTickerLength)
+	check(strings.IndexFunc(ticker, unicode.IsSpace) >= 0, "ticker con espacios")
+	check(utf8.RuneCountInString(rec.Company) > MaxCompanyLength, "company de más de %d caracteres", MaxCompanyLength)
+	check(utf8.RuneCountInString(rec.Action) > MaxActionLength, "action de más de %d caracteres", MaxActionLength)
+	check(utf8.RuneCountInString(rec.Brokerage) > MaxBrokerageLength, "brokerage de más de %d caracteres", MaxBrokerageLength)
+	check(utf8.RuneCountInString(rec.RatingFrom) > MaxRatingLength, "rating_from de más de %d caracteres", MaxRatingLength)
+	check(utf8.RuneCountInString(rec.RatingTo) > MaxRatingLength, "rating_to de más de %d caracteres", MaxRatingLength)
+	check(rec.TargetFrom.Valid && rec.TargetFrom.Cents < 0, "target_from negativo")
+	check(rec.TargetFrom.Cents > MaxTargetCents, "target_from fuera de rango")
+	check(rec.TargetTo.Valid && rec.TargetTo.Cents < 0, "target_to negativo")
+	check(rec.TargetTo.Cents > MaxTargetCents, "target_to fuera de rango")
+	check(!isCurrencyCode(rec.PriceCurrency()), "divisa inválida %q", rec.PriceCurrency())
+	check(rec.Time.IsZero(), "falta la fecha")
+	check(!rec.Time.IsZero() && rec.Time.Before(minRecommendationTime), "fecha anterior a 1970")
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidRecommendation, strings.Join(problems, "; "))
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrJobRunning indica que el trabajo ya está en curso o encolado.
+var ErrJobRunning = errors.New("el trabajo ya está en curso")
+
+// ErrUnknownJob indica que no hay un trabajo programado con el nombre indicado.
+var ErrUnknownJob = errors.New("trabajo desconocido")
+
+// JobTrigger indica qué originó la ejecución de un trabajo del worker.
+type JobTrigger string
+
+// Orígenes de la ejecución de un trabajo del worker.
+const (
+	JobTriggerStartup  JobTrigger = "startup"  // Al arrancar el worker (run_on_start)
+	JobTriggerSchedule JobTrigger = "schedule" // Expresión cron del trabajo
+	JobTriggerManual   JobTrigger = "manual"   // Pedido desde el endpoint de administración
+)
+
+// JobRunStatus es el estado de una ejecución de un trabajo del worker.
+type JobRunStatus string
+
+// Estados de una ejecución de un trabajo del worker.
+const (
+	JobRunRunning   JobRunStatus = "running"
+	JobRunSucceeded JobRunStatus = "succeeded"
+	JobRunFailed    JobRunStatus = "failed"
+	JobRunSkipped   JobRunStatus = "skipped"        // Omitida por la política de solapamiento o porque otro proceso tenía el lease
+	JobRunQuota     JobRunStatus = "quota_exceeded" // Detenida porque un proveedor agotó su cuota diaria
+)
+
+// OverlapPolicy decide qué hacer cuando llega la hora de un trabajo que sigue en curso.
+type OverlapPolicy string
+
+// Políticas de solapamiento.
+const (
+	OverlapSkip  OverlapPolicy = "skip"  // Se omite la ejecución (por defecto)
+	OverlapQueue OverlapPolicy = "queue" // Se ejecuta una vez al terminar la que está en curso
+	OverlapAllow OverlapPolicy = "allow" // Se ejecuta en paralelo
+)
+
+// Valid indica si la política es una de las conocidas.
+func (p OverlapPolicy) Valid() bool {
+	return p == OverlapSkip || p == OverlapQueue || p == OverlapAllow
+}
+
+// JobRun es una ejecución de un trabajo del worker.
+// @JobRun
+type JobRun struct {
+	// Identificador de la ejecución
+	ID string `json:"id" example:"5f0c1e9a-6d2b-4c51-9a55-1c7f3e0b2a44"`
+	// Nombre del trabajo
+	Job string `json:"job" example:"incremental"`
+	// Tipo de trabajo
+	Kind string `json:"kind" example:"incremental"`
+	// Origen de la ejecución
+	Trigger JobTrigger `json:"trigger" example:"schedule"`
+	// Estado de la ejecución
+	Status JobRunStatus `json:"status" example:"succeeded"`
+	// Inicio de la ejecución
+	StartedAt time.Time `json:"started_at"`
+	// Fin de la ejecución (vacío mientras está en curso)
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Error que hizo fallar la ejecución, o motivo por el que se omitió
+	Error string `json:"error,omitempty"`
+	// Sincronizaciones por proveedor de la ejecución, con sus contadores (solo en el estado del worker)
+	SyncRuns []SyncRun `json:"sync_runs,omitempty"`
+}
+
+// ScheduledJob es el estado de un trabajo programado del worker.
+// @ScheduledJob
+type ScheduledJob struct {
+	// Nombre del trabajo
+	Name string `json:"name" example:"incremental"`
+	// Tipo de trabajo
+	Kind string `json:"kind" example:"incremental"`
+	// Expresión cron (vacía: solo manual o al arrancar)
+	Schedule string `json:"schedule,omitempty" example:"*/15 9-16 * * MON-FRI"`
+	// Tiempo máximo de una ejecución (vacío: sin límite)
+	Timeout string `json:"timeout,omitempty" example:"10m0s"`
+	// Política de solapamiento
+	Overlap OverlapPolicy `json:"overlap" example:"skip"`
+	// Próxima ejecución programada (vacía si está pausado o no tiene expresión cron)
+	NextRun *time.Time `json:"next_run,omitempty"`
+	// Indica si hay una ejecución encolada a la espera de que termine la que está en curso
+	Pending bool `json:"pending" example:"false"`
+	// Ejecuciones en curso, con el progreso de sus sincronizaciones
+	Running []JobRun `json:"running"`
+	// Última ejecución terminada
+	Last *JobRun `json:"last,omitempty"`
+}
+
+// WorkerStatus es el estado del worker: pausa de la programación y estado de cada trabajo.
+// @WorkerStatus
+type WorkerStatus struct {
+	// Indica si la programación está pausada (los trabajos manuales siguen permitidos)
+	Paused bool `json:"paused" example:"false"`
+	// Trabajos programados
+	Jobs []ScheduledJob `json:"jobs"`
+}
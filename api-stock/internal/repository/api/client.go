@@ -1,87 +1,226 @@
 package api
 
 import (
+	"api-stock/internal/config"
 	"api-stock/internal/domain"
+	"api-stock/pkg/metrics"
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 )
 
+// RetryConfig agrupa los parámetros de reintento de las peticiones a la API externa.
+type RetryConfig struct {
+	MaxRetries   int           // Reintentos máximos por petición (0 desactiva los reintentos)
+	InitialDelay time.Duration // Espera base antes del primer reintento; se duplica en cada intento
+	MaxDelay     time.Duration // Tope de la espera entre reintentos; un Retry-After mayor hace fallar la petición
+}
+
+// DefaultRetryConfig devuelve la configuración de reintentos por defecto.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{MaxRetries: 3, InitialDelay: 1 * time.Second, MaxDelay: 30 * time.Second}
+}
+
 // recommendationClient implementa la interfaz domain.ExternalAPI.
-// Se encarga de realizar llamadas a una API externa para obtener recomendaciones bursátiles.
+// Se encarga de realizar llamadas a la API de un proveedor para obtener recomendaciones bursátiles,
+// según la URL, autenticación, paginación y nombres de campos de su configuración.
 type recommendationClient struct {
-	client  *http.Client
-	apiKey  string
-	baseURL string
+	client   *http.Client
+	provider config.ProviderConfig
+	retry    RetryConfig
+	limiter  *requestLimiter // Ritmo y cuota de peticiones del proveedor
 }
 
-// NewRecommendationClient crea una nueva instancia del cliente de recomendaciones externas.
-// Recibe como parámetros la API key y la URL base de la API.
-func NewRecommendationClient(apiKey, baseURL string) domain.ExternalAPI {
-	return &recommendationClient{
-		client:  &http.Client{Timeout: 30 * time.Second},
-		apiKey:  apiKey,
-		baseURL: baseURL,
+// NewRecommendationClient crea el cliente de un proveedor de recomendaciones a partir de su configuración
+// y de la configuración de reintentos. Completa los valores por defecto y valida la configuración.
+// transport reemplaza el transporte HTTP (por ejemplo, para grabar o reproducir intercambios); nil usa el de por defecto.
+// quotas lleva la cuota diaria del proveedor, compartida entre procesos; nil la cuenta en memoria.
+func NewRecommendationClient(provider config.ProviderConfig, retry RetryConfig, transport http.RoundTripper, quotas domain.QuotaRepository) (domain.ExternalAPI, error) {
+	provider, err := providerWithDefaults(provider)
+	if err != nil {
+		return nil, err
 	}
+	if retry.InitialDelay <= 0 {
+		retry.InitialDelay = DefaultRetryConfig().InitialDelay
+	}
+	if retry.MaxDelay < retry.InitialDelay {
+		retry.MaxDelay = retry.InitialDelay
+	}
+	return &recommendationClient{
+		client:   &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		provider: provider,
+		retry:    retry,
+		limiter:  newRequestLimiter(provider.Name, provider.RateLimit, quotas),
+	}, nil
+}
+
+// Name devuelve el nombre del proveedor.
+func (rc *recommendationClient) Name() string {
+	return rc.provider.Name
 }
 
+// retryableError es un fallo transitorio (red, 5xx, 429) que merece reintentarse.
+type retryableError struct {
+	reason     string        // Motivo para métricas: código HTTP o "network"
+	retryAfter time.Duration // Espera pedida por el proveedor con Retry-After (0 si no la indicó)
+	err        error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+func (e *retryableError) Unwrap() error { return e.err }
+
 // GetRecommendations obtiene un conjunto de recomendaciones desde la API externa.
 // Puede aceptar un token de paginación `nextPage` para continuar desde la última página consultada.
-func (rc *recommendationClient) GetRecommendations(ctx context.Context, nextPage string) ([]domain.StockRecommendation, string, error) {
-	url := rc.baseURL
-	if nextPage != "" {
-		url = fmt.Sprintf("%s?next_page=%s", url, nextPage)
+// Los errores de red y las respuestas 5xx se reintentan con backoff exponencial y jitter; las 429
+// esperan lo indicado en Retry-After, y fallan sin reintentar si pide más que MaxDelay; las 401/403 fallan de inmediato con domain.ErrProviderAuth.
+// Cada intento espera su turno en el limitador del proveedor; con la cuota diaria agotada falla con
+// domain.ErrQuotaExceeded sin hacer la petición.
+func (rc *recommendationClient) GetRecommendations(ctx context.Context, nextPage string) (*domain.APIResponse, error) {
+	url, err := rc.pageURL(nextPage)
+	if err != nil {
+		return nil, err
 	}
 
+	for attempt := 0; ; attempt++ {
+		if err := rc.limiter.Wait(ctx); err != nil {
+			metrics.ObserveExternalAPIRequest(rc.provider.Name, "error")
+			return nil, err
+		}
+		page, err := rc.fetchPage(ctx, url, nextPage)
+		if err == nil {
+			metrics.ObserveExternalAPIRequest(rc.provider.Name, "success")
+			return page, nil
+		}
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt >= rc.retry.MaxRetries || ctx.Err() != nil {
+			metrics.ObserveExternalAPIRequest(rc.provider.Name, "error")
+			return nil, err
+		}
+
+		delay := retryable.retryAfter
+		if delay > rc.retry.MaxDelay {
+			metrics.ObserveExternalAPIRequest(rc.provider.Name, "error")
+			return nil, fmt.Errorf("%w; el proveedor pide esperar %s antes de reintentar, más que el máximo de %s",
+				err, delay.Round(time.Second), rc.retry.MaxDelay)
+		}
+		if delay <= 0 {
+			delay = rc.backoff(attempt)
+		}
+		metrics.ObserveExternalAPIRetry(rc.provider.Name, retryable.reason)
+		log.Printf("%s request failed (%v); retry %d/%d in %s", rc.provider.Name, err, attempt+1, rc.retry.MaxRetries, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			metrics.ObserveExternalAPIRequest(rc.provider.Name, "error")
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// fetchPage hace una única petición a la API externa y clasifica el fallo, si lo hay.
+// current es el token de la página pedida, necesario para calcular la siguiente en la paginación por número.
+func (rc *recommendationClient) fetchPage(ctx context.Context, url, current string) (*domain.APIResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("error al crear la solicitud: %v", err)
+		return nil, fmt.Errorf("error al crear la solicitud: %v", err)
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", rc.apiKey))
+	req.Header.Add(rc.provider.AuthHeader, rc.authValue())
 	req.Header.Add("Content-Type", "application/json")
 
 	resp, err := rc.client.Do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("error en la solicitud: %v", err)
+		return nil, &retryableError{reason: "network", err: fmt.Errorf("error en la solicitud: %v", err)}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("código de estado no exitoso: %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%w: código de estado %d", domain.ErrProviderAuth, resp.StatusCode)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, &retryableError{
+			reason:     strconv.Itoa(resp.StatusCode),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			err:        fmt.Errorf("código de estado no exitoso: %d", resp.StatusCode),
+		}
+	default:
+		return nil, fmt.Errorf("código de estado no exitoso: %d", resp.StatusCode)
 	}
 
-	var apiResponse domain.APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return nil, "", fmt.Errorf("error al decodificar JSON: %v", err)
+	// El cuerpo se lee completo para archivarlo tal como llegó
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &retryableError{reason: "network", err: fmt.Errorf("error leyendo la respuesta: %v", err)}
 	}
-
-	return apiResponse.Items, apiResponse.NextPage, nil
+	page, err := rc.decodePage(bytes.NewReader(body), current)
+	if err != nil {
+		return nil, err
+	}
+	page.Raw = &domain.ArchivedPage{
+		Source:      rc.provider.Name,
+		PageToken:   current,
+		URL:         url,
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+		Fingerprint: domain.PayloadFingerprint(body),
+		FetchedAt:   time.Now(),
+	}
+	return page, nil
 }
 
-// GetAllRecommendations obtiene todas las recomendaciones de la API externa manejando la paginación.
-// Continúa consultando hasta que no haya más páginas disponibles.
-func (rc *recommendationClient) GetAllRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
-	var allItems []domain.StockRecommendation
-	nextPage := ""
+// backoff calcula la espera antes del reintento indicado: InitialDelay * 2^attempt, con tope MaxDelay
+// y jitter ("equal jitter": la mitad fija y la otra mitad aleatoria) para no sincronizar clientes.
+func (rc *recommendationClient) backoff(attempt int) time.Duration {
+	delay := rc.retry.InitialDelay
+	for i := 0; i < attempt && delay < rc.retry.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > rc.retry.MaxDelay {
+		delay = rc.retry.MaxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
 
-	for {
-		items, newNextPage, err := rc.GetRecommendations(ctx, nextPage)
-		if err != nil {
-			return nil, fmt.Errorf("error obteniendo página: %v", err)
+// parseRetryAfter interpreta la cabecera Retry-After en segundos o como fecha HTTP; devuelve 0 si no es válida.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
 		}
-
-		allItems = append(allItems, items...)
-
-		if newNextPage == "" {
-			break // No hay más páginas
+		if int64(seconds) > math.MaxInt64/int64(time.Second) {
+			return math.MaxInt64 // Sin desbordar: cualquier tope la rechaza
 		}
-
-		nextPage = newNextPage
-		time.Sleep(2 * time.Second) // Pausa para evitar rate limits
+		return time.Duration(seconds) * time.Second
 	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
 
-	return allItems, nil
+// StreamPages recorre las páginas a partir de nextPage, pidiendo las siguientes mientras se procesan las
+// recibidas. Cada página se pide con GetRecommendations, así que conserva sus reintentos.
+func (rc *recommendationClient) StreamPages(ctx context.Context, nextPage string, options domain.PageStreamOptions) domain.PageStream {
+	return newPageStream(ctx, rc.GetRecommendations, nextPage, options)
 }
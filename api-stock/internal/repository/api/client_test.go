@@ -0,0 +1,86 @@
+package api
+
+import (
+	"api-stock/internal/config"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"sin cabecera", "", 0},
+		{"cero", "0", 0},
+		{"negativo", "-5", 0},
+		{"segundos", "120", 2 * time.Minute},
+		{"segundos fuera de rango", "99999999999999999", math.MaxInt64},
+		{"texto", "pronto", 0},
+		{"fecha pasada", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRecommendationsRetryAfterBound(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryAfter   string
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "dentro del máximo se reintenta", retryAfter: "1", wantRequests: 2},
+		{name: "mayor que el máximo falla sin reintentar", retryAfter: "3600", wantErr: true, wantRequests: 1},
+		{name: "fuera de rango falla sin reintentar", retryAfter: "99999999999999999", wantErr: true, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests++
+				first := requests == 1
+				mu.Unlock()
+				if first {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(`{"items": [], "next_page": ""}`))
+			}))
+			defer server.Close()
+
+			client, err := NewRecommendationClient(
+				config.ProviderConfig{Name: "test", BaseURL: server.URL, Token: "token", RateLimit: config.RateLimitConfig{RPS: -1}},
+				RetryConfig{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: 2 * time.Second}, nil, nil)
+			if err != nil {
+				t.Fatalf("NewRecommendationClient error: %v", err)
+			}
+
+			_, err = client.GetRecommendations(context.Background(), "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRecommendations() error = %v, want error = %v", err, tt.wantErr)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if requests != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
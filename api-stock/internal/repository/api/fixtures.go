@@ -0,0 +1,260 @@
+package api
+
+import (
+	"api-stock/internal/config"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted reemplaza los secretos en las grabaciones.
+const redacted = "REDACTED"
+
+// sensitiveHeaders son las cabeceras que nunca se graban con su valor.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// FixtureConfig indica si las peticiones a los proveedores se graban o se reproducen desde disco.
+// Cada proveedor usa el archivo <directorio>/<nombre>.json. Con ReplayDir no se hace ninguna petición real.
+type FixtureConfig struct {
+	RecordDir string // Directorio donde se graban los intercambios con los proveedores (vacío no graba)
+	ReplayDir string // Directorio desde el que se reproducen los intercambios grabados (vacío usa la red)
+}
+
+// transport devuelve el transporte HTTP del proveedor según la configuración, o nil para el transporte por defecto.
+func (f FixtureConfig) transport(provider config.ProviderConfig) (http.RoundTripper, error) {
+	secrets := []string{provider.Token}
+	switch {
+	case f.ReplayDir != "":
+		return NewReplayTransport(filepath.Join(f.ReplayDir, provider.Name+".json"), provider.AuthHeader, secrets...)
+	case f.RecordDir != "":
+		return NewRecordingTransport(filepath.Join(f.RecordDir, provider.Name+".json"), nil, provider.AuthHeader, secrets...), nil
+	default:
+		return nil, nil
+	}
+}
+
+// Cassette es el archivo con los intercambios grabados de un proveedor, en orden.
+type Cassette struct {
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+// Exchange es una petición al proveedor y su respuesta, con los secretos reemplazados por REDACTED.
+// El cuerpo se guarda en JSON si es JSON válido (más fácil de editar a mano) y como texto si no.
+type Exchange struct {
+	Method         string          `json:"method"`
+	URL            string          `json:"url"`
+	RequestHeaders http.Header     `json:"request_headers,omitempty"`
+	Status         int             `json:"status"`
+	Headers        http.Header     `json:"headers,omitempty"`
+	JSON           json.RawMessage `json:"json,omitempty"`
+	Body           string          `json:"body,omitempty"`
+	RecordedAt     time.Time       `json:"recorded_at"`
+}
+
+// body devuelve el cuerpo de la respuesta grabada.
+func (e Exchange) body() []byte {
+	if len(e.JSON) > 0 {
+		return e.JSON
+	}
+	return []byte(e.Body)
+}
+
+// redactor reemplaza los secretos de un proveedor en URLs, cabeceras y cuerpos.
+type redactor struct {
+	headers []string // Cabeceras cuyo valor se reemplaza entero
+	secrets []string // Valores que se reemplazan donde aparezcan
+}
+
+func newRedactor(authHeader string, secrets []string) redactor {
+	r := redactor{headers: append([]string{authHeader}, sensitiveHeaders...)}
+	for _, secret := range secrets {
+		if secret != "" {
+			r.secrets = append(r.secrets, secret, url.QueryEscape(secret))
+		}
+	}
+	return r
+}
+
+func (r redactor) text(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+func (r redactor) header(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		for _, value := range values {
+			out.Add(name, r.text(value))
+		}
+	}
+	for _, name := range r.headers {
+		if name != "" && out.Get(name) != "" {
+			out.Set(name, redacted)
+		}
+	}
+	return out
+}
+
+// key identifica una petición para reproducirla: método y URL sin secretos, con la query ordenada.
+func (r redactor) key(method, rawURL string) string {
+	rawURL = r.text(rawURL)
+	if u, err := url.Parse(rawURL); err == nil {
+		u.RawQuery = u.Query().Encode()
+		rawURL = u.String()
+	}
+	return method + " " + rawURL
+}
+
+// recordingTransport graba cada intercambio con el proveedor en su cassette.
+type recordingTransport struct {
+	path     string
+	next     http.RoundTripper
+	redactor redactor
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecordingTransport crea un transporte que hace las peticiones con next (http.DefaultTransport si es nil)
+// y graba cada respuesta en path, reemplazando el valor de authHeader y los secretos indicados.
+// El archivo se reescribe completo después de cada intercambio, así que una sincronización interrumpida
+// deja grabado lo que alcanzó a recibir.
+func NewRecordingTransport(path string, next http.RoundTripper, authHeader string, secrets ...string) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recordingTransport{path: path, next: next, redactor: newRedactor(authHeader, secrets)}
+}
+
+// RoundTrip hace la petición y graba la respuesta; los errores de red no se graban.
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la respuesta a grabar: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	exchange := Exchange{
+		Method:         req.Method,
+		URL:            t.redactor.text(req.URL.String()),
+		RequestHeaders: t.redactor.header(req.Header),
+		Status:         resp.StatusCode,
+		Headers:        t.redactor.header(resp.Header),
+		RecordedAt:     time.Now().UTC(),
+	}
+	if clean := []byte(t.redactor.text(string(body))); json.Valid(clean) {
+		exchange.JSON = clean
+	} else {
+		exchange.Body = string(clean)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Exchanges = append(t.cassette.Exchanges, exchange)
+	if err := writeCassette(t.path, t.cassette); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// writeCassette escribe el cassette en un archivo temporal y lo renombra, para no dejarlo a medias.
+func writeCassette(path string, cassette Cassette) error {
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando grabación: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creando directorio de grabaciones: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error escribiendo grabación: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error escribiendo grabación: %v", err)
+	}
+	return nil
+}
+
+// replayTransport responde con los intercambios grabados, sin acceder a la red.
+type replayTransport struct {
+	redactor redactor
+
+	mu        sync.Mutex
+	exchanges map[string][]Exchange // Respuestas pendientes por petición, en orden de grabación
+}
+
+// NewReplayTransport crea un transporte que responde con los intercambios del cassette en path.
+// Cada petición se compara por método y URL (sin secretos y con la query ordenada); las respuestas de una
+// misma petición se sirven en el orden en que se grabaron (un 429 y luego el 200 del reintento) y la última
+// se repite si se vuelve a pedir. Una petición sin grabación falla.
+func NewReplayTransport(path, authHeader string, secrets ...string) (http.RoundTripper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo grabación: %v", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("error decodificando grabación %s: %v", path, err)
+	}
+
+	t := &replayTransport{redactor: newRedactor(authHeader, secrets), exchanges: make(map[string][]Exchange)}
+	for _, exchange := range cassette.Exchanges {
+		key := t.redactor.key(exchange.Method, exchange.URL)
+		t.exchanges[key] = append(t.exchanges[key], exchange)
+	}
+	return t, nil
+}
+
+// RoundTrip devuelve la siguiente respuesta grabada para la petición.
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	key := t.redactor.key(req.Method, req.URL.String())
+
+	t.mu.Lock()
+	pending := t.exchanges[key]
+	if len(pending) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no hay respuesta grabada para %s", key)
+	}
+	exchange := pending[0]
+	if len(pending) > 1 {
+		t.exchanges[key] = pending[1:]
+	}
+	t.mu.Unlock()
+
+	body := exchange.body()
+	header := exchange.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Del("Content-Length") // El cuerpo grabado puede tener otro formato que el original
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"api-stock/internal/config"
+	"api-stock/internal/domain"
+	"api-stock/pkg/metrics"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// quotaTimeout acota las consultas de la cuota que no dependen del contexto de una petición: devolver una
+// petición cancelada y leer el presupuesto para las métricas.
+const quotaTimeout = 5 * time.Second
+
+// quotaRefresh es la antigüedad a partir de la cual el presupuesto publicado vuelve a leer la cuota, para
+// recoger las peticiones de otros procesos.
+const quotaRefresh = 30 * time.Second
+
+// requestLimiter reparte las peticiones a un proveedor: un token bucket marca el ritmo (RPS y ráfaga) y una
+// cuota diaria, que se renueva a medianoche UTC, acota el total. El ritmo es de cada proceso; la cuota se
+// cuenta en quotas, compartida por todos los procesos y por los reinicios, y cada petición la descuenta
+// antes de hacerse con una operación atómica.
+type requestLimiter struct {
+	provider string
+	limiter  *rate.Limiter
+	quota    int                    // Peticiones por día (0 sin límite)
+	quotas   domain.QuotaRepository // Cuenta de peticiones por día
+	now      func() time.Time       // Reloj del día de la cuota
+
+	// Última cuenta conocida de la cuota, que publica budget sin consultar quotas
+	mu         sync.Mutex
+	usedDay    time.Time // Día de used
+	used       int       // Peticiones del día al descontar, devolver o leer la cuota
+	usedAt     time.Time // Momento de esa cuenta (cero: aún no se conoce)
+	version    int       // Cambia con cada descuento o devolución, para descartar lecturas anteriores
+	refreshing bool      // Hay una lectura de la cuota en curso
+}
+
+// newRequestLimiter crea el limitador de un proveedor y publica su presupuesto en las métricas. Sin
+// quotas, la cuota se cuenta en memoria del proceso.
+func newRequestLimiter(provider string, limits config.RateLimitConfig, quotas domain.QuotaRepository) *requestLimiter {
+	limit := rate.Limit(limits.RPS)
+	if limits.RPS <= 0 {
+		limit = rate.Inf
+	}
+	burst := limits.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	if quotas == nil {
+		quotas = newMemoryQuotas()
+	}
+	l := &requestLimiter{provider: provider, limiter: rate.NewLimiter(limit, burst), quota: limits.DailyQuota, quotas: quotas, now: time.Now}
+	metrics.RegisterExternalAPIBudget(provider, l.budget)
+	return l
+}
+
+// Wait espera el turno de una petición y la descuenta de la cuota. Si la cuota está agotada devuelve
+// domain.ErrQuotaExceeded sin esperar; si ctx se cancela durante la espera, la petición no cuenta.
+func (l *requestLimiter) Wait(ctx context.Context) error {
+	day, err := l.take(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := l.limiter.Wait(ctx); err != nil {
+		l.refund(ctx, day)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err // El plazo de ctx vence antes del turno
+	}
+	metrics.ObserveRateLimitWait(l.provider, time.Since(start))
+	return nil
+}
+
+// take descuenta una petición de la cuota del día y devuelve el día al que se descontó.
+func (l *requestLimiter) take(ctx context.Context) (time.Time, error) {
+	today := l.today()
+	if l.quota <= 0 {
+		return today, nil
+	}
+	used, ok, err := l.quotas.TakeQuota(ctx, l.provider, today, l.quota)
+	if err != nil {
+		return today, err
+	}
+	l.recordUsed(today, used)
+	if !ok {
+		resetAt := domain.QuotaResetAt(today)
+		log.Printf("%s daily quota of %d requests exhausted; renews at %s", l.provider, l.quota, resetAt.Format(time.RFC3339))
+		return today, fmt.Errorf("%w: %s hizo %d peticiones hoy; se renueva el %s",
+			domain.ErrQuotaExceeded, l.provider, used, resetAt.Format(time.RFC3339))
+	}
+	return today, nil
+}
+
+// refund devuelve a la cuota del día una petición que no llegó a hacerse, aunque ctx se haya cancelado.
+func (l *requestLimiter) refund(ctx context.Context, day time.Time) {
+	if l.quota <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), quotaTimeout)
+	defer cancel()
+	if err := l.quotas.RefundQuota(ctx, l.provider, day); err != nil {
+		log.Printf("Failed to refund %s daily quota: %v", l.provider, err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.usedDay.Equal(day) && l.used > 0 {
+		l.used--
+		l.version++
+	}
+}
+
+// recordUsed guarda las peticiones del día que devolvió un descuento de la cuota.
+func (l *requestLimiter) recordUsed(day time.Time, used int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.usedDay, l.used, l.usedAt = day, used, time.Now()
+	l.version++
+}
+
+// budget devuelve las peticiones disponibles ahora y las que quedan de la cuota del día según la última
+// cuenta conocida, sin esperar a la base de datos: si la cuenta es de otro día o más antigua que
+// quotaRefresh, lanza una lectura en segundo plano para la siguiente llamada. Mientras no se conoce ninguna
+// cuenta, omite la cuota.
+func (l *requestLimiter) budget() metrics.Budget {
+	budget := metrics.Budget{Tokens: l.limiter.Tokens(), QuotaRemaining: -1}
+	if l.limiter.Limit() == rate.Inf {
+		budget.Tokens = float64(l.limiter.Burst())
+	}
+	if l.quota <= 0 {
+		return budget
+	}
+
+	today := l.today()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	used := l.used
+	if !l.usedDay.Equal(today) {
+		used = 0 // La cuota se renovó desde la última cuenta
+	}
+	if !l.usedAt.IsZero() {
+		budget.QuotaRemaining = max(l.quota-used, 0)
+	}
+	stale := l.usedAt.IsZero() || !l.usedDay.Equal(today) || time.Since(l.usedAt) > quotaRefresh
+	if stale && !l.refreshing {
+		l.refreshing = true
+		go l.refreshUsed(today, l.version)
+	}
+	return budget
+}
+
+// refreshUsed lee de quotas las peticiones del día y las guarda como última cuenta, salvo que un
+// descuento o una devolución posterior a la lectura (version) la haya dejado atrás.
+func (l *requestLimiter) refreshUsed(day time.Time, version int) {
+	ctx, cancel := context.WithTimeout(context.Background(), quotaTimeout)
+	defer cancel()
+	used, err := l.quotas.GetQuotaUsed(ctx, l.provider, day)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refreshing = false
+	if err != nil {
+		log.Printf("Failed to read %s daily quota: %v", l.provider, err)
+		return
+	}
+	if l.version == version {
+		l.usedDay, l.used, l.usedAt = day, used, time.Now()
+	}
+}
+
+// today devuelve el día UTC de la cuota en curso.
+func (l *requestLimiter) today() time.Time {
+	return l.now().UTC().Truncate(24 * time.Hour)
+}
+
+// memoryQuotas implementa domain.QuotaRepository en memoria del proceso, para los clientes que no
+// comparten la cuota (pruebas, reproducción de grabaciones).
+type memoryQuotas struct {
+	mu   sync.Mutex
+	used map[string]map[time.Time]int // Peticiones por proveedor y día
+}
+
+// newMemoryQuotas crea una cuenta de cuotas vacía.
+func newMemoryQuotas() *memoryQuotas {
+	return &memoryQuotas{used: make(map[string]map[time.Time]int)}
+}
+
+// TakeQuota descuenta una petición si el día tiene menos de limit. Olvida los días anteriores del proveedor.
+func (m *memoryQuotas) TakeQuota(ctx context.Context, provider string, day time.Time, limit int) (int, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	days := m.used[provider]
+	if days == nil {
+		days = make(map[time.Time]int)
+		m.used[provider] = days
+	}
+	for d := range days {
+		if d.Before(day) {
+			delete(days, d)
+		}
+	}
+	if days[day] >= limit {
+		return days[day], false, nil
+	}
+	days[day]++
+	return days[day], true, nil
+}
+
+// RefundQuota devuelve una petición a la cuota del día, sin bajar de cero.
+func (m *memoryQuotas) RefundQuota(ctx context.Context, provider string, day time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.used[provider][day] > 0 {
+		m.used[provider][day]--
+	}
+	return nil
+}
+
+// GetQuotaUsed obtiene las peticiones del día.
+func (m *memoryQuotas) GetQuotaUsed(ctx context.Context, provider string, day time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.used[provider][day], nil
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"api-stock/internal/config"
+	"api-stock/internal/domain"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newTestLimiter crea un limitador sin límite de ritmo, con la cuota y el reloj indicados.
+func newTestLimiter(quota int, quotas domain.QuotaRepository, now *time.Time) *requestLimiter {
+	l := newRequestLimiter("test", config.RateLimitConfig{RPS: -1, DailyQuota: quota}, quotas)
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestRequestLimiterQuotaRollover(t *testing.T) {
+	day := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+
+	// Cada paso descuenta una petición en el momento indicado
+	type step struct {
+		at            time.Time
+		wantExceeded  bool
+		wantRemaining int // Cuota que queda después del paso
+	}
+	tests := []struct {
+		name  string
+		quota int
+		steps []step
+	}{
+		{
+			name:  "se agota y se renueva a medianoche UTC",
+			quota: 2,
+			steps: []step{
+				{at: day.Add(9 * time.Hour), wantRemaining: 1},
+				{at: day.Add(15 * time.Hour), wantRemaining: 0},
+				{at: day.Add(23*time.Hour + 59*time.Minute), wantExceeded: true, wantRemaining: 0},
+				{at: day.Add(24 * time.Hour), wantRemaining: 1},
+				{at: day.Add(25 * time.Hour), wantRemaining: 0},
+				{at: day.Add(26 * time.Hour), wantExceeded: true, wantRemaining: 0},
+			},
+		},
+		{
+			name:  "el día es UTC aunque el reloj tenga otra zona",
+			quota: 1,
+			steps: []step{
+				{at: day.Add(23 * time.Hour).In(time.FixedZone("UTC-5", -5*3600)), wantRemaining: 0},
+				{at: day.Add(23*time.Hour + 30*time.Minute).In(time.FixedZone("UTC+2", 2*3600)), wantExceeded: true, wantRemaining: 0},
+				{at: day.Add(24*time.Hour + time.Minute).In(time.FixedZone("UTC-5", -5*3600)), wantRemaining: 0},
+			},
+		},
+		{
+			name:  "un día sin peticiones no arrastra la cuenta",
+			quota: 3,
+			steps: []step{
+				{at: day, wantRemaining: 2},
+				{at: day.Add(72 * time.Hour), wantRemaining: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := tt.steps[0].at
+			l := newTestLimiter(tt.quota, nil, &now)
+			for i, step := range tt.steps {
+				now = step.at
+				err := l.Wait(context.Background())
+				if exceeded := errors.Is(err, domain.ErrQuotaExceeded); exceeded != step.wantExceeded || (err != nil && !exceeded) {
+					t.Fatalf("step %d at %v: Wait() error = %v, want exceeded = %v", i, step.at, err, step.wantExceeded)
+				}
+				if got := l.budget().QuotaRemaining; got != step.wantRemaining {
+					t.Fatalf("step %d at %v: QuotaRemaining = %d, want %d", i, step.at, got, step.wantRemaining)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestLimiterRefund(t *testing.T) {
+	now := time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)
+	background := func() (context.Context, context.CancelFunc) { return context.Background(), func() {} }
+	cancelled := func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx, cancel
+	}
+	deadline := func() (context.Context, context.CancelFunc) {
+		return context.WithTimeout(context.Background(), time.Second)
+	}
+
+	tests := []struct {
+		name          string
+		rps           float64 // Ritmo después de la primera petición, que gasta la ráfaga
+		ctx           func() (context.Context, context.CancelFunc)
+		wantErr       error // nil: la petición se hace
+		wantRemaining int
+	}{
+		{name: "la petición se hace", rps: 1000, ctx: background, wantRemaining: 3},
+		{name: "contexto cancelado antes del turno", rps: 1.0 / 3600, ctx: cancelled, wantErr: context.Canceled, wantRemaining: 4},
+		{name: "el plazo vence antes del turno", rps: 1.0 / 3600, ctx: deadline, wantErr: errAny, wantRemaining: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRequestLimiter("test", config.RateLimitConfig{RPS: 1000, Burst: 1, DailyQuota: 5}, nil)
+			l.now = func() time.Time { return now }
+			if err := l.Wait(context.Background()); err != nil {
+				t.Fatalf("first Wait() error: %v", err)
+			}
+			l.limiter.SetLimit(rate.Limit(tt.rps))
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			err := l.Wait(ctx)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("Wait() error: %v", err)
+			case tt.wantErr == errAny && err == nil:
+				t.Errorf("Wait() did not fail")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Errorf("Wait() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := l.budget().QuotaRemaining; got != tt.wantRemaining {
+				t.Errorf("QuotaRemaining = %d, want %d", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+// errAny indica en una tabla que se espera un error cualquiera.
+var errAny = errors.New("cualquier error")
+
+func TestRequestLimiterSharedQuota(t *testing.T) {
+	now := time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)
+	quotas := newMemoryQuotas()
+	a := newTestLimiter(3, quotas, &now) // Dos procesos con la misma cuenta
+	b := newTestLimiter(3, quotas, &now)
+
+	steps := []struct {
+		limiter      *requestLimiter
+		wantExceeded bool
+	}{
+		{a, false},
+		{b, false},
+		{a, false},
+		{b, true},
+		{a, true},
+	}
+	for i, step := range steps {
+		err := step.limiter.Wait(context.Background())
+		if errors.Is(err, domain.ErrQuotaExceeded) != step.wantExceeded {
+			t.Fatalf("step %d: Wait() error = %v, want exceeded = %v", i, err, step.wantExceeded)
+		}
+	}
+
+	// Una devolución de un día anterior no da cuota al día en curso
+	a.refund(context.Background(), now.Add(-24*time.Hour).Truncate(24*time.Hour))
+	if got := b.budget().QuotaRemaining; got != 0 {
+		t.Errorf("QuotaRemaining after refunding yesterday = %d, want 0", got)
+	}
+}
+
+func TestRequestLimiterWithoutQuota(t *testing.T) {
+	now := time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)
+	l := newTestLimiter(0, nil, &now)
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error: %v", err)
+		}
+	}
+	if got := l.budget().QuotaRemaining; got != -1 {
+		t.Errorf("QuotaRemaining = %d, want -1", got)
+	}
+}
+
+// countingQuotas cuenta las lecturas de la cuota de un domain.QuotaRepository.
+type countingQuotas struct {
+	domain.QuotaRepository
+	mu    sync.Mutex
+	reads int
+}
+
+func (c *countingQuotas) GetQuotaUsed(ctx context.Context, provider string, day time.Time) (int, error) {
+	c.mu.Lock()
+	c.reads++
+	c.mu.Unlock()
+	return c.QuotaRepository.GetQuotaUsed(ctx, provider, day)
+}
+
+func (c *countingQuotas) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reads
+}
+
+func TestRequestLimiterBudgetCache(t *testing.T) {
+	now := time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)
+	quotas := &countingQuotas{QuotaRepository: newMemoryQuotas()}
+	other := newTestLimiter(5, quotas, &now) // Otro proceso con la misma cuenta
+	for i := 0; i < 2; i++ {
+		if err := other.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error: %v", err)
+		}
+	}
+
+	l := newTestLimiter(5, quotas, &now)
+	if got := l.budget().QuotaRemaining; got != -1 {
+		t.Fatalf("QuotaRemaining before the first read = %d, want -1", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	for l.budget().QuotaRemaining != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("QuotaRemaining = %d after the background read, want 3", l.budget().QuotaRemaining)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Con la cuenta reciente, el presupuesto no consulta la cuota y sigue los descuentos propios
+	reads := quotas.count()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if got := l.budget().QuotaRemaining; got != 2 {
+			t.Fatalf("QuotaRemaining = %d, want 2", got)
+		}
+	}
+	if got := quotas.count(); got != reads {
+		t.Errorf("budget() read the quota %d times with a fresh count, want 0", got-reads)
+	}
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"api-stock/internal/config"
+	"api-stock/internal/domain"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Estilos de paginación soportados.
+const (
+	paginationToken = "token" // La respuesta trae el token de la siguiente página
+	paginationPage  = "page"  // Páginas numeradas; termina con una página vacía o incompleta
+)
+
+// providerWithDefaults completa los valores por defecto de un proveedor (los del proveedor original)
+// y valida su configuración.
+func providerWithDefaults(p config.ProviderConfig) (config.ProviderConfig, error) {
+	if !domain.ValidSource(p.Name) {
+		return p, fmt.Errorf("proveedor %q: el nombre debe usar minúsculas, dígitos y guiones bajos (máximo 50)", p.Name)
+	}
+	if _, err := url.Parse(p.BaseURL); err != nil || p.BaseURL == "" {
+		return p, fmt.Errorf("proveedor %s: base_url inválida %q", p.Name, p.BaseURL)
+	}
+	if p.AuthHeader == "" {
+		p.AuthHeader = "Authorization"
+	}
+	if p.AuthScheme == "" {
+		p.AuthScheme = "Bearer"
+	}
+	if p.ItemsField == "" {
+		p.ItemsField = "items"
+	}
+	if err := domain.FieldMapping(p.Fields).Validate(); err != nil {
+		return p, fmt.Errorf("proveedor %s: %v", p.Name, err)
+	}
+
+	pg := &p.Pagination
+	switch pg.Style {
+	case "", paginationToken:
+		pg.Style = paginationToken
+		if pg.Param == "" {
+			pg.Param = "next_page"
+		}
+		if pg.ResponseField == "" {
+			pg.ResponseField = "next_page"
+		}
+	case paginationPage:
+		if pg.Param == "" {
+			pg.Param = "page"
+		}
+		if pg.FirstPage == 0 {
+			pg.FirstPage = 1
+		}
+	default:
+		return p, fmt.Errorf("proveedor %s: estilo de paginación desconocido %q", p.Name, pg.Style)
+	}
+	return p, nil
+}
+
+// authValue devuelve el valor de la cabecera de autenticación.
+func (rc *recommendationClient) authValue() string {
+	if strings.EqualFold(rc.provider.AuthScheme, "none") {
+		return rc.provider.Token
+	}
+	return rc.provider.AuthScheme + " " + rc.provider.Token
+}
+
+// pageURL construye la URL de la página indicada por el token (vacío es la primera página).
+func (rc *recommendationClient) pageURL(nextPage string) (string, error) {
+	u, err := url.Parse(rc.provider.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("error al construir la URL: %v", err)
+	}
+	pg := rc.provider.Pagination
+	query := u.Query()
+	switch pg.Style {
+	case paginationPage:
+		if nextPage == "" {
+			nextPage = strconv.Itoa(pg.FirstPage)
+		}
+		query.Set(pg.Param, nextPage)
+		if pg.SizeParam != "" && pg.Size > 0 {
+			query.Set(pg.SizeParam, strconv.Itoa(pg.Size))
+		}
+	default:
+		if nextPage != "" {
+			query.Set(pg.Param, nextPage)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// decodePage lee la respuesta del proveedor, traduce y valida cada registro, y calcula el token de la
+// siguiente página ("" si es la última). Un registro que no se puede traducir o no supera la validación
+// se devuelve en Rejected con su payload original; solo una respuesta ilegible hace fallar la página.
+func (rc *recommendationClient) decodePage(body io.Reader, current string) (*domain.APIResponse, error) {
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber() // Conserva los precios tal cual para Money
+	var payload map[string]interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("error al decodificar JSON: %v", err)
+	}
+
+	rawItems, _ := domain.LookupPath(payload, rc.provider.ItemsField)
+	list, ok := rawItems.([]interface{})
+	if !ok && rawItems != nil {
+		return nil, fmt.Errorf("el campo %s de la respuesta no es una lista", rc.provider.ItemsField)
+	}
+
+	page := &domain.APIResponse{Items: make([]domain.StockRecommendation, 0, len(list))}
+	for _, raw := range list {
+		rec, err := rc.mapRecord(raw)
+		if err != nil {
+			rejected, marshalErr := json.Marshal(raw)
+			if marshalErr != nil {
+				return nil, fmt.Errorf("error serializando registro rechazado: %v", marshalErr)
+			}
+			page.Rejected = append(page.Rejected, domain.QuarantinedRecord{
+				Source:      rc.provider.Name,
+				Fingerprint: domain.PayloadFingerprint(rejected),
+				Reason:      err.Error(),
+				Payload:     rejected,
+			})
+			continue
+		}
+		page.Items = append(page.Items, rec)
+	}
+	// La página por número termina con una página incompleta: cuentan también los registros rechazados
+	page.NextPage = rc.nextPage(payload, current, len(list))
+	return page, nil
+}
+
+// DecodePage decodifica una página archivada como si acabara de recibirse, con el mapeo de campos actual.
+func (rc *recommendationClient) DecodePage(page domain.ArchivedPage) (*domain.APIResponse, error) {
+	return rc.decodePage(bytes.NewReader(page.Body), page.PageToken)
+}
+
+// DecodeRecord decodifica y valida un registro del proveedor con su mapeo de campos.
+func (rc *recommendationClient) DecodeRecord(payload json.RawMessage) (domain.StockRecommendation, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return domain.StockRecommendation{}, fmt.Errorf("%w: el payload no es JSON válido: %v", domain.ErrInvalidRecommendation, err)
+	}
+	return rc.mapRecord(raw)
+}
+
+// mapRecord traduce un registro del proveedor a una recomendación usando el mapeo de campos y la valida.
+func (rc *recommendationClient) mapRecord(raw interface{}) (domain.StockRecommendation, error) {
+	item, ok := raw.(map[string]interface{})
+	if !ok {
+		return domain.StockRecommendation{}, fmt.Errorf("%w: el registro no es un objeto", domain.ErrInvalidRecommendation)
+	}
+	rec, err := domain.DecodeRecommendation(item, rc.provider.Fields, rc.provider.TimeLayout)
+	if err != nil {
+		return rec, fmt.Errorf("%w: %v", domain.ErrInvalidRecommendation, err)
+	}
+	rec.Source = rc.provider.Name
+	return rec, domain.ValidateRecommendation(rec)
+}
+
+// nextPage calcula el token de la siguiente página según el estilo de paginación.
+func (rc *recommendationClient) nextPage(payload map[string]interface{}, current string, count int) string {
+	pg := rc.provider.Pagination
+	if pg.Style == paginationPage {
+		if count == 0 || (pg.Size > 0 && count < pg.Size) {
+			return "" // Página vacía o incompleta: no hay más
+		}
+		page := pg.FirstPage
+		if n, err := strconv.Atoi(current); err == nil {
+			page = n
+		}
+		return strconv.Itoa(page + 1)
+	}
+
+	value, _ := domain.LookupPath(payload, pg.ResponseField)
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"api-stock/internal/config"
+	"api-stock/internal/domain"
+	"fmt"
+)
+
+// Registry agrupa los proveedores de recomendaciones por nombre, en orden de registro.
+// Además de los clientes configurados por archivo admite cualquier otra implementación de domain.ExternalAPI.
+type Registry struct {
+	providers []domain.ExternalAPI
+	byName    map[string]domain.ExternalAPI
+}
+
+// NewRegistry crea un registro de proveedores vacío.
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]domain.ExternalAPI)}
+}
+
+// NewRegistryFromConfig crea un cliente por cada proveedor configurado y los registra.
+// fixtures indica si sus peticiones se graban o se reproducen desde disco; quotas lleva sus cuotas
+// diarias (nil las cuenta en memoria del proceso).
+func NewRegistryFromConfig(providers []config.ProviderConfig, retry RetryConfig, fixtures FixtureConfig, quotas domain.QuotaRepository) (*Registry, error) {
+	registry := NewRegistry()
+	for _, provider := range providers {
+		provider, err := providerWithDefaults(provider)
+		if err != nil {
+			return nil, err
+		}
+		transport, err := fixtures.transport(provider)
+		if err != nil {
+			return nil, fmt.Errorf("proveedor %s: %v", provider.Name, err)
+		}
+		if fixtures.ReplayDir != "" {
+			provider.RateLimit = config.RateLimitConfig{RPS: -1, DailyQuota: -1} // Las grabaciones no consumen cuota del proveedor
+		}
+		client, err := NewRecommendationClient(provider, retry, transport, quotas)
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.Register(client); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Register agrega un proveedor; falla si el nombre no es válido o ya está registrado.
+func (r *Registry) Register(provider domain.ExternalAPI) error {
+	name := provider.Name()
+	if !domain.ValidSource(name) {
+		return fmt.Errorf("nombre de proveedor inválido %q", name)
+	}
+	if _, exists := r.byName[name]; exists {
+		return fmt.Errorf("proveedor %s registrado dos veces", name)
+	}
+	r.byName[name] = provider
+	r.providers = append(r.providers, provider)
+	return nil
+}
+
+// Get devuelve el proveedor con ese nombre; el segundo valor es false si no existe.
+func (r *Registry) Get(name string) (domain.ExternalAPI, bool) {
+	provider, ok := r.byName[name]
+	return provider, ok
+}
+
+// Providers devuelve los proveedores en orden de registro.
+func (r *Registry) Providers() []domain.ExternalAPI {
+	return append([]domain.ExternalAPI(nil), r.providers...)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"io"
+)
+
+// pageResult es una página pedida por adelantado o el error que terminó la lectura.
+type pageResult struct {
+	page *domain.APIResponse
+	err  error
+}
+
+// pageStream implementa domain.PageStream: una goroutine pide las páginas y las deja en un canal acotado,
+// de modo que si el consumidor se retrasa la lectura se detiene con Prefetch páginas esperando en lugar de acumularlas.
+// Con Prefetch 0 no se pide nada por adelantado: cada página se pide cuando el consumidor llama a Next.
+type pageStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	pages  chan pageResult
+	demand chan struct{} // Con Prefetch 0, una señal de Next por página; nil si se pide por adelantado
+	err    error         // Error con el que terminó el stream (io.EOF después de la última página)
+}
+
+// newPageStream empieza a pedir páginas con fetch a partir de nextPage. Un Prefetch negativo se trata como 0.
+func newPageStream(ctx context.Context, fetch func(context.Context, string) (*domain.APIResponse, error), nextPage string, options domain.PageStreamOptions) *pageStream {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &pageStream{ctx: ctx, cancel: cancel}
+	if options.Prefetch > 0 {
+		// La goroutine retiene una página más que el canal mientras espera para entregarla
+		s.pages = make(chan pageResult, options.Prefetch-1)
+	} else {
+		s.pages = make(chan pageResult)
+		s.demand = make(chan struct{}, 1) // Next deja como mucho una señal pendiente
+	}
+	go s.run(fetch, nextPage)
+	return s
+}
+
+// run pide las páginas en orden hasta la última, un error o la cancelación. El ritmo de las peticiones
+// lo marca el limitador del cliente.
+func (s *pageStream) run(fetch func(context.Context, string) (*domain.APIResponse, error), nextPage string) {
+	defer close(s.pages)
+	for {
+		if !s.wait() {
+			return
+		}
+		page, err := fetch(s.ctx, nextPage)
+		if !s.send(pageResult{page: page, err: err}) || err != nil || page.NextPage == "" {
+			return
+		}
+		nextPage = page.NextPage
+	}
+}
+
+// wait espera a que el consumidor pida la siguiente página si no se pide por adelantado. Devuelve false si
+// se canceló.
+func (s *pageStream) wait() bool {
+	if s.demand == nil {
+		return true
+	}
+	select {
+	case <-s.demand:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// send entrega el resultado al consumidor; espera mientras el canal está lleno. Devuelve false si se canceló.
+func (s *pageStream) send(result pageResult) bool {
+	select {
+	case s.pages <- result:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// Next devuelve la siguiente página, o io.EOF después de la última.
+func (s *pageStream) Next() (*domain.APIResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.demand != nil {
+		s.demand <- struct{}{} // No bloquea: la goroutine consume cada señal antes de entregar la página
+	}
+	result, ok := <-s.pages
+	switch {
+	case !ok && s.ctx.Err() != nil:
+		s.err = s.ctx.Err() // Cancelado antes de la última página: no es el final de los datos
+	case !ok:
+		s.err = io.EOF
+	case result.err != nil:
+		s.err = result.err
+	default:
+		return result.page, nil
+	}
+	return nil, s.err
+}
+
+// Close cancela la lectura y descarta las páginas pedidas por adelantado.
+func (s *pageStream) Close() {
+	s.cancel()
+	for range s.pages {
+	}
+}
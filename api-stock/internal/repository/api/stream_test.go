@@ -0,0 +1,207 @@
+package api
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePages sirve total páginas numeradas y cuenta cuántas se pidieron.
+type fakePages struct {
+	mu      sync.Mutex
+	total   int
+	failAt  int // Página que devuelve error (0 ninguna)
+	fetched int
+}
+
+func (f *fakePages) fetch(ctx context.Context, token string) (*domain.APIResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetched++
+	page := f.fetched
+	if page == f.failAt {
+		return nil, fmt.Errorf("página %d: error del proveedor", page)
+	}
+	next := ""
+	if page < f.total {
+		next = fmt.Sprintf("p%d", page+1)
+	}
+	return &domain.APIResponse{NextPage: next}, nil
+}
+
+func (f *fakePages) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fetched
+}
+
+// settle espera a que el número de páginas pedidas deje de crecer y lo devuelve.
+func (f *fakePages) settle() int {
+	last := -1
+	for {
+		time.Sleep(20 * time.Millisecond)
+		n := f.count()
+		if n == last {
+			return n
+		}
+		last = n
+	}
+}
+
+func TestPageStreamPrefetchBound(t *testing.T) {
+	tests := []struct {
+		prefetch int
+		want     int // Páginas pedidas sin que el consumidor lea ninguna
+	}{
+		{prefetch: -1, want: 0},
+		{prefetch: 0, want: 0}, // Solo pide la página que se lee
+		{prefetch: 1, want: 1},
+		{prefetch: 3, want: 3},
+		{prefetch: 8, want: 8},
+		{prefetch: 50, want: 20}, // No pide más allá de la última página
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("prefetch %d", tt.prefetch), func(t *testing.T) {
+			pages := &fakePages{total: 20}
+			stream := newPageStream(context.Background(), pages.fetch, "", domain.PageStreamOptions{Prefetch: tt.prefetch})
+			defer stream.Close()
+
+			if got := pages.settle(); got != tt.want {
+				t.Fatalf("fetched %d pages before the first read, want %d", got, tt.want)
+			}
+
+			// Cada página leída deja sitio para pedir una más, hasta la última
+			for read := 1; read <= 5; read++ {
+				if _, err := stream.Next(); err != nil {
+					t.Fatalf("Next() error: %v", err)
+				}
+				if got, want := pages.settle(), min(tt.want+read, pages.total); got != want {
+					t.Fatalf("fetched %d pages after reading %d, want %d", got, read, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPageStreamEnd(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		failAt    int
+		prefetch  int
+		wantPages int
+		wantErr   error // nil: se espera el error de la página failAt
+	}{
+		{name: "una página", total: 1, prefetch: 2, wantPages: 1, wantErr: io.EOF},
+		{name: "varias páginas", total: 7, prefetch: 2, wantPages: 7, wantErr: io.EOF},
+		{name: "varias páginas sin lectura anticipada", total: 7, wantPages: 7, wantErr: io.EOF},
+		{name: "error a mitad", total: 7, failAt: 4, prefetch: 2, wantPages: 3},
+		{name: "error a mitad sin lectura anticipada", total: 7, failAt: 4, wantPages: 3},
+		{name: "error en la primera", total: 7, failAt: 1, prefetch: 2, wantPages: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := &fakePages{total: tt.total, failAt: tt.failAt}
+			stream := newPageStream(context.Background(), pages.fetch, "", domain.PageStreamOptions{Prefetch: tt.prefetch})
+			defer stream.Close()
+
+			read := 0
+			var err error
+			for {
+				if _, err = stream.Next(); err != nil {
+					break
+				}
+				read++
+			}
+			if read != tt.wantPages {
+				t.Errorf("read %d pages, want %d", read, tt.wantPages)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Next() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (err == nil || errors.Is(err, io.EOF)) {
+				t.Errorf("Next() error = %v, want the provider error", err)
+			}
+
+			// El error se repite y no se piden más páginas
+			if _, again := stream.Next(); again != err {
+				t.Errorf("Next() after the end = %v, want %v", again, err)
+			}
+			if got, want := pages.settle(), tt.wantPages+min(tt.failAt, 1); got != want {
+				t.Errorf("fetched %d pages, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestPageStreamCancellation(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefetch int
+		cancel   func(stream *pageStream, cancelParent context.CancelFunc)
+	}{
+		{name: "cancelar el contexto", prefetch: 4, cancel: func(_ *pageStream, cancelParent context.CancelFunc) { cancelParent() }},
+		{name: "cerrar el stream", prefetch: 4, cancel: func(stream *pageStream, _ context.CancelFunc) { stream.Close() }},
+		{name: "cerrar el stream sin lectura anticipada", cancel: func(stream *pageStream, _ context.CancelFunc) { stream.Close() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			pages := &fakePages{total: 100}
+			stream := newPageStream(ctx, pages.fetch, "", domain.PageStreamOptions{Prefetch: tt.prefetch})
+			before := pages.settle()
+
+			tt.cancel(stream, cancel)
+
+			// La lectura termina con el error de cancelación, nunca con io.EOF: los datos no se acabaron
+			var err error
+			for i := 0; i <= before && err == nil; i++ {
+				_, err = stream.Next()
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Next() after cancelling = %v, want %v", err, context.Canceled)
+			}
+			if got := pages.settle(); got != before {
+				t.Errorf("fetched %d pages after cancelling, want %d", got, before)
+			}
+			stream.Close() // Vuelve porque la goroutine terminó y cerró el canal
+		})
+	}
+}
+
+func TestPageStreamCloseUnblocksFetch(t *testing.T) {
+	started := make(chan struct{})
+	fetch := func(ctx context.Context, token string) (*domain.APIResponse, error) {
+		close(started)
+		<-ctx.Done() // Una petición lenta que respeta la cancelación
+		return nil, ctx.Err()
+	}
+	stream := newPageStream(context.Background(), fetch, "", domain.PageStreamOptions{Prefetch: 2})
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		stream.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close() did not return while a page was being fetched")
+	}
+	if _, err := stream.Next(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Next() after Close = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+)
+
+// archivedPageColumns es la lista de columnas que se leen al recorrer el archivo, en el orden de scanArchivedPage.
+const archivedPageColumns = `id, source, sync_run_id, page_token, url, status_code, content_type, content_encoding, body, fingerprint, first_fetched_at, fetched_at, fetches`
+
+// archivedPageBatch es el número de páginas que se leen por consulta al recorrer el archivo.
+const archivedPageBatch = 50
+
+// archiveRepository implementa domain.ArchiveRepository sobre la tabla raw_pages.
+// El cuerpo se guarda comprimido con gzip; content_encoding permite leer otras codificaciones.
+type archiveRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewArchiveRepository crea el repositorio del archivo de páginas del proveedor.
+func NewArchiveRepository(db *sql.DB) domain.ArchiveRepository {
+	return &archiveRepository{db: db}
+}
+
+// ArchivePage comprime y guarda la página, o actualiza la ya archivada con el mismo cuerpo. Con un lease en
+// el contexto, solo la guarda mientras siga siendo de este trabajo.
+func (r *archiveRepository) ArchivePage(ctx context.Context, page domain.ArchivedPage) error {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(page.Body); err != nil {
+		return fmt.Errorf("error comprimiendo página: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error comprimiendo página: %v", err)
+	}
+
+	fetchedAt := page.FetchedAt
+	if fetchedAt.IsZero() {
+		fetchedAt = time.Now()
+	}
+	err := inFencedTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+        INSERT INTO raw_pages (source, fingerprint, sync_run_id, page_token, url, status_code, content_type,
+            content_encoding, body, size, first_fetched_at, fetched_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, 'gzip', $8, $9, $10, $10)
+        ON CONFLICT (source, fingerprint) DO UPDATE SET
+            sync_run_id = EXCLUDED.sync_run_id,
+            page_token = EXCLUDED.page_token,
+            url = EXCLUDED.url,
+            fetched_at = greatest(raw_pages.fetched_at, EXCLUDED.fetched_at),
+            fetches = raw_pages.fetches + 1`,
+			page.Source, page.Fingerprint, nullString(page.SyncRunID), page.PageToken, page.URL, page.StatusCode,
+			page.ContentType, compressed.Bytes(), len(page.Body), fetchedAt)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error archivando página: %w", err)
+	}
+	return nil
+}
+
+// StreamArchivedPages recorre el archivo por lotes con paginación por clave (fetched_at, id), de modo que
+// fn puede escribir en la base de datos sin que haya una consulta abierta. Una página que se vuelve a
+// recibir durante el recorrido pasa al final y puede visitarse dos veces.
+func (r *archiveRepository) StreamArchivedPages(ctx context.Context, source string, since time.Time, fn func(domain.ArchivedPage) error) error {
+	afterTime := since
+	afterID := "00000000-0000-0000-0000-000000000000" // Incluye las páginas recibidas justo en since
+	for {
+		pages, err := r.archivedPagesAfter(ctx, source, afterTime, afterID)
+		if err != nil {
+			return err
+		}
+		for _, page := range pages {
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		if len(pages) < archivedPageBatch {
+			return nil
+		}
+		last := pages[len(pages)-1]
+		afterTime, afterID = last.FetchedAt, last.ID
+	}
+}
+
+// archivedPagesAfter lee el siguiente lote de páginas del proveedor posteriores a (afterTime, afterID).
+func (r *archiveRepository) archivedPagesAfter(ctx context.Context, source string, afterTime time.Time, afterID string) ([]domain.ArchivedPage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT `+archivedPageColumns+`
+        FROM raw_pages
+        WHERE source = $1 AND (fetched_at > $2 OR (fetched_at = $2 AND id > $3::UUID))
+        ORDER BY fetched_at, id
+        LIMIT $4`, source, afterTime, afterID, archivedPageBatch)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando páginas archivadas: %v", err)
+	}
+	defer rows.Close()
+
+	var pages []domain.ArchivedPage
+	for rows.Next() {
+		page, err := scanArchivedPage(rows)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return pages, nil
+}
+
+// DeleteArchivedPagesBefore elimina las páginas del proveedor cuya última recepción es anterior a before.
+func (r *archiveRepository) DeleteArchivedPagesBefore(ctx context.Context, source string, before time.Time) (int64, error) {
+	deleted, err := deleteInBatches(ctx, r.db, `
+        DELETE FROM raw_pages WHERE source = $1 AND fetched_at < $2`, source, before)
+	if err != nil {
+		return deleted, fmt.Errorf("error eliminando páginas archivadas: %v", err)
+	}
+	return deleted, nil
+}
+
+// scanArchivedPage escanea una fila con las columnas de archivedPageColumns y descomprime el cuerpo.
+func scanArchivedPage(row rowScanner) (domain.ArchivedPage, error) {
+	var page domain.ArchivedPage
+	var syncRunID sql.NullString
+	var encoding string
+	var body []byte
+	err := row.Scan(&page.ID, &page.Source, &syncRunID, &page.PageToken, &page.URL, &page.StatusCode, &page.ContentType,
+		&encoding, &body, &page.Fingerprint, &page.FirstFetchedAt, &page.FetchedAt, &page.Fetches)
+	if err != nil {
+		return page, fmt.Errorf("error escaneando página archivada: %v", err)
+	}
+	page.SyncRunID = syncRunID.String
+
+	switch encoding {
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return page, fmt.Errorf("error descomprimiendo página archivada %s: %v", page.ID, err)
+		}
+		if page.Body, err = io.ReadAll(zr); err != nil {
+			return page, fmt.Errorf("error descomprimiendo página archivada %s: %v", page.ID, err)
+		}
+	case "identity":
+		page.Body = body
+	default:
+		return page, fmt.Errorf("página archivada %s con codificación desconocida %q", page.ID, encoding)
+	}
+	return page, nil
+}
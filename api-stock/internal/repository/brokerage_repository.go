@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// brokerageKeySQL replica domain.BrokerageKey en SQL para comparar columnas de texto con alias_key.
+const brokerageKeySQL = `trim(regexp_replace(translate(lower(regexp_replace(%s, '[.,''()&]+', ' ', 'g')), 'áéíóú', 'aeiou'), '[\s\-_/]+', ' ', 'g'))`
+
+// brokerageRepository implementa domain.BrokerageRepository sobre las tablas brokerages,
+// brokerage_aliases y brokerage_unresolved.
+type brokerageRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewBrokerageRepository crea el repositorio del registro maestro de firmas de corretaje.
+func NewBrokerageRepository(db *sql.DB) domain.BrokerageRepository {
+	return &brokerageRepository{db: db}
+}
+
+// ListBrokerages devuelve todas las firmas ordenadas por nombre, cada una con sus alias.
+func (r *brokerageRepository) ListBrokerages(ctx context.Context) ([]domain.Brokerage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT b.id, b.name, b.reputation_weight, b.created_at, b.updated_at, a.alias
+        FROM brokerages AS b
+        LEFT JOIN brokerage_aliases AS a ON a.brokerage_id = b.id
+        ORDER BY b.name, b.id, a.alias`)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando firmas de corretaje: %v", err)
+	}
+	defer rows.Close()
+
+	var brokerages []domain.Brokerage
+	for rows.Next() {
+		var b domain.Brokerage
+		var alias sql.NullString
+		if err := rows.Scan(&b.ID, &b.Name, &b.ReputationWeight, &b.CreatedAt, &b.UpdatedAt, &alias); err != nil {
+			return nil, fmt.Errorf("error escaneando firma de corretaje: %v", err)
+		}
+		// Las filas llegan agrupadas por firma; se acumulan los alias en la última
+		if n := len(brokerages); n == 0 || brokerages[n-1].ID != b.ID {
+			b.Aliases = []string{}
+			brokerages = append(brokerages, b)
+		}
+		if alias.Valid {
+			last := &brokerages[len(brokerages)-1]
+			last.Aliases = append(last.Aliases, alias.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return brokerages, nil
+}
+
+// GetBrokerage obtiene una firma con sus alias, o domain.ErrBrokerageNotFound.
+func (r *brokerageRepository) GetBrokerage(ctx context.Context, id string) (*domain.Brokerage, error) {
+	var b domain.Brokerage
+	err := r.db.QueryRowContext(ctx, `
+        SELECT id, name, reputation_weight, created_at, updated_at
+        FROM brokerages WHERE id = $1`, id).
+		Scan(&b.ID, &b.Name, &b.ReputationWeight, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrBrokerageNotFound
+		}
+		return nil, fmt.Errorf("error consultando firma de corretaje: %v", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT alias FROM brokerage_aliases WHERE brokerage_id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando alias: %v", err)
+	}
+	defer rows.Close()
+
+	b.Aliases = []string{}
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("error escaneando alias: %v", err)
+		}
+		b.Aliases = append(b.Aliases, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	sort.Strings(b.Aliases)
+	return &b, nil
+}
+
+// SaveBrokerage crea la firma o actualiza su nombre y peso de reputación.
+func (r *brokerageRepository) SaveBrokerage(ctx context.Context, b domain.Brokerage) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO brokerages (id, name, reputation_weight)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (id) DO UPDATE SET
+            name = EXCLUDED.name,
+            reputation_weight = EXCLUDED.reputation_weight,
+            updated_at = now()`,
+		b.ID, b.Name, b.ReputationWeight)
+	if err != nil {
+		return fmt.Errorf("error guardando firma de corretaje: %v", err)
+	}
+	return nil
+}
+
+// AddAlias asigna el alias a la firma y, en la misma transacción, enlaza las recomendaciones
+// sin resolver cuyo nombre coincide y las quita del informe de no resueltos.
+func (r *brokerageRepository) AddAlias(ctx context.Context, id, alias string) error {
+	key := domain.BrokerageKey(alias)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback() // Rollback automático en caso de error
+
+	var owner string
+	err = tx.QueryRowContext(ctx, `SELECT brokerage_id FROM brokerage_aliases WHERE alias_key = $1`, key).Scan(&owner)
+	switch {
+	case err == sql.ErrNoRows:
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO brokerage_aliases (alias_key, alias, brokerage_id) VALUES ($1, $2, $3)`,
+			key, alias, id); err != nil {
+			return fmt.Errorf("error guardando alias: %v", err)
+		}
+	case err != nil:
+		return fmt.Errorf("error consultando alias: %v", err)
+	case owner != id:
+		return fmt.Errorf("%w: %q pertenece a %s", domain.ErrAliasConflict, alias, owner)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+        UPDATE recommendations SET brokerage_id = $1
+        WHERE brokerage_id IS NULL AND `+brokerageKeySQL+` = $2`, "brokerage"), id, key); err != nil {
+		return fmt.Errorf("error enlazando recomendaciones: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+        DELETE FROM brokerage_unresolved WHERE `+brokerageKeySQL+` = $1`, "name"), key); err != nil {
+		return fmt.Errorf("error limpiando nombres no resueltos: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// RemoveAlias elimina el alias de la firma. Las recomendaciones ya enlazadas conservan su ID
+// hasta la siguiente sincronización.
+func (r *brokerageRepository) RemoveAlias(ctx context.Context, id, alias string) error {
+	_, err := r.db.ExecContext(ctx, `
+        DELETE FROM brokerage_aliases WHERE alias_key = $1 AND brokerage_id = $2`,
+		domain.BrokerageKey(alias), id)
+	if err != nil {
+		return fmt.Errorf("error eliminando alias: %v", err)
+	}
+	return nil
+}
+
+// RecordUnresolved acumula las apariciones de cada nombre no resuelto.
+func (r *brokerageRepository) RecordUnresolved(ctx context.Context, counts map[string]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	// Orden determinista para que lotes concurrentes tomen los locks de fila en el mismo orden
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	valueStrings := make([]string, 0, len(names))
+	valueArgs := make([]interface{}, 0, len(names)*4)
+	for i, name := range names {
+		valueStrings = append(valueStrings, rowPlaceholders(i, 4))
+		valueArgs = append(valueArgs, name, counts[name], now, now)
+	}
+
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf(`
+        INSERT INTO brokerage_unresolved (name, occurrences, first_seen, last_seen)
+        VALUES %s
+        ON CONFLICT (name) DO UPDATE SET
+            occurrences = brokerage_unresolved.occurrences + EXCLUDED.occurrences,
+            last_seen = EXCLUDED.last_seen`, strings.Join(valueStrings, ",")), valueArgs...)
+	if err != nil {
+		return fmt.Errorf("error registrando nombres no resueltos: %v", err)
+	}
+	return nil
+}
+
+// ListUnresolved devuelve los nombres no resueltos de más a menos frecuente.
+func (r *brokerageRepository) ListUnresolved(ctx context.Context, limit int) ([]domain.UnresolvedBrokerage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT name, occurrences, first_seen, last_seen
+        FROM brokerage_unresolved
+        ORDER BY occurrences DESC, name
+        LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando nombres no resueltos: %v", err)
+	}
+	defer rows.Close()
+
+	unresolved := []domain.UnresolvedBrokerage{}
+	for rows.Next() {
+		var u domain.UnresolvedBrokerage
+		if err := rows.Scan(&u.Name, &u.Occurrences, &u.FirstSeen, &u.LastSeen); err != nil {
+			return nil, fmt.Errorf("error escaneando nombre no resuelto: %v", err)
+		}
+		unresolved = append(unresolved, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return unresolved, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// checkpointRepository implementa domain.CheckpointRepository sobre la tabla sync_checkpoints.
+type checkpointRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewCheckpointRepository crea el repositorio de checkpoints de sincronización.
+func NewCheckpointRepository(db *sql.DB) domain.CheckpointRepository {
+	return &checkpointRepository{db: db}
+}
+
+// GetCheckpoint devuelve el checkpoint con ese nombre, o nil si no existe.
+func (r *checkpointRepository) GetCheckpoint(ctx context.Context, name string) (*domain.SyncCheckpoint, error) {
+	var cp domain.SyncCheckpoint
+	err := r.db.QueryRowContext(ctx, `
+        SELECT name, next_page, pages, records, started_at, updated_at
+        FROM sync_checkpoints WHERE name = $1`, name).
+		Scan(&cp.Name, &cp.NextPage, &cp.Pages, &cp.Records, &cp.StartedAt, &cp.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No hay sincronización en curso
+		}
+		return nil, fmt.Errorf("error consultando checkpoint: %v", err)
+	}
+	return &cp, nil
+}
+
+// SaveCheckpoint crea o actualiza el checkpoint; updated_at se fija con la hora de la base de datos.
+// Con un lease en el contexto, solo lo guarda mientras siga siendo de este trabajo.
+func (r *checkpointRepository) SaveCheckpoint(ctx context.Context, cp domain.SyncCheckpoint) error {
+	err := inFencedTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+        INSERT INTO sync_checkpoints (name, next_page, pages, records, started_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, now())
+        ON CONFLICT (name) DO UPDATE SET
+            next_page = EXCLUDED.next_page,
+            pages = EXCLUDED.pages,
+            records = EXCLUDED.records,
+            updated_at = now()`,
+			cp.Name, cp.NextPage, cp.Pages, cp.Records, cp.StartedAt)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteCheckpoint elimina el checkpoint con ese nombre, con la misma comprobación del lease que SaveCheckpoint.
+func (r *checkpointRepository) DeleteCheckpoint(ctx context.Context, name string) error {
+	err := inFencedTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM sync_checkpoints WHERE name = $1`, name)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error eliminando checkpoint: %w", err)
+	}
+	return nil
+}
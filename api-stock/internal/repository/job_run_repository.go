@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// jobRunColumns es la lista de columnas que se leen en cada consulta de ejecuciones, en el orden de scanJobRun.
+const jobRunColumns = `id, job, kind, triggered_by, status, started_at, finished_at, error`
+
+// jobRunRepository implementa domain.JobRunRepository sobre la tabla job_runs.
+type jobRunRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewJobRunRepository crea el repositorio del historial de trabajos del worker.
+func NewJobRunRepository(db *sql.DB) domain.JobRunRepository {
+	return &jobRunRepository{db: db}
+}
+
+// StartJobRun registra la ejecución y completa su ID y, si está vacía, su fecha de inicio.
+// Una ejecución omitida se registra ya terminada.
+func (r *jobRunRepository) StartJobRun(ctx context.Context, run *domain.JobRun) error {
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	if run.Status == "" {
+		run.Status = domain.JobRunRunning
+	}
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO job_runs (job, kind, triggered_by, status, started_at, finished_at, error)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id`,
+		run.Job, run.Kind, string(run.Trigger), string(run.Status), run.StartedAt, run.FinishedAt, nullString(run.Error)).
+		Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("error registrando ejecución del trabajo %s: %v", run.Job, err)
+	}
+	return nil
+}
+
+// FinishJobRun guarda el estado final y el error de la ejecución.
+func (r *jobRunRepository) FinishJobRun(ctx context.Context, run domain.JobRun) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE job_runs SET status = $2, finished_at = $3, error = $4
+        WHERE id = $1`,
+		run.ID, string(run.Status), run.FinishedAt, nullString(run.Error))
+	if err != nil {
+		return fmt.Errorf("error finalizando ejecución del trabajo %s: %v", run.Job, err)
+	}
+	return nil
+}
+
+// ListJobRuns devuelve las ejecuciones más recientes primero, de un trabajo o de todos.
+func (r *jobRunRepository) ListJobRuns(ctx context.Context, job string, limit int) ([]domain.JobRun, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT `+jobRunColumns+`
+        FROM job_runs
+        WHERE $1 = '' OR job = $1
+        ORDER BY started_at DESC, id
+        LIMIT $2`, job, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando ejecuciones de trabajos: %v", err)
+	}
+	defer rows.Close()
+
+	runs := []domain.JobRun{}
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return runs, nil
+}
+
+// DeleteJobRunsBefore elimina las ejecuciones terminadas que empezaron antes de before.
+func (r *jobRunRepository) DeleteJobRunsBefore(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := deleteInBatches(ctx, r.db, `
+        DELETE FROM job_runs WHERE started_at < $1 AND status != $2`,
+		before, string(domain.JobRunRunning))
+	if err != nil {
+		return deleted, fmt.Errorf("error eliminando ejecuciones de trabajos: %v", err)
+	}
+	return deleted, nil
+}
+
+// scanJobRun escanea una fila con las columnas de jobRunColumns.
+func scanJobRun(row rowScanner) (domain.JobRun, error) {
+	var run domain.JobRun
+	var finishedAt sql.NullTime
+	var runError sql.NullString
+	err := row.Scan(&run.ID, &run.Job, &run.Kind, &run.Trigger, &run.Status, &run.StartedAt, &finishedAt, &runError)
+	if err != nil {
+		return run, fmt.Errorf("error escaneando ejecución de trabajo: %v", err)
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	run.Error = runError.String
+	return run, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// leaseRepository implementa domain.LeaseRepository sobre la tabla leases.
+// Los vencimientos se calculan con el reloj de la base de datos, común a todos los procesos.
+type leaseRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewLeaseRepository crea el repositorio de leases.
+func NewLeaseRepository(db *sql.DB) domain.LeaseRepository {
+	return &leaseRepository{db: db}
+}
+
+// AcquireLease toma el lease si está libre o vencido e incrementa su token.
+func (r *leaseRepository) AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (*domain.Lease, error) {
+	var lease domain.Lease
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO leases (name, owner, acquired_at, renewed_at, expires_at, token)
+        VALUES ($1, $2, now(), now(), now() + $3::INT8 * INTERVAL '1 millisecond', 1)
+        ON CONFLICT (name) DO UPDATE SET
+            owner = EXCLUDED.owner,
+            acquired_at = EXCLUDED.acquired_at,
+            renewed_at = EXCLUDED.renewed_at,
+            expires_at = EXCLUDED.expires_at,
+            token = leases.token + 1
+        WHERE leases.expires_at <= now()
+        RETURNING name, owner, acquired_at, renewed_at, expires_at, token`,
+		name, owner, ttl.Milliseconds()).
+		Scan(&lease.Name, &lease.Owner, &lease.AcquiredAt, &lease.RenewedAt, &lease.ExpiresAt, &lease.Token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Lo tiene otro proceso
+		}
+		return nil, fmt.Errorf("error tomando lease %s: %v", name, err)
+	}
+	return &lease, nil
+}
+
+// RenewLease extiende el vencimiento mientras el lease siga siendo de owner, aunque haya vencido sin que
+// otro proceso lo tomara.
+func (r *leaseRepository) RenewLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE leases SET renewed_at = now(), expires_at = now() + $3::INT8 * INTERVAL '1 millisecond'
+        WHERE name = $1 AND owner = $2`,
+		name, owner, ttl.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("error renovando lease %s: %v", name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error renovando lease %s: %v", name, err)
+	}
+	return n == 1, nil
+}
+
+// ReleaseLease libera el lease si sigue siendo de owner. La fila se vence en lugar de borrarse para
+// conservar el token.
+func (r *leaseRepository) ReleaseLease(ctx context.Context, name, owner string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE leases SET expires_at = now() WHERE name = $1 AND owner = $2`, name, owner); err != nil {
+		return fmt.Errorf("error liberando lease %s: %v", name, err)
+	}
+	return nil
+}
+
+// GetLease devuelve el lease vigente, o nil si está libre o vencido.
+func (r *leaseRepository) GetLease(ctx context.Context, name string) (*domain.Lease, error) {
+	var lease domain.Lease
+	err := r.db.QueryRowContext(ctx, `
+        SELECT name, owner, acquired_at, renewed_at, expires_at, token
+        FROM leases WHERE name = $1 AND expires_at > now()`, name).
+		Scan(&lease.Name, &lease.Owner, &lease.AcquiredAt, &lease.RenewedAt, &lease.ExpiresAt, &lease.Token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error consultando lease %s: %v", name, err)
+	}
+	return &lease, nil
+}
+
+// checkLeaseFence comprueba, dentro de tx, que la toma del lease del contexto (domain.WithLeaseFence) sigue
+// siendo la vigente y bloquea la fila del lease hasta que tx termine, de modo que ningún otro proceso puede
+// tomarlo antes del commit. Devuelve un error que envuelve domain.ErrLeaseLost si otro lo tomó después.
+// Sin lease en el contexto no comprueba nada.
+func checkLeaseFence(ctx context.Context, tx *sql.Tx) error {
+	fence, ok := domain.LeaseFenceFrom(ctx)
+	if !ok {
+		return nil
+	}
+
+	var owner string
+	var token int64
+	err := tx.QueryRowContext(ctx, `SELECT owner, token FROM leases WHERE name = $1 FOR UPDATE`, fence.Name).
+		Scan(&owner, &token)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: el lease %s ya no existe", domain.ErrLeaseLost, fence.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("error comprobando lease %s: %v", fence.Name, err)
+	}
+	if owner != fence.Owner || token != fence.Token {
+		return fmt.Errorf("%w: %s tiene el lease %s con el token %d (este trabajo tenía el %d)",
+			domain.ErrLeaseLost, owner, fence.Name, token, fence.Token)
+	}
+	return nil
+}
+
+// inFencedTx ejecuta fn en una transacción que antes comprueba, con checkLeaseFence, la toma del lease del
+// contexto. Así las escrituras del trabajo que tiene el lease (checkpoint, historial, archivo, cuarentena)
+// no se confirman si otro proceso lo tomó después.
+func inFencedTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback() // Rollback automático en caso de error
+
+	if err := checkLeaseFence(ctx, tx); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
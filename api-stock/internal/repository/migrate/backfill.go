@@ -0,0 +1,126 @@
+package migrate
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// goStepPrefix marca en un script la línea que ejecuta un paso escrito en Go ("-- migrate:go <nombre>").
+// El paso se ejecuta en la posición de la línea, entre las sentencias SQL anterior y siguiente.
+const goStepPrefix = "-- migrate:go "
+
+// goSteps son los pasos de migración que no se pueden expresar con fidelidad en SQL, por nombre.
+var goSteps = map[string]func(ctx context.Context, db *sql.DB) error{
+	"backfill_price_targets": backfillPriceTargets,
+}
+
+// backfillBatchSize es el número de filas que cada paso en Go actualiza por transacción.
+const backfillBatchSize = 500
+
+// maxNumericCents es el primer importe en centavos que no cabe en NUMERIC(14,2).
+const maxNumericCents = 100_000_000_000_000
+
+// goStepName devuelve el nombre del paso en Go si la sentencia es una línea "-- migrate:go <nombre>".
+func goStepName(stmt string) (string, bool) {
+	name, ok := strings.CutPrefix(stmt, goStepPrefix)
+	return strings.TrimSpace(name), ok
+}
+
+// backfillPriceTargets rellena target_from_amount, target_to_amount y currency (migración 0002) a partir
+// de los precios en texto, con las mismas reglas que domain.ParseMoney: símbolos y códigos de divisa
+// antes o después del importe, negativos entre paréntesis y separadores decimales y de miles de
+// cualquiera de los dos estilos ("$1,234.50", "1.234,50 €"). Los valores que no se pueden interpretar
+// quedan en NULL. Recorre la tabla por su clave (ticker, time) en lotes.
+func backfillPriceTargets(ctx context.Context, db *sql.DB) error {
+	var lastTicker string
+	var lastTime time.Time
+	first := true
+	for {
+		query := `SELECT ticker, time, target_from::STRING, target_to::STRING FROM recommendations
+			ORDER BY ticker, time LIMIT $1`
+		args := []interface{}{backfillBatchSize}
+		if !first {
+			query = `SELECT ticker, time, target_from::STRING, target_to::STRING FROM recommendations
+				WHERE (ticker, time) > ($2, $3) ORDER BY ticker, time LIMIT $1`
+			args = append(args, lastTicker, lastTime)
+		}
+
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("error leyendo precios objetivo: %v", err)
+		}
+		type priceRow struct {
+			ticker   string
+			time     time.Time
+			from, to sql.NullString
+		}
+		var batch []priceRow
+		for rows.Next() {
+			var row priceRow
+			if err := rows.Scan(&row.ticker, &row.time, &row.from, &row.to); err != nil {
+				rows.Close()
+				return fmt.Errorf("error escaneando precios objetivo: %v", err)
+			}
+			batch = append(batch, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error después de iterar filas: %v", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("error iniciando transacción: %v", err)
+		}
+		for _, row := range batch {
+			from, to, currency := parsePriceTargets(row.from, row.to)
+			_, err := tx.ExecContext(ctx, `UPDATE recommendations
+				SET target_from_amount = $1, target_to_amount = $2, currency = $3
+				WHERE ticker = $4 AND time = $5`, from, to, currency, row.ticker, row.time)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("error actualizando precios objetivo de %s: %v", row.ticker, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error confirmando precios objetivo: %v", err)
+		}
+
+		last := batch[len(batch)-1]
+		lastTicker, lastTime, first = last.ticker, last.time, false
+	}
+}
+
+// parsePriceTargets interpreta los precios objetivo en texto de una fila. La divisa es la del precio
+// final si se pudo interpretar, si no la del inicial, y si no domain.DefaultCurrency.
+func parsePriceTargets(from, to sql.NullString) (domain.Money, domain.Money, string) {
+	fromAmount, toAmount := parsePriceTarget(from), parsePriceTarget(to)
+	switch {
+	case toAmount.Valid:
+		return fromAmount, toAmount, toAmount.Currency
+	case fromAmount.Valid:
+		return fromAmount, toAmount, fromAmount.Currency
+	default:
+		return fromAmount, toAmount, domain.DefaultCurrency
+	}
+}
+
+// parsePriceTarget interpreta un precio objetivo; los valores inválidos o fuera del rango de
+// NUMERIC(14,2) devuelven un Money inválido (NULL).
+func parsePriceTarget(raw sql.NullString) domain.Money {
+	if !raw.Valid {
+		return domain.Money{}
+	}
+	money, err := domain.ParseMoney(raw.String)
+	if err != nil || money.Cents >= maxNumericCents || money.Cents <= -maxNumericCents {
+		return domain.Money{}
+	}
+	return money
+}
@@ -0,0 +1,44 @@
+package migrate
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestParsePriceTargets(t *testing.T) {
+	null := sql.NullString{}
+	text := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+
+	tests := []struct {
+		name         string
+		from, to     sql.NullString
+		wantFrom     string
+		wantTo       string
+		wantCurrency string
+	}{
+		{"dólares con miles", text("$1,234.50"), text("$1,300.00"), "1234.50", "1300.00", "USD"},
+		{"coma decimal", text("€99,90"), text("€105,00"), "99.90", "105.00", "EUR"},
+		{"estilo europeo con símbolo al final", text("1.234,50 €"), text("1.300,00 €"), "1234.50", "1300.00", "EUR"},
+		{"negativo entre paréntesis", text("(12.00)"), text("$15.00"), "-12.00", "15.00", "USD"},
+		{"dólares australianos", text("A$10.00"), text("A$12.00"), "10.00", "12.00", "AUD"},
+		{"reales", text("R$ 25,50"), text("R$ 30,00"), "25.50", "30.00", "BRL"},
+		{"dólares estadounidenses explícitos", text("US$5"), text("US$6"), "5.00", "6.00", "USD"},
+		{"yenes", text("¥1,500"), text("¥1,800"), "1500.00", "1800.00", "JPY"},
+		{"código ISO", text("150 CHF"), text("CHF 160"), "150.00", "160.00", "CHF"},
+		{"la divisa del precio final tiene prioridad", text("$10"), text("€12"), "10.00", "12.00", "EUR"},
+		{"sin precio final", text("£8.00"), null, "8.00", "", "GBP"},
+		{"valores numéricos", text("150.00"), text("160.5"), "150.00", "160.50", "USD"},
+		{"inválidos y vacíos quedan en NULL", text("abc"), text("N/A"), "", "", "USD"},
+		{"fuera de rango de NUMERIC(14,2)", text("1000000000000"), text("999999999999.99"), "", "999999999999.99", "USD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, currency := parsePriceTargets(tt.from, tt.to)
+			if from.String() != tt.wantFrom || to.String() != tt.wantTo || currency != tt.wantCurrency {
+				t.Errorf("parsePriceTargets(%q, %q) = %q, %q, %q; want %q, %q, %q", tt.from.String, tt.to.String,
+					from.String(), to.String(), currency, tt.wantFrom, tt.wantTo, tt.wantCurrency)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package migrate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// ensureTables crea las tablas de control de migraciones si no existen.
+func (m *Migrator) ensureTables(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT8 PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			dirty BOOL NOT NULL DEFAULT false,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+		`CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+			id INT8 PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+			owner VARCHAR(100) NOT NULL,
+			acquired_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+	}
+	for _, query := range queries {
+		if _, err := m.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("error creando tablas de migraciones: %v", err)
+		}
+	}
+	return nil
+}
+
+// errLockLost indica que el lock de migraciones dejó de ser de este proceso mientras se migraba.
+var errLockLost = errors.New("se perdió el lock de migraciones")
+
+// withLock ejecuta fn mientras mantiene el lock de migraciones.
+// Si otro proceso tiene el lock, espera hasta LockTimeout; los locks más antiguos que LockTTL
+// se consideran abandonados (proceso caído) y se liberan. Mientras fn se ejecuta, el lock se renueva
+// cada LockTTL/3 para que una migración larga no parezca abandonada; si se pierde, se cancela el
+// contexto de fn y withLock devuelve errLockLost.
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := m.ensureTables(ctx); err != nil {
+		return err
+	}
+
+	owner := lockOwner()
+	if err := m.acquireLock(ctx, owner); err != nil {
+		return err
+	}
+	defer func() {
+		// Se usa un contexto nuevo para liberar el lock aunque ctx se haya cancelado
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := m.db.ExecContext(releaseCtx, `DELETE FROM schema_migrations_lock WHERE id = 1 AND owner = $1`, owner); err != nil {
+			log.Printf("Error liberando lock de migraciones: %v", err)
+		}
+	}()
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		m.heartbeat(lockCtx, owner, cancel)
+	}()
+
+	err := fn(lockCtx)
+	cancel(nil)
+	<-heartbeatDone
+	if cause := context.Cause(lockCtx); errors.Is(cause, errLockLost) {
+		return cause // La migración se interrumpió por perder el lock; su error es consecuencia de la cancelación
+	}
+	return err
+}
+
+// heartbeat renueva acquired_at cada LockTTL/3 hasta que termine fn. Si el lock ya no es de owner, o no se
+// pudo renovar durante un LockTTL completo (y otro proceso pudo liberarlo), cancela fn con errLockLost.
+func (m *Migrator) heartbeat(ctx context.Context, owner string, cancel context.CancelCauseFunc) {
+	if m.LockTTL <= 0 {
+		return
+	}
+	ticker := time.NewTicker(m.LockTTL / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		res, err := m.db.ExecContext(ctx, `UPDATE schema_migrations_lock SET acquired_at = now() WHERE id = 1 AND owner = $1`, owner)
+		var n int64
+		if err == nil {
+			n, err = res.RowsAffected()
+		}
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return // fn terminó durante la renovación
+		case err != nil:
+			log.Printf("Error renovando lock de migraciones: %v", err)
+			if time.Since(renewed) >= m.LockTTL {
+				cancel(fmt.Errorf("%w: sin renovar desde %s", errLockLost, renewed.Format(time.RFC3339)))
+				return
+			}
+		case n == 0:
+			cancel(fmt.Errorf("%w: otro proceso lo tomó", errLockLost))
+			return
+		default:
+			renewed = time.Now()
+		}
+	}
+}
+
+// acquireLock intenta tomar el lock de migraciones hasta obtenerlo o agotar LockTimeout.
+func (m *Migrator) acquireLock(ctx context.Context, owner string) error {
+	ctx, cancel := context.WithTimeout(ctx, m.LockTimeout)
+	defer cancel()
+
+	for {
+		// Libera locks abandonados por procesos que murieron durante una migración
+		_, err := m.db.ExecContext(ctx, `DELETE FROM schema_migrations_lock
+			WHERE id = 1 AND acquired_at < now() - $1::INT8 * INTERVAL '1 second'`, int64(m.LockTTL.Seconds()))
+		if err != nil {
+			return fmt.Errorf("error limpiando lock de migraciones: %v", err)
+		}
+
+		res, err := m.db.ExecContext(ctx, `INSERT INTO schema_migrations_lock (id, owner, acquired_at)
+			VALUES (1, $1, now()) ON CONFLICT (id) DO NOTHING`, owner)
+		if err != nil {
+			return fmt.Errorf("error obteniendo lock de migraciones: %v", err)
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			return nil
+		}
+
+		log.Println("Otro proceso está ejecutando migraciones, esperando...")
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("tiempo de espera agotado para el lock de migraciones: %v", ctx.Err())
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// lockOwner genera un identificador único del proceso para registrar quién tiene el lock.
+func lockOwner() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,468 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles contiene las migraciones numeradas embebidas en el binario.
+// Cada versión tiene un archivo NNNN_nombre.up.sql y su reverso NNNN_nombre.down.sql.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationFileName reconoce nombres como 0002_numeric_price_targets.up.sql.
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration representa una versión del esquema con sus sentencias de subida y bajada.
+type Migration struct {
+	Version int64  // Número de versión (prefijo del archivo)
+	Name    string // Nombre descriptivo de la migración
+	Up      string // SQL para aplicar la migración
+	Down    string // SQL para revertir la migración
+}
+
+// MigrationStatus describe el estado de una migración en la base de datos.
+type MigrationStatus struct {
+	Migration
+	Applied   bool       // Indica si la migración está aplicada
+	Dirty     bool       // Indica si la migración falló a medias y requiere intervención manual
+	AppliedAt *time.Time // Momento en que se aplicó (nil si no está aplicada)
+}
+
+// Migrator aplica y revierte migraciones sobre la base de datos, registrándolas en schema_migrations.
+// Usa un lock en base de datos para que varios procesos (cmd/api, cmd/worker) no migren a la vez.
+type Migrator struct {
+	db          *sql.DB
+	migrations  []Migration
+	LockTimeout time.Duration // Tiempo máximo de espera para obtener el lock de migraciones
+	LockTTL     time.Duration // Antigüedad a partir de la cual un lock se considera abandonado
+}
+
+// New crea un Migrator con las migraciones embebidas en el binario.
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:          db,
+		migrations:  migrations,
+		LockTimeout: 2 * time.Minute,
+		LockTTL:     10 * time.Minute,
+	}, nil
+}
+
+// loadMigrations lee y valida las migraciones del sistema de archivos, ordenadas por versión.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo migraciones: %v", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, "migrations/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo migración %s: %v", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("versión %d duplicada: %s y %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("la migración %04d_%s debe tener archivos up y down", m.Version, m.Name)
+		}
+		for _, script := range []string{m.Up, m.Down} {
+			for _, stmt := range splitStatements(script) {
+				if name, ok := goStepName(stmt); ok && goSteps[name] == nil {
+					return nil, fmt.Errorf("la migración %04d_%s usa el paso en Go desconocido %q", m.Version, m.Name, name)
+				}
+			}
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Latest devuelve la versión más alta disponible en el binario.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up aplica todas las migraciones pendientes.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.Goto(ctx, m.Latest())
+}
+
+// Down revierte las últimas `steps` migraciones aplicadas.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("el número de pasos debe ser mayor que cero")
+	}
+	return m.withLock(ctx, func(ctx context.Context) error {
+		current, err := m.currentVersion(ctx)
+		if err != nil {
+			return err
+		}
+		target := current
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			if m.migrations[i].Version <= current {
+				target = m.versionBefore(m.migrations[i].Version)
+				steps--
+			}
+		}
+		if err := m.migrateTo(ctx, current, target); err != nil {
+			return err
+		}
+		return m.verifyMirroredTables(ctx)
+	})
+}
+
+// Goto lleva el esquema a la versión indicada, aplicando o revirtiendo migraciones según corresponda,
+// y comprueba que las tablas de mirroredTables siguen teniendo las columnas de su original.
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("la versión %d no existe", version)
+	}
+	return m.withLock(ctx, func(ctx context.Context) error {
+		current, err := m.currentVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.migrateTo(ctx, current, version); err != nil {
+			return err
+		}
+		return m.verifyMirroredTables(ctx)
+	})
+}
+
+// Force marca la versión indicada como aplicada y limpia el estado dirty, sin ejecutar SQL.
+// Se usa tras reparar a mano una migración que falló a medias.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("la versión %d no existe", version)
+	}
+	return m.withLock(ctx, func(ctx context.Context) error {
+		if _, err := m.db.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version > $1 OR dirty`, version); err != nil {
+			return fmt.Errorf("error forzando versión: %v", err)
+		}
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			_, err := m.db.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, dirty)
+				VALUES ($1, $2, false) ON CONFLICT (version) DO NOTHING`, mig.Version, mig.Name)
+			if err != nil {
+				return fmt.Errorf("error forzando versión %d: %v", mig.Version, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Status devuelve el estado de cada migración conocida por el binario.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTables(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, `SELECT version, dirty, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando schema_migrations: %v", err)
+	}
+	defer rows.Close()
+
+	type appliedRow struct {
+		dirty     bool
+		appliedAt time.Time
+	}
+	applied := make(map[int64]appliedRow)
+	for rows.Next() {
+		var version int64
+		var row appliedRow
+		if err := rows.Scan(&version, &row.dirty, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando schema_migrations: %v", err)
+		}
+		applied[version] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Migration: mig}
+		if row, ok := applied[mig.Version]; ok {
+			appliedAt := row.appliedAt
+			status.Applied = !row.dirty
+			status.Dirty = row.dirty
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// migrateTo aplica o revierte migraciones en orden desde la versión actual hasta la objetivo.
+func (m *Migrator) migrateTo(ctx context.Context, current, target int64) error {
+	if target > current {
+		for _, mig := range m.migrations {
+			if mig.Version > current && mig.Version <= target {
+				if err := m.apply(ctx, mig, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Version <= current && mig.Version > target {
+			if err := m.apply(ctx, mig, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// apply ejecuta una migración en la dirección indicada y actualiza schema_migrations.
+// Las sentencias se ejecutan una a una y fuera de transacción: CockroachDB no permite usar en la misma
+// transacción una columna recién añadida. Por eso la versión se marca como dirty mientras se ejecuta,
+// y si falla queda así hasta que se repare y se use Force.
+func (m *Migrator) apply(ctx context.Context, mig Migration, up bool) error {
+	direction, script := "up", mig.Up
+	if !up {
+		direction, script = "down", mig.Down
+	}
+	log.Printf("Migración %04d_%s (%s)", mig.Version, mig.Name, direction)
+
+	_, err := m.db.ExecContext(ctx, `UPSERT INTO schema_migrations (version, name, dirty, applied_at)
+		VALUES ($1, $2, true, now())`, mig.Version, mig.Name)
+	if err != nil {
+		return fmt.Errorf("error registrando migración %d: %v", mig.Version, err)
+	}
+
+	for _, stmt := range splitStatements(script) {
+		if name, ok := goStepName(stmt); ok {
+			if err := goSteps[name](ctx, m.db); err != nil {
+				return fmt.Errorf("error en migración %04d_%s (%s), paso %s: %v", mig.Version, mig.Name, direction, name, err)
+			}
+			continue
+		}
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("error en migración %04d_%s (%s): %v", mig.Version, mig.Name, direction, err)
+		}
+	}
+
+	if up {
+		_, err = m.db.ExecContext(ctx, `UPDATE schema_migrations SET dirty = false, applied_at = now() WHERE version = $1`, mig.Version)
+	} else {
+		_, err = m.db.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("error registrando migración %d: %v", mig.Version, err)
+	}
+	return nil
+}
+
+// currentVersion devuelve la versión aplicada más alta, o un error si alguna quedó dirty.
+func (m *Migrator) currentVersion(ctx context.Context) (int64, error) {
+	var dirty int64
+	err := m.db.QueryRowContext(ctx, `SELECT version FROM schema_migrations WHERE dirty ORDER BY version LIMIT 1`).Scan(&dirty)
+	if err == nil {
+		return 0, fmt.Errorf("la migración %d quedó a medias (dirty); repárela y ejecute 'migrate force <versión>'", dirty)
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("error consultando schema_migrations: %v", err)
+	}
+
+	var version sql.NullInt64
+	if err := m.db.QueryRowContext(ctx, `SELECT max(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("error consultando versión actual: %v", err)
+	}
+	return version.Int64, nil
+}
+
+// versionBefore devuelve la versión inmediatamente anterior a la dada (0 si es la primera).
+func (m *Migrator) versionBefore(version int64) int64 {
+	prev := int64(0)
+	for _, mig := range m.migrations {
+		if mig.Version >= version {
+			break
+		}
+		prev = mig.Version
+	}
+	return prev
+}
+
+// find busca una migración por versión.
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// splitStatements separa un script en sentencias individuales.
+// Una sentencia termina en un ';' que no está dentro de una cadena ('...', E'...'), un identificador
+// entre comillas ("..."), un bloque $$...$$ o $etiqueta$...$etiqueta$, ni un comentario. Los comentarios
+// (-- y /* */, que pueden anidarse) se eliminan de las sentencias, salvo las líneas "-- migrate:go <nombre>",
+// que se devuelven como una sentencia más para ejecutar el paso en Go correspondiente.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch {
+		case c == ';':
+			flush()
+			i++
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			if comment := script[i : i+end]; strings.HasPrefix(comment, goStepPrefix) {
+				flush() // El paso en Go es una sentencia aparte, en su posición
+				statements = append(statements, strings.TrimSpace(comment))
+			}
+			i += end // El salto de línea se conserva como separador
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			i = skipBlockComment(script, i)
+			current.WriteByte(' ')
+		case c == '\'' || c == '"':
+			end := skipQuoted(script, i, c, c == '\'' && isEscapeString(script, i))
+			current.WriteString(script[i:end])
+			i = end
+		case c == '$' && dollarTag(script, i) != "":
+			tag := dollarTag(script, i)
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				end = len(script) - i // Bloque sin cerrar: el resto del script es parte de la sentencia
+			} else {
+				end += len(tag) + len(tag)
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+		default:
+			current.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+// skipQuoted devuelve la posición siguiente a la comilla que cierra la cadena o identificador que empieza
+// en start. La comilla duplicada es una comilla literal; en las cadenas E'...' también lo es \'.
+func skipQuoted(script string, start int, quote byte, backslashEscapes bool) int {
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(script)
+}
+
+// isEscapeString indica si la comilla en start abre una cadena E'...' (con escapes de barra invertida).
+func isEscapeString(script string, start int) bool {
+	if start == 0 || (script[start-1] != 'E' && script[start-1] != 'e') {
+		return false
+	}
+	return start == 1 || !isIdentChar(script[start-2])
+}
+
+// skipBlockComment devuelve la posición siguiente al cierre del comentario /* */ que empieza en start.
+func skipBlockComment(script string, start int) int {
+	depth := 0
+	for i := start; i < len(script)-1; i++ {
+		switch {
+		case script[i] == '/' && script[i+1] == '*':
+			depth++
+			i++
+		case script[i] == '*' && script[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(script)
+}
+
+// dollarTag devuelve la etiqueta ($$ o $nombre$) que abre un bloque en start, o "" si el '$' no abre uno
+// (por ejemplo un parámetro $1 o un '$' dentro de un identificador).
+func dollarTag(script string, start int) string {
+	if start > 0 && isIdentChar(script[start-1]) {
+		return ""
+	}
+	for i := start + 1; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '$':
+			return script[start : i+1]
+		case isIdentChar(c) && !(i == start+1 && c >= '0' && c <= '9'):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// isIdentChar indica si c puede formar parte de un identificador sin comillas.
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,172 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "sentencias en varias líneas",
+			script: "CREATE TABLE a (\n    id INT8\n);\n\nDROP TABLE b;\n",
+			want:   []string{"CREATE TABLE a (\n    id INT8\n)", "DROP TABLE b"},
+		},
+		{
+			name:   "varias sentencias en una línea",
+			script: "DELETE FROM a; DELETE FROM b;",
+			want:   []string{"DELETE FROM a", "DELETE FROM b"},
+		},
+		{
+			name:   "sin punto y coma final",
+			script: "SELECT 1",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "vacío y solo comentarios",
+			script: "-- nada que hacer;\n\n/* tampoco; */\n;\n",
+			want:   nil,
+		},
+		{
+			name:   "punto y coma al final de una línea dentro de una cadena",
+			script: "INSERT INTO a (v) VALUES ('uno;\ndos;');\nSELECT 1;",
+			want:   []string{"INSERT INTO a (v) VALUES ('uno;\ndos;')", "SELECT 1"},
+		},
+		{
+			name:   "comilla duplicada dentro de una cadena",
+			script: "SELECT 'it''s; fine';",
+			want:   []string{"SELECT 'it''s; fine'"},
+		},
+		{
+			name:   "cadena con escapes de barra invertida",
+			script: `SELECT E'a\';b'; SELECT 2;`,
+			want:   []string{`SELECT E'a\';b'`, "SELECT 2"},
+		},
+		{
+			name:   "barra invertida en una cadena normal",
+			script: `SELECT 'C:\'; SELECT 2;`,
+			want:   []string{`SELECT 'C:\'`, "SELECT 2"},
+		},
+		{
+			name:   "identificador entre comillas",
+			script: `CREATE TABLE "a;b" (id INT8); SELECT 1;`,
+			want:   []string{`CREATE TABLE "a;b" (id INT8)`, "SELECT 1"},
+		},
+		{
+			name:   "bloque $$",
+			script: "CREATE FUNCTION f() RETURNS INT8 AS $$\n    SELECT 1;\n$$ LANGUAGE SQL;\nSELECT f();",
+			want:   []string{"CREATE FUNCTION f() RETURNS INT8 AS $$\n    SELECT 1;\n$$ LANGUAGE SQL", "SELECT f()"},
+		},
+		{
+			name:   "bloque con etiqueta que contiene $$",
+			script: "SELECT $body$ a; $$ b; $body$; SELECT 2;",
+			want:   []string{"SELECT $body$ a; $$ b; $body$", "SELECT 2"},
+		},
+		{
+			name:   "parámetros posicionales no abren bloques",
+			script: "UPDATE a SET v = $1 WHERE id = $2; SELECT 2;",
+			want:   []string{"UPDATE a SET v = $1 WHERE id = $2", "SELECT 2"},
+		},
+		{
+			name:   "comentario de línea con punto y coma",
+			script: "SELECT 1 -- fin;\n+ 1;\nSELECT 2;",
+			want:   []string{"SELECT 1 \n+ 1", "SELECT 2"},
+		},
+		{
+			name:   "comentario de bloque anidado",
+			script: "SELECT /* uno; /* dos; */ tres; */ 1; SELECT 2;",
+			want:   []string{"SELECT   1", "SELECT 2"},
+		},
+		{
+			name:   "comentario dentro de una cadena",
+			script: "SELECT '-- no; /* es */ comentario';",
+			want:   []string{"SELECT '-- no; /* es */ comentario'"},
+		},
+		{
+			name:   "paso en Go",
+			script: "ALTER TABLE a ADD COLUMN b INT8;\n\n-- migrate:go backfill_b\n\nALTER TABLE a DROP COLUMN c;",
+			want:   []string{"ALTER TABLE a ADD COLUMN b INT8", "-- migrate:go backfill_b", "ALTER TABLE a DROP COLUMN c"},
+		},
+		{
+			name:   "paso en Go en medio de una sentencia sin terminar",
+			script: "SELECT 1\n-- migrate:go paso\nSELECT 2;",
+			want:   []string{"SELECT 1", "-- migrate:go paso", "SELECT 2"},
+		},
+		{
+			name:   "cadena sin cerrar",
+			script: "SELECT 'abierta; SELECT 2;",
+			want:   []string{"SELECT 'abierta; SELECT 2;"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements(%q)\n got %q\nwant %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrationsSplit(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	for _, mig := range migrations {
+		for direction, script := range map[string]string{"up": mig.Up, "down": mig.Down} {
+			if len(splitStatements(script)) == 0 {
+				t.Errorf("%04d_%s (%s) no tiene sentencias", mig.Version, mig.Name, direction)
+			}
+		}
+	}
+}
+
+func TestDiffColumns(t *testing.T) {
+	original := map[string]string{
+		"id":     "VARCHAR(32) NOT NULL",
+		"ticker": "VARCHAR(10) NULL",
+		"source": "VARCHAR(50) NOT NULL",
+	}
+
+	tests := []struct {
+		name   string
+		mirror map[string]string
+		want   []string
+	}{
+		{
+			name:   "iguales",
+			mirror: map[string]string{"id": "VARCHAR(32) NOT NULL", "ticker": "VARCHAR(10) NULL", "source": "VARCHAR(50) NOT NULL"},
+			want:   nil,
+		},
+		{
+			name:   "falta una columna",
+			mirror: map[string]string{"id": "VARCHAR(32) NOT NULL", "ticker": "VARCHAR(10) NULL"},
+			want:   []string{"falta source VARCHAR(50) NOT NULL"},
+		},
+		{
+			name: "sobra una columna y otra cambia de tipo",
+			mirror: map[string]string{
+				"id": "VARCHAR(32) NOT NULL", "ticker": "VARCHAR(20) NULL", "source": "VARCHAR(50) NOT NULL", "extra": "INT8 NULL",
+			},
+			want: []string{"sobra extra INT8 NULL", "ticker es VARCHAR(20) NULL en lugar de VARCHAR(10) NULL"},
+		},
+		{
+			name:   "nulabilidad distinta",
+			mirror: map[string]string{"id": "VARCHAR(32) NOT NULL", "ticker": "VARCHAR(10) NULL", "source": "VARCHAR(50) NULL"},
+			want:   []string{"source es VARCHAR(50) NULL en lugar de VARCHAR(50) NOT NULL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffColumns(original, tt.mirror); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffColumns()\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
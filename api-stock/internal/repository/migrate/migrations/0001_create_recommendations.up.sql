@@ -0,0 +1,18 @@
+-- Esquema inicial de recomendaciones, tal como lo creaba RunMigrations.
+-- Usa IF NOT EXISTS para adoptar bases de datos creadas antes del sistema de migraciones.
+CREATE TABLE IF NOT EXISTS recommendations (
+    ticker VARCHAR(10),
+    target_from VARCHAR(20),
+    target_to VARCHAR(20),
+    company VARCHAR(100),
+    action VARCHAR(50),
+    brokerage VARCHAR(100),
+    rating_from VARCHAR(50),
+    rating_to VARCHAR(50),
+    time TIMESTAMP,
+    PRIMARY KEY (ticker, time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_ticker ON recommendations (ticker);
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_time ON recommendations (time);
@@ -0,0 +1,18 @@
+-- Vuelve a guardar los precios objetivo como texto con símbolo de divisa ("$150.00", "EUR 150.00").
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS target_from_text VARCHAR(20);
+
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS target_to_text VARCHAR(20);
+
+UPDATE recommendations SET
+    target_from_text = CASE currency WHEN 'USD' THEN '$' WHEN 'EUR' THEN '€' WHEN 'GBP' THEN '£' WHEN 'CAD' THEN 'C$' WHEN 'AUD' THEN 'A$' WHEN 'BRL' THEN 'R$' WHEN 'JPY' THEN '¥' ELSE currency || ' ' END || target_from::STRING,
+    target_to_text = CASE currency WHEN 'USD' THEN '$' WHEN 'EUR' THEN '€' WHEN 'GBP' THEN '£' WHEN 'CAD' THEN 'C$' WHEN 'AUD' THEN 'A$' WHEN 'BRL' THEN 'R$' WHEN 'JPY' THEN '¥' ELSE currency || ' ' END || target_to::STRING;
+
+ALTER TABLE recommendations DROP COLUMN target_from;
+
+ALTER TABLE recommendations DROP COLUMN target_to;
+
+ALTER TABLE recommendations DROP COLUMN currency;
+
+ALTER TABLE recommendations RENAME COLUMN target_from_text TO target_from;
+
+ALTER TABLE recommendations RENAME COLUMN target_to_text TO target_to;
@@ -0,0 +1,20 @@
+-- Convierte target_from/target_to de texto ("$1,234.50") a NUMERIC y añade la divisa.
+-- El backfill se hace en Go con domain.ParseMoney (divisas, negativos entre paréntesis y separadores
+-- "1,234.50" o "1.234,50"), las mismas reglas que usa la ingesta. Los valores que no se pueden
+-- interpretar quedan en NULL.
+-- El cast ::STRING permite aplicar la migración también a tablas que ya tenían columnas numéricas.
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS target_from_amount NUMERIC(14,2);
+
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS target_to_amount NUMERIC(14,2);
+
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+-- migrate:go backfill_price_targets
+
+ALTER TABLE recommendations DROP COLUMN target_from;
+
+ALTER TABLE recommendations DROP COLUMN target_to;
+
+ALTER TABLE recommendations RENAME COLUMN target_from_amount TO target_from;
+
+ALTER TABLE recommendations RENAME COLUMN target_to_amount TO target_to;
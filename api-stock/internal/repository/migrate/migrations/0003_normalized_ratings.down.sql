@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS recommendations@idx_recommendations_normalized_rating_to;
+
+ALTER TABLE recommendations DROP COLUMN IF EXISTS normalized_rating_from;
+
+ALTER TABLE recommendations DROP COLUMN IF EXISTS normalized_rating_to;
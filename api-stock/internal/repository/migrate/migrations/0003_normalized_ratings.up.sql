@@ -0,0 +1,25 @@
+-- Añade las calificaciones canónicas (strong_sell..strong_buy) junto al texto original del proveedor.
+-- El backfill usa el diccionario por defecto; los textos no reconocidos quedan como 'unknown'.
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS normalized_rating_from VARCHAR(20) NOT NULL DEFAULT 'unknown';
+
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS normalized_rating_to VARCHAR(20) NOT NULL DEFAULT 'unknown';
+
+UPDATE recommendations SET
+    normalized_rating_from = CASE
+        WHEN lower(regexp_replace(trim(rating_from), '[\s\-_/]+', ' ', 'g')) IN ('strong buy', 'conviction buy', 'top pick', 'compra fuerte', 'fuerte compra') THEN 'strong_buy'
+        WHEN lower(regexp_replace(trim(rating_from), '[\s\-_/]+', ' ', 'g')) IN ('buy', 'outperform', 'market outperform', 'sector outperform', 'overweight', 'moderate buy', 'speculative buy', 'accumulate', 'add', 'positive', 'comprar', 'compra', 'superar', 'superior al mercado', 'sobreponderar', 'acumular') THEN 'buy'
+        WHEN lower(regexp_replace(trim(rating_from), '[\s\-_/]+', ' ', 'g')) IN ('hold', 'neutral', 'market perform', 'sector perform', 'peer perform', 'perform', 'equal weight', 'sector weight', 'market weight', 'in line', 'inline', 'fair value', 'mantener', 'neutro', 'en linea') THEN 'hold'
+        WHEN lower(regexp_replace(trim(rating_from), '[\s\-_/]+', ' ', 'g')) IN ('sell', 'underperform', 'market underperform', 'sector underperform', 'underweight', 'moderate sell', 'reduce', 'negative', 'vender', 'venta', 'reducir', 'infraponderar', 'rendimiento inferior', 'inferior al mercado') THEN 'sell'
+        WHEN lower(regexp_replace(trim(rating_from), '[\s\-_/]+', ' ', 'g')) IN ('strong sell', 'venta fuerte', 'fuerte venta') THEN 'strong_sell'
+        ELSE 'unknown'
+    END,
+    normalized_rating_to = CASE
+        WHEN lower(regexp_replace(trim(rating_to), '[\s\-_/]+', ' ', 'g')) IN ('strong buy', 'conviction buy', 'top pick', 'compra fuerte', 'fuerte compra') THEN 'strong_buy'
+        WHEN lower(regexp_replace(trim(rating_to), '[\s\-_/]+', ' ', 'g')) IN ('buy', 'outperform', 'market outperform', 'sector outperform', 'overweight', 'moderate buy', 'speculative buy', 'accumulate', 'add', 'positive', 'comprar', 'compra', 'superar', 'superior al mercado', 'sobreponderar', 'acumular') THEN 'buy'
+        WHEN lower(regexp_replace(trim(rating_to), '[\s\-_/]+', ' ', 'g')) IN ('hold', 'neutral', 'market perform', 'sector perform', 'peer perform', 'perform', 'equal weight', 'sector weight', 'market weight', 'in line', 'inline', 'fair value', 'mantener', 'neutro', 'en linea') THEN 'hold'
+        WHEN lower(regexp_replace(trim(rating_to), '[\s\-_/]+', ' ', 'g')) IN ('sell', 'underperform', 'market underperform', 'sector underperform', 'underweight', 'moderate sell', 'reduce', 'negative', 'vender', 'venta', 'reducir', 'infraponderar', 'rendimiento inferior', 'inferior al mercado') THEN 'sell'
+        WHEN lower(regexp_replace(trim(rating_to), '[\s\-_/]+', ' ', 'g')) IN ('strong sell', 'venta fuerte', 'fuerte venta') THEN 'strong_sell'
+        ELSE 'unknown'
+    END;
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_normalized_rating_to ON recommendations (normalized_rating_to);
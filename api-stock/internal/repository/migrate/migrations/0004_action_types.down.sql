@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS recommendations@idx_recommendations_action_type;
+
+ALTER TABLE recommendations DROP COLUMN IF EXISTS action_type;
@@ -0,0 +1,28 @@
+-- Añade el tipo de acción canónico (upgrade, downgrade, target_raised, ...) junto al texto original.
+-- El backfill aproxima domain.ClassifyAction: texto de inicio/retirada, luego cambio de calificación,
+-- luego texto de upgrade/downgrade, luego cambio de precio objetivo y por último el resto del texto.
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS action_type VARCHAR(20) NOT NULL DEFAULT 'unknown';
+
+UPDATE recommendations SET
+    action_type = CASE
+        WHEN lower(action) ~ '(^|[^a-z])(dropped|drops|drop|discontinued|terminated|terminates|suspended|retirada|suspendida|terminada)([^a-z]|$)' THEN 'drop_coverage'
+        WHEN lower(action) ~ '(^|[^a-z])(initiated|initiates|initiate|resumed|iniciado|iniciada|inicia|reanudada)([^a-z]|$)' THEN 'initiate'
+        WHEN normalized_rating_from <> 'unknown' AND normalized_rating_to <> 'unknown' AND normalized_rating_from <> normalized_rating_to THEN
+            CASE
+                WHEN array_position(ARRAY['strong_sell', 'sell', 'hold', 'buy', 'strong_buy'], normalized_rating_to)
+                    > array_position(ARRAY['strong_sell', 'sell', 'hold', 'buy', 'strong_buy'], normalized_rating_from) THEN 'upgrade'
+                ELSE 'downgrade'
+            END
+        WHEN lower(action) ~ '(^|[^a-z])(upgraded|upgrades|upgrade|mejorado|mejora)([^a-z]|$)' THEN 'upgrade'
+        WHEN lower(action) ~ '(^|[^a-z])(downgraded|downgrades|downgrade|rebajado|degradado|empeorado)([^a-z]|$)' THEN 'downgrade'
+        WHEN target_from IS NOT NULL AND target_to IS NOT NULL AND target_to > target_from THEN 'target_raised'
+        WHEN target_from IS NOT NULL AND target_to IS NOT NULL AND target_to < target_from THEN 'target_lowered'
+        WHEN lower(action) ~ '(^|[^a-z])(raised|raises|increased|boosted|aumentado|subido|elevado)([^a-z]|$)' THEN 'target_raised'
+        WHEN lower(action) ~ '(^|[^a-z])(lowered|lowers|cut|cuts|reduced|decreased|bajado|reducido|recortado)([^a-z]|$)' THEN 'target_lowered'
+        WHEN lower(action) ~ '(^|[^a-z])(reiterated|reiterates|reiterate|reaffirmed|reiterado|reafirmado)([^a-z]|$)' THEN 'reiterate'
+        WHEN lower(action) ~ '(^|[^a-z])(maintained|maintains|set|updated|mantenido|mantiene|establecido|actualizado)([^a-z]|$)' THEN 'maintain'
+        WHEN normalized_rating_from <> 'unknown' AND normalized_rating_to <> 'unknown' AND target_from IS NOT NULL AND target_to IS NOT NULL THEN 'maintain'
+        ELSE 'unknown'
+    END;
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_action_type ON recommendations (action_type);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS recommendations@idx_recommendations_brokerage_id;
+
+ALTER TABLE recommendations DROP COLUMN IF EXISTS brokerage_id;
+
+DROP TABLE IF EXISTS brokerage_unresolved;
+
+DROP TABLE IF EXISTS brokerage_aliases;
+
+DROP TABLE IF EXISTS brokerages;
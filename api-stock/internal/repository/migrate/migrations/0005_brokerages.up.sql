@@ -0,0 +1,75 @@
+-- Registro maestro de firmas de corretaje: ID canónico, nombre, peso de reputación y alias.
+-- alias_key se calcula igual que domain.BrokerageKey: minúsculas, sin acentos, sin puntuación
+-- y con separadores unificados.
+CREATE TABLE IF NOT EXISTS brokerages (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    reputation_weight FLOAT8 NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS brokerage_aliases (
+    alias_key VARCHAR(255) PRIMARY KEY,
+    alias VARCHAR(255) NOT NULL,
+    brokerage_id VARCHAR(64) NOT NULL REFERENCES brokerages (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    INDEX idx_brokerage_aliases_brokerage_id (brokerage_id)
+);
+
+-- Nombres recibidos del proveedor que no coinciden con ningún alias, para revisión manual.
+CREATE TABLE IF NOT EXISTS brokerage_unresolved (
+    name VARCHAR(255) PRIMARY KEY,
+    occurrences INT8 NOT NULL DEFAULT 0,
+    first_seen TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Firmas iniciales; los pesos son los que usaba el modelo de recomendación.
+INSERT INTO brokerages (id, name, reputation_weight) VALUES
+    ('goldman_sachs', 'Goldman Sachs', 1.3),
+    ('morgan_stanley', 'Morgan Stanley', 1.2),
+    ('jpmorgan', 'JPMorgan Chase & Co.', 1.2),
+    ('bmo_capital_markets', 'BMO Capital Markets', 1.1),
+    ('oppenheimer', 'Oppenheimer', 1.0),
+    ('mizuho', 'Mizuho', 0.9)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO brokerage_aliases (alias_key, alias, brokerage_id)
+SELECT trim(regexp_replace(translate(lower(regexp_replace(alias, '[.,''()&]+', ' ', 'g')), 'áéíóú', 'aeiou'), '[\s\-_/]+', ' ', 'g')), alias, brokerage_id
+FROM (VALUES
+    ('Goldman Sachs', 'goldman_sachs'),
+    ('The Goldman Sachs Group', 'goldman_sachs'),
+    ('Goldman Sachs Group', 'goldman_sachs'),
+    ('Morgan Stanley', 'morgan_stanley'),
+    ('Morgan Stanley & Co.', 'morgan_stanley'),
+    ('JPMorgan Chase & Co.', 'jpmorgan'),
+    ('JPMorgan', 'jpmorgan'),
+    ('JP Morgan', 'jpmorgan'),
+    ('J.P. Morgan', 'jpmorgan'),
+    ('JPMorgan Chase', 'jpmorgan'),
+    ('BMO Capital Markets', 'bmo_capital_markets'),
+    ('BMO Capital', 'bmo_capital_markets'),
+    ('BMO', 'bmo_capital_markets'),
+    ('Oppenheimer', 'oppenheimer'),
+    ('Oppenheimer & Co.', 'oppenheimer'),
+    ('Mizuho', 'mizuho'),
+    ('Mizuho Securities', 'mizuho')
+) AS seed (alias, brokerage_id)
+ON CONFLICT (alias_key) DO NOTHING;
+
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS brokerage_id VARCHAR(64);
+
+UPDATE recommendations SET brokerage_id = a.brokerage_id
+FROM brokerage_aliases AS a
+WHERE recommendations.brokerage_id IS NULL
+  AND a.alias_key = trim(regexp_replace(translate(lower(regexp_replace(recommendations.brokerage, '[.,''()&]+', ' ', 'g')), 'áéíóú', 'aeiou'), '[\s\-_/]+', ' ', 'g'));
+
+INSERT INTO brokerage_unresolved (name, occurrences, first_seen, last_seen)
+SELECT brokerage, count(*), min(time), max(time)
+FROM recommendations
+WHERE brokerage_id IS NULL AND trim(brokerage) <> ''
+GROUP BY brokerage
+ON CONFLICT (name) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_brokerage_id ON recommendations (brokerage_id);
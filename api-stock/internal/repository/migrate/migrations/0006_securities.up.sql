@@ -0,0 +1,21 @@
+-- Registro maestro de valores: ticker, empresa, bolsa, sector, industria y si sigue activo.
+-- Se alimenta de la ingesta (ticker y empresa) y de la importación de CSV (el resto de metadatos).
+CREATE TABLE IF NOT EXISTS securities (
+    ticker VARCHAR(20) PRIMARY KEY,
+    company_name VARCHAR(255) NOT NULL DEFAULT '',
+    exchange VARCHAR(20) NOT NULL DEFAULT '',
+    sector VARCHAR(100) NOT NULL DEFAULT '',
+    industry VARCHAR(150) NOT NULL DEFAULT '',
+    active BOOL NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    INDEX idx_securities_sector (sector)
+);
+
+-- Backfill con el nombre de empresa de la recomendación más reciente de cada ticker
+INSERT INTO securities (ticker, company_name)
+SELECT DISTINCT ON (ticker) ticker, COALESCE(company, '')
+FROM recommendations
+WHERE ticker IS NOT NULL
+ORDER BY ticker, time DESC
+ON CONFLICT (ticker) DO NOTHING;
@@ -0,0 +1,15 @@
+-- Volver a (ticker, time) exige que sea única: se conserva una sola fila por par (la de menor ID).
+DELETE FROM recommendations
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, row_number() OVER (PARTITION BY ticker, time ORDER BY id) AS rn
+        FROM recommendations
+    ) AS ranked
+    WHERE rn > 1
+);
+
+ALTER TABLE recommendations ALTER PRIMARY KEY USING COLUMNS (ticker, time);
+
+DROP INDEX IF EXISTS recommendations@recommendations_id_key CASCADE;
+
+ALTER TABLE recommendations DROP COLUMN IF EXISTS id;
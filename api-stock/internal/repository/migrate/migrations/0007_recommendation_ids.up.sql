@@ -0,0 +1,30 @@
+-- Sustituye la clave primaria (ticker, time) por un ID estable derivado de la identidad del proveedor,
+-- para que dos firmas que califican el mismo ticker en el mismo instante no se sobrescriban.
+-- El cálculo replica domain.RecommendationID: primeros 32 hex del SHA-256 de
+-- "TICKER|firma|segundos unix|acción".
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS id VARCHAR(32);
+
+UPDATE recommendations SET id = substr(sha256(concat(
+    upper(trim(ticker)), '|',
+    lower(trim(COALESCE(brokerage, ''))), '|',
+    floor(extract(epoch FROM time))::INT8::STRING, '|',
+    lower(trim(COALESCE(action, '')))
+)), 1, 32)
+WHERE id IS NULL;
+
+-- Filas con la misma identidad que solo difieren en fracciones de segundo: se conserva la más reciente
+DELETE FROM recommendations
+WHERE (ticker, time) IN (
+    SELECT ticker, time FROM (
+        SELECT ticker, time, row_number() OVER (PARTITION BY id ORDER BY time DESC) AS rn
+        FROM recommendations
+    ) AS ranked
+    WHERE rn > 1
+);
+
+ALTER TABLE recommendations ALTER COLUMN id SET NOT NULL;
+
+ALTER TABLE recommendations ALTER PRIMARY KEY USING COLUMNS (id);
+
+-- ALTER PRIMARY KEY conserva la clave anterior como índice único; ya no debe impedir duplicados de (ticker, time)
+DROP INDEX IF EXISTS recommendations@recommendations_ticker_time_key CASCADE;
@@ -0,0 +1,4 @@
+-- Tabla de staging para la sincronización completa: se carga y valida aquí y luego se publica en
+-- recommendations (ver recommendation_swaps). Debe tener las mismas columnas que recommendations;
+-- las migraciones que añadan columnas a recommendations deben añadirlas también aquí.
+CREATE TABLE IF NOT EXISTS recommendations_staging (LIKE recommendations INCLUDING ALL);
@@ -0,0 +1,11 @@
+-- Progreso de las sincronizaciones paginadas: token de la siguiente página y contadores.
+-- La fila existe mientras la sincronización está en curso o interrumpida; los registros ya cargados
+-- quedan en recommendations_staging.
+CREATE TABLE IF NOT EXISTS sync_checkpoints (
+    name VARCHAR(50) PRIMARY KEY,
+    next_page STRING NOT NULL DEFAULT '',
+    pages INT8 NOT NULL DEFAULT 0,
+    records INT8 NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
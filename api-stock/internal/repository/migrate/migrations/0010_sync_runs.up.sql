@@ -0,0 +1,17 @@
+-- Historial de ejecuciones de sincronización con la API externa.
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    pages INT8 NOT NULL DEFAULT 0,
+    inserted INT8 NOT NULL DEFAULT 0,
+    updated INT8 NOT NULL DEFAULT 0,
+    skipped INT8 NOT NULL DEFAULT 0,
+    deleted INT8 NOT NULL DEFAULT 0,
+    error STRING,
+    watermark TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_runs_started_at ON sync_runs (started_at DESC);
@@ -0,0 +1,11 @@
+-- Solo se conservan los checkpoints y las filas del proveedor original.
+DELETE FROM sync_checkpoints WHERE name LIKE 'full:%' AND name <> 'full:default';
+UPDATE sync_checkpoints SET name = 'full' WHERE name = 'full:default';
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS source;
+
+DELETE FROM recommendations_staging WHERE source <> 'default';
+DELETE FROM recommendations WHERE source <> 'default';
+DROP INDEX IF EXISTS recommendations_staging@idx_recommendations_staging_source;
+DROP INDEX IF EXISTS recommendations@idx_recommendations_source_time;
+ALTER TABLE recommendations_staging DROP COLUMN IF EXISTS source;
+ALTER TABLE recommendations DROP COLUMN IF EXISTS source;
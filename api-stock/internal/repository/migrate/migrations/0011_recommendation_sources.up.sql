@@ -0,0 +1,11 @@
+-- Proveedor de datos de cada recomendación. Las filas existentes pertenecen al proveedor original.
+-- staging replica la columna (ver 0008) porque la sincronización completa se carga y se intercambia por proveedor.
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS source VARCHAR(50) NOT NULL DEFAULT 'default';
+ALTER TABLE recommendations_staging ADD COLUMN IF NOT EXISTS source VARCHAR(50) NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_source_time ON recommendations (source, time DESC);
+CREATE INDEX IF NOT EXISTS idx_recommendations_staging_source ON recommendations_staging (source);
+
+-- Historial y checkpoints pasan a ser por proveedor.
+ALTER TABLE sync_runs ADD COLUMN IF NOT EXISTS source VARCHAR(50) NOT NULL DEFAULT 'default';
+UPDATE sync_checkpoints SET name = 'full:default' WHERE name = 'full';
@@ -0,0 +1,2 @@
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS quarantined;
+DROP TABLE IF EXISTS quarantine;
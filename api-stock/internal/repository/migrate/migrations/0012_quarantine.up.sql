@@ -0,0 +1,21 @@
+-- Registros del proveedor rechazados por la validación de la ingesta, con el motivo y el payload original.
+-- fingerprint es el SHA-256 del payload recibido: un registro que el proveedor repite en cada
+-- sincronización ocupa una sola fila. payload guarda la versión corregida por un administrador, si la hay.
+CREATE TABLE IF NOT EXISTS quarantine (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source VARCHAR(50) NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    sync_run_id UUID,
+    reason STRING NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    occurrences INT8 NOT NULL DEFAULT 1,
+    first_seen TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (source, fingerprint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantine_status_last_seen ON quarantine (status, last_seen DESC);
+
+ALTER TABLE sync_runs ADD COLUMN IF NOT EXISTS quarantined INT8 NOT NULL DEFAULT 0;
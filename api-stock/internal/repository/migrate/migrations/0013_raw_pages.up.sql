@@ -0,0 +1,23 @@
+-- Archivo de las páginas del proveedor tal como se recibieron, con el cuerpo comprimido con gzip, para
+-- reconstruir las recomendaciones con las reglas de decodificación actuales (cmd/reprocess).
+-- fingerprint es el SHA-256 del cuerpo: una página idéntica que se vuelve a pedir ocupa una sola fila y
+-- solo actualiza fetched_at, fetches y la ejecución que la pidió.
+CREATE TABLE IF NOT EXISTS raw_pages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source VARCHAR(50) NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    sync_run_id UUID,
+    page_token STRING NOT NULL DEFAULT '',
+    url STRING NOT NULL,
+    status_code INT8 NOT NULL,
+    content_type STRING NOT NULL DEFAULT '',
+    content_encoding VARCHAR(20) NOT NULL DEFAULT 'gzip',
+    body BYTES NOT NULL,
+    size INT8 NOT NULL,
+    first_fetched_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fetches INT8 NOT NULL DEFAULT 1,
+    UNIQUE (source, fingerprint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_raw_pages_source_fetched_at ON raw_pages (source, fetched_at, id);
@@ -0,0 +1,2 @@
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS corrected;
+DROP TABLE IF EXISTS recommendation_revisions;
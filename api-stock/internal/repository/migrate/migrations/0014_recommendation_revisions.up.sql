@@ -0,0 +1,30 @@
+-- Versiones anteriores de las recomendaciones. Cuando una sincronización, una importación o un reingreso
+-- reemplaza una fila por una versión distinta con el mismo ID, la versión anterior se copia aquí con la
+-- ejecución que la reemplazó. correction indica que cambió algún dato del proveedor (precios objetivo,
+-- divisa, empresa, acción, firma o calificaciones) y no solo los campos derivados de la normalización.
+CREATE TABLE IF NOT EXISTS recommendation_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recommendation_id VARCHAR(32) NOT NULL,
+    source VARCHAR(50) NOT NULL DEFAULT 'default',
+    ticker VARCHAR(10),
+    target_from NUMERIC(14,2),
+    target_to NUMERIC(14,2),
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    company VARCHAR(100),
+    action VARCHAR(50),
+    action_type VARCHAR(20) NOT NULL DEFAULT 'unknown',
+    brokerage VARCHAR(100),
+    brokerage_id VARCHAR(64),
+    rating_from VARCHAR(50),
+    rating_to VARCHAR(50),
+    normalized_rating_from VARCHAR(20) NOT NULL DEFAULT 'unknown',
+    normalized_rating_to VARCHAR(20) NOT NULL DEFAULT 'unknown',
+    time TIMESTAMP,
+    correction BOOL NOT NULL DEFAULT false,
+    sync_run_id UUID,
+    revised_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recommendation_revisions_recommendation ON recommendation_revisions (recommendation_id, revised_at DESC);
+
+ALTER TABLE sync_runs ADD COLUMN IF NOT EXISTS corrected INT8 NOT NULL DEFAULT 0;
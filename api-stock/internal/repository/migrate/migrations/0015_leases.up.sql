@@ -0,0 +1,10 @@
+-- Leases que serializan los trabajos entre procesos (réplicas del worker, sincronización inicial de
+-- cmd/api, cmd/reprocess). Una fila por trabajo; quien la tiene la renueva antes de expires_at y
+-- cualquier proceso puede tomarla una vez vencida.
+CREATE TABLE IF NOT EXISTS leases (
+    name VARCHAR(50) PRIMARY KEY,
+    owner VARCHAR(100) NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    renewed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,16 @@
+-- Historial de ejecuciones de los trabajos programados del worker (sincronizaciones, retención).
+-- Las sincronizaciones de cada ejecución quedan además en sync_runs, una por proveedor.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job VARCHAR(50) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    triggered_by VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    error STRING
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_started_at ON job_runs (started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_started_at ON job_runs (job, started_at DESC);
@@ -0,0 +1,5 @@
+-- Token de fencing: cada toma del lease lo incrementa. Quien escribe con el lease tomado comprueba dentro
+-- de su transacción que el lease sigue siendo suyo con el mismo token, de modo que un proceso que perdió
+-- el lease sin enterarse no puede escribir después de que otro lo tome. Liberar el lease lo vence en
+-- lugar de borrar la fila, para que el token no vuelva a empezar.
+ALTER TABLE leases ADD COLUMN IF NOT EXISTS token INT8 NOT NULL DEFAULT 0;
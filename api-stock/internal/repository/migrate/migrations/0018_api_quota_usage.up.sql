@@ -0,0 +1,8 @@
+-- Peticiones hechas a cada proveedor por día UTC, para su cuota diaria. Todos los procesos descuentan de la
+-- misma fila, así que la cuota se respeta aunque cambie el proceso que sincroniza o se reinicie.
+CREATE TABLE IF NOT EXISTS api_quota_usage (
+    provider VARCHAR(50) NOT NULL,
+    day DATE NOT NULL,
+    used INT8 NOT NULL DEFAULT 0,
+    PRIMARY KEY (provider, day)
+);
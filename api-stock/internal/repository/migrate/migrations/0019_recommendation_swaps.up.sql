@@ -0,0 +1,9 @@
+-- Proveedores con un intercambio de staging en curso. Mientras un proveedor tiene fila aquí, las consultas
+-- leen sus recomendaciones de recommendations_staging (el dataset nuevo, completo) en vez de recommendations,
+-- que se está actualizando por lotes; al terminar se borra la fila y vuelven a leer de recommendations.
+-- Cambiar de tabla es una sola escritura, así que las consultas nunca ven un dataset a medias.
+CREATE TABLE IF NOT EXISTS recommendation_swaps (
+    source VARCHAR(50) PRIMARY KEY,
+    sync_run_id UUID,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
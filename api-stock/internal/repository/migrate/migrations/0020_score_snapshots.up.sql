@@ -0,0 +1,12 @@
+-- Foto diaria del score de cada ticker, que guarda el trabajo score_snapshot del worker para seguir su
+-- evolución. Volver a tomar la foto de un día la reemplaza.
+CREATE TABLE IF NOT EXISTS score_snapshots (
+    day DATE NOT NULL,
+    ticker VARCHAR(10) NOT NULL,
+    score FLOAT8 NOT NULL,
+    recommendations INT8 NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (day, ticker)
+);
+
+CREATE INDEX IF NOT EXISTS idx_score_snapshots_ticker_day ON score_snapshots (ticker, day DESC);
@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// mirroredTables asocia las tablas que deben tener las mismas columnas que otra con esa otra tabla.
+// recommendations_staging se creó con LIKE recommendations (0008) y se intercambia con ella por nombre de
+// columna, así que cada migración que cambia las columnas de recommendations debe cambiar también staging.
+var mirroredTables = map[string]string{
+	"recommendations_staging": "recommendations",
+}
+
+// verifyMirroredTables comprueba, después de migrar, que cada tabla de mirroredTables tiene las mismas
+// columnas (nombre, tipo y nulabilidad) que su original. Las tablas que aún no existen en la versión
+// actual del esquema se omiten.
+func (m *Migrator) verifyMirroredTables(ctx context.Context) error {
+	mirrors := make([]string, 0, len(mirroredTables))
+	for mirror := range mirroredTables {
+		mirrors = append(mirrors, mirror)
+	}
+	sort.Strings(mirrors)
+
+	for _, mirror := range mirrors {
+		original := mirroredTables[mirror]
+		mirrorColumns, err := m.tableColumns(ctx, mirror)
+		if err != nil {
+			return err
+		}
+		if len(mirrorColumns) == 0 {
+			continue
+		}
+		originalColumns, err := m.tableColumns(ctx, original)
+		if err != nil {
+			return err
+		}
+		if diff := diffColumns(originalColumns, mirrorColumns); len(diff) > 0 {
+			return fmt.Errorf("las columnas de %s no coinciden con las de %s (%s); la migración que cambió %s debe cambiar también %s",
+				mirror, original, strings.Join(diff, "; "), original, mirror)
+		}
+	}
+	return nil
+}
+
+// tableColumns devuelve la definición ("TIPO NULL" o "TIPO NOT NULL") de cada columna visible de la tabla,
+// o un mapa vacío si la tabla no existe.
+func (m *Migrator) tableColumns(ctx context.Context, table string) (map[string]string, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT column_name, crdb_sql_type, is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND is_hidden = 'NO'`, table)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo columnas de %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, typ, nullable string
+		if err := rows.Scan(&name, &typ, &nullable); err != nil {
+			return nil, fmt.Errorf("error leyendo columnas de %s: %v", table, err)
+		}
+		if nullable == "YES" {
+			columns[name] = typ + " NULL"
+		} else {
+			columns[name] = typ + " NOT NULL"
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo columnas de %s: %v", table, err)
+	}
+	return columns, nil
+}
+
+// diffColumns describe, ordenadas por nombre, las columnas que faltan, sobran o difieren en mirror respecto a original.
+func diffColumns(original, mirror map[string]string) []string {
+	names := make([]string, 0, len(original)+len(mirror))
+	for name := range original {
+		names = append(names, name)
+	}
+	for name := range mirror {
+		if _, ok := original[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diff []string
+	for _, name := range names {
+		want, inOriginal := original[name]
+		got, inMirror := mirror[name]
+		switch {
+		case !inMirror:
+			diff = append(diff, fmt.Sprintf("falta %s %s", name, want))
+		case !inOriginal:
+			diff = append(diff, fmt.Sprintf("sobra %s %s", name, got))
+		case got != want:
+			diff = append(diff, fmt.Sprintf("%s es %s en lugar de %s", name, got, want))
+		}
+	}
+	return diff
+}
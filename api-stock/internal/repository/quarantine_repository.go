@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// quarantineColumns es la lista de columnas que se leen en cada consulta de la cuarentena, en el orden de scanQuarantined.
+const quarantineColumns = `id, source, fingerprint, sync_run_id, reason, payload, status, occurrences, first_seen, last_seen, updated_at`
+
+// quarantineRepository implementa domain.QuarantineRepository sobre la tabla quarantine.
+type quarantineRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewQuarantineRepository crea el repositorio de registros en cuarentena.
+func NewQuarantineRepository(db *sql.DB) domain.QuarantineRepository {
+	return &quarantineRepository{db: db}
+}
+
+// QuarantineRecords guarda los registros rechazados en un único upsert. Los repetidos dentro de la lista
+// se agrupan por proveedor y huella para sumar sus apariciones. Con un lease en el contexto, solo los
+// guarda mientras siga siendo de este trabajo.
+func (r *quarantineRepository) QuarantineRecords(ctx context.Context, records []domain.QuarantinedRecord) ([]domain.QuarantinedRecord, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	type key struct{ source, fingerprint string }
+	unique := make(map[key]domain.QuarantinedRecord, len(records))
+	counts := make(map[key]int64, len(records))
+	keys := make([]key, 0, len(records))
+	for _, rec := range records {
+		k := key{rec.Source, rec.Fingerprint}
+		if _, seen := unique[k]; !seen {
+			keys = append(keys, k)
+		}
+		unique[k] = rec
+		counts[k]++
+	}
+	// Orden determinista para que sincronizaciones concurrentes tomen los locks de fila en el mismo orden
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return keys[i].source < keys[j].source
+		}
+		return keys[i].fingerprint < keys[j].fingerprint
+	})
+
+	now := time.Now()
+	valueStrings := make([]string, 0, len(keys))
+	valueArgs := make([]interface{}, 0, len(keys)*8)
+	for i, k := range keys {
+		rec := unique[k]
+		valueStrings = append(valueStrings, rowPlaceholders(i, 8))
+		valueArgs = append(valueArgs, rec.Source, rec.Fingerprint, nullString(rec.SyncRunID), rec.Reason,
+			string(rec.Payload), counts[k], now, now)
+	}
+
+	var reingested []domain.QuarantinedRecord
+	err := inFencedTx(ctx, r.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+        INSERT INTO quarantine (source, fingerprint, sync_run_id, reason, payload, occurrences, first_seen, last_seen)
+        VALUES %s
+        ON CONFLICT (source, fingerprint) DO UPDATE SET
+            sync_run_id = EXCLUDED.sync_run_id,
+            reason = CASE WHEN quarantine.status = 'pending' AND quarantine.payload = EXCLUDED.payload
+                THEN EXCLUDED.reason ELSE quarantine.reason END,
+            occurrences = quarantine.occurrences + EXCLUDED.occurrences,
+            last_seen = EXCLUDED.last_seen
+        RETURNING `+quarantineColumns, strings.Join(valueStrings, ",")), valueArgs...)
+		if err != nil {
+			return fmt.Errorf("error guardando registros en cuarentena: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			rec, err := scanQuarantined(rows)
+			if err != nil {
+				return err
+			}
+			if rec.Status == domain.QuarantineReingested {
+				reingested = append(reingested, rec)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error después de iterar filas: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reingested, nil
+}
+
+// ListQuarantine devuelve los registros que cumplen el filtro, los vistos más recientemente primero.
+func (r *quarantineRepository) ListQuarantine(ctx context.Context, filter domain.QuarantineFilter, limit int) ([]domain.QuarantinedRecord, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, string(filter.Status))
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.Source != "" {
+		args = append(args, filter.Source)
+		conditions = append(conditions, fmt.Sprintf("source = $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+        SELECT `+quarantineColumns+`
+        FROM quarantine
+        %s
+        ORDER BY last_seen DESC, id
+        LIMIT $%d`, where, len(args)+1), append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando cuarentena: %v", err)
+	}
+	defer rows.Close()
+
+	records := []domain.QuarantinedRecord{}
+	for rows.Next() {
+		rec, err := scanQuarantined(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return records, nil
+}
+
+// GetQuarantined obtiene un registro por ID, o domain.ErrQuarantineNotFound.
+func (r *quarantineRepository) GetQuarantined(ctx context.Context, id string) (*domain.QuarantinedRecord, error) {
+	rec, err := scanQuarantined(r.db.QueryRowContext(ctx, `
+        SELECT `+quarantineColumns+` FROM quarantine WHERE id::STRING = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrQuarantineNotFound
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// UpdateQuarantined guarda el payload, el motivo y el estado del registro, con la misma comprobación del
+// lease que QuarantineRecords.
+func (r *quarantineRepository) UpdateQuarantined(ctx context.Context, rec domain.QuarantinedRecord) error {
+	var affected int64
+	err := inFencedTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+        UPDATE quarantine SET payload = $2, reason = $3, status = $4, updated_at = now()
+        WHERE id::STRING = $1`,
+			rec.ID, string(rec.Payload), rec.Reason, string(rec.Status))
+		if err != nil {
+			return err
+		}
+		affected, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error actualizando registro en cuarentena: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrQuarantineNotFound
+	}
+	return nil
+}
+
+// DeleteResolvedBefore elimina los registros reingresados o descartados cuya última aparición es anterior
+// a before. Los pendientes se conservan siempre: esperan una revisión.
+func (r *quarantineRepository) DeleteResolvedBefore(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := deleteInBatches(ctx, r.db, `
+        DELETE FROM quarantine WHERE status != $1 AND last_seen < $2`,
+		string(domain.QuarantinePending), before)
+	if err != nil {
+		return deleted, fmt.Errorf("error eliminando registros en cuarentena: %v", err)
+	}
+	return deleted, nil
+}
+
+// scanQuarantined escanea una fila con las columnas de quarantineColumns.
+func scanQuarantined(row rowScanner) (domain.QuarantinedRecord, error) {
+	var rec domain.QuarantinedRecord
+	var syncRunID sql.NullString
+	var payload []byte
+	err := row.Scan(&rec.ID, &rec.Source, &rec.Fingerprint, &syncRunID, &rec.Reason, &payload, &rec.Status,
+		&rec.Occurrences, &rec.FirstSeen, &rec.LastSeen, &rec.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return rec, err
+	}
+	if err != nil {
+		return rec, fmt.Errorf("error escaneando registro en cuarentena: %v", err)
+	}
+	rec.SyncRunID = syncRunID.String
+	rec.Payload = payload
+	return rec, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// quotaRepository implementa domain.QuotaRepository sobre la tabla api_quota_usage.
+type quotaRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewQuotaRepository crea el repositorio de cuotas de los proveedores.
+func NewQuotaRepository(db *sql.DB) domain.QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+// TakeQuota descuenta una petición con un upsert condicionado: dos procesos que descuentan a la vez no
+// pueden pasar juntos de limit.
+func (r *quotaRepository) TakeQuota(ctx context.Context, provider string, day time.Time, limit int) (int, bool, error) {
+	var used int
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO api_quota_usage (provider, day, used) VALUES ($1, $2::DATE, 1)
+        ON CONFLICT (provider, day) DO UPDATE SET used = api_quota_usage.used + 1
+        WHERE api_quota_usage.used < $3
+        RETURNING used`, provider, quotaDay(day), limit).Scan(&used)
+	if err == sql.ErrNoRows {
+		used, err := r.GetQuotaUsed(ctx, provider, day) // Cuota agotada: la fila no se actualizó
+		return used, false, err
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error descontando la cuota de %s: %v", provider, err)
+	}
+	return used, true, nil
+}
+
+// RefundQuota devuelve una petición a la cuota del día, sin bajar de cero.
+func (r *quotaRepository) RefundQuota(ctx context.Context, provider string, day time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE api_quota_usage SET used = used - 1
+        WHERE provider = $1 AND day = $2::DATE AND used > 0`, provider, quotaDay(day))
+	if err != nil {
+		return fmt.Errorf("error devolviendo la cuota de %s: %v", provider, err)
+	}
+	return nil
+}
+
+// GetQuotaUsed obtiene las peticiones del día; 0 si todavía no hay ninguna.
+func (r *quotaRepository) GetQuotaUsed(ctx context.Context, provider string, day time.Time) (int, error) {
+	var used int
+	err := r.db.QueryRowContext(ctx, `SELECT used FROM api_quota_usage WHERE provider = $1 AND day = $2::DATE`,
+		provider, quotaDay(day)).Scan(&used)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error consultando la cuota de %s: %v", provider, err)
+	}
+	return used, nil
+}
+
+// quotaDay da formato de fecha al día UTC de la cuota.
+func quotaDay(day time.Time) string {
+	return day.UTC().Format(time.DateOnly)
+}
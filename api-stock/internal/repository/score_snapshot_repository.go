@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// snapshotBatchSize es el número de tickers que SaveScoreSnapshot inserta por sentencia.
+const snapshotBatchSize = 500
+
+// scoreSnapshotRepository implementa domain.ScoreSnapshotRepository sobre la tabla score_snapshots.
+type scoreSnapshotRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewScoreSnapshotRepository crea el repositorio de fotos diarias de scores.
+func NewScoreSnapshotRepository(db *sql.DB) domain.ScoreSnapshotRepository {
+	return &scoreSnapshotRepository{db: db}
+}
+
+// SaveScoreSnapshot reemplaza en una transacción la foto del día: borra la anterior, si la hay, e inserta
+// los scores en lotes de snapshotBatchSize.
+func (r *scoreSnapshotRepository) SaveScoreSnapshot(ctx context.Context, day time.Time, scores []domain.TickerScore) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback() // Rollback automático en caso de error
+
+	date := day.UTC().Format(time.DateOnly)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM score_snapshots WHERE day = $1::DATE`, date); err != nil {
+		return fmt.Errorf("error borrando foto de scores: %v", err)
+	}
+
+	for start := 0; start < len(scores); start += snapshotBatchSize {
+		batch := scores[start:min(start+snapshotBatchSize, len(scores))]
+		valueStrings := make([]string, 0, len(batch))
+		valueArgs := make([]interface{}, 0, len(batch)*3+1)
+		valueArgs = append(valueArgs, date)
+		for i, score := range batch {
+			valueStrings = append(valueStrings, fmt.Sprintf("($1::DATE, $%d, $%d, $%d)", i*3+2, i*3+3, i*3+4))
+			valueArgs = append(valueArgs, score.Ticker, score.Score, score.Recommendations)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO score_snapshots (day, ticker, score, recommendations) VALUES `+
+			strings.Join(valueStrings, ","), valueArgs...); err != nil {
+			return fmt.Errorf("error guardando foto de scores: %v", err)
+		}
+	}
+	return tx.Commit()
+}
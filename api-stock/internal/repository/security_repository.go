@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// securityImportBatchSize es el número de filas por sentencia al importar valores.
+const securityImportBatchSize = 500
+
+// securityRepository implementa domain.SecurityRepository sobre la tabla securities.
+type securityRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewSecurityRepository crea el repositorio del registro maestro de valores.
+func NewSecurityRepository(db *sql.DB) domain.SecurityRepository {
+	return &securityRepository{db: db}
+}
+
+// ListSecurities devuelve los valores que cumplen el filtro, ordenados por ticker.
+func (r *securityRepository) ListSecurities(ctx context.Context, filter domain.SecurityFilter) ([]domain.Security, error) {
+	var conditions []string
+	var args []interface{}
+	if !filter.IncludeInactive {
+		conditions = append(conditions, "s.active")
+	}
+	if filter.Sector != "" {
+		args = append(args, filter.Sector)
+		conditions = append(conditions, fmt.Sprintf("lower(s.sector) = lower($%d)", len(args)))
+	}
+	if filter.WithRecommendations {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM "+servedRecommendations+" AS r WHERE r.ticker = s.ticker)")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT s.ticker, s.company_name, s.exchange, s.sector, s.industry, s.active, s.updated_at
+        FROM securities AS s
+        `+where+`
+        ORDER BY s.ticker`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error en consulta SQL: %v", err)
+	}
+	defer rows.Close()
+
+	securities := []domain.Security{}
+	for rows.Next() {
+		var sec domain.Security
+		if err := rows.Scan(&sec.Ticker, &sec.Company, &sec.Exchange, &sec.Sector, &sec.Industry, &sec.Active, &sec.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando valor: %v", err)
+		}
+		securities = append(securities, sec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return securities, nil
+}
+
+// UpsertSecurities crea o reemplaza los metadatos de los valores en una sola transacción.
+// Un nombre de empresa vacío no borra el existente.
+func (r *securityRepository) UpsertSecurities(ctx context.Context, securities []domain.Security) error {
+	if len(securities) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback() // Rollback automático en caso de error
+
+	for start := 0; start < len(securities); start += securityImportBatchSize {
+		end := start + securityImportBatchSize
+		if end > len(securities) {
+			end = len(securities)
+		}
+		batch := securities[start:end]
+
+		valueStrings := make([]string, 0, len(batch))
+		valueArgs := make([]interface{}, 0, len(batch)*6)
+		for i, sec := range batch {
+			valueStrings = append(valueStrings, rowPlaceholders(i, 6))
+			valueArgs = append(valueArgs, sec.Ticker, sec.Company, sec.Exchange, sec.Sector, sec.Industry, sec.Active)
+		}
+
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+            INSERT INTO securities (ticker, company_name, exchange, sector, industry, active)
+            VALUES %s
+            ON CONFLICT (ticker) DO UPDATE SET
+                company_name = CASE WHEN EXCLUDED.company_name = '' THEN securities.company_name ELSE EXCLUDED.company_name END,
+                exchange = EXCLUDED.exchange,
+                sector = EXCLUDED.sector,
+                industry = EXCLUDED.industry,
+                active = EXCLUDED.active,
+                updated_at = now()`, strings.Join(valueStrings, ",")), valueArgs...)
+		if err != nil {
+			return fmt.Errorf("error importando valores: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
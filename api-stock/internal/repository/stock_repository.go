@@ -9,6 +9,78 @@ import (
 	"time"
 )
 
+// recommendationColumns es la lista de columnas que se leen en cada consulta de recomendaciones.
+// Debe mantenerse en el mismo orden que los campos escaneados en scanRecommendation.
+const recommendationColumns = `id, source, ticker, target_from, target_to, currency, company, action, action_type,
+              brokerage, brokerage_id, rating_from, rating_to, normalized_rating_from, normalized_rating_to, time`
+
+// revisionColumns son las columnas de recommendation_revisions que copian una versión anterior,
+// en el orden de recommendationColumns.
+const revisionColumns = `recommendation_id, source, ticker, target_from, target_to, currency, company, action, action_type,
+              brokerage, brokerage_id, rating_from, rating_to, normalized_rating_from, normalized_rating_to, time`
+
+// recommendationColumnTypes son los tipos SQL de recommendationColumns, para los lotes que se comparan
+// con las filas guardadas antes de escribirlos.
+var recommendationColumnTypes = []string{
+	"STRING", "STRING", "STRING", "DECIMAL", "DECIMAL", "STRING", "STRING", "STRING", "STRING",
+	"STRING", "STRING", "STRING", "STRING", "STRING", "STRING", "TIMESTAMP",
+}
+
+// Tablas de recomendaciones: la que leen las consultas y la de staging donde se carga la sincronización completa.
+const (
+	recommendationsTable = "recommendations"
+	stagingTable         = "recommendations_staging"
+)
+
+// servedRecommendations es la tabla derivada que leen las consultas en lugar de recommendations: las filas
+// de cada proveedor salen de recommendations, salvo las de los proveedores con un intercambio en curso
+// (recommendation_swaps), que salen de staging. Así una consulta ve el dataset anterior completo o el nuevo
+// completo, nunca uno a medio publicar. Las consultas le dan un alias.
+const servedRecommendations = `(
+        SELECT ` + recommendationColumns + ` FROM ` + recommendationsTable + `
+        WHERE source NOT IN (SELECT source FROM recommendation_swaps)
+        UNION ALL
+        SELECT ` + recommendationColumns + ` FROM ` + stagingTable + `
+        WHERE source IN (SELECT source FROM recommendation_swaps)
+    )`
+
+// rowScanner abstrae *sql.Row y *sql.Rows para reutilizar el escaneo de filas.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRecommendation escanea una fila con las columnas de recommendationColumns.
+// Propaga la divisa de la fila a los precios objetivo; brokerage_id NULL se lee como cadena vacía.
+func scanRecommendation(row rowScanner) (domain.StockRecommendation, error) {
+	var rec domain.StockRecommendation
+	var brokerageID sql.NullString
+	err := row.Scan(
+		&rec.ID,
+		&rec.Source,
+		&rec.Ticker,
+		&rec.TargetFrom,
+		&rec.TargetTo,
+		&rec.Currency,
+		&rec.Company,
+		&rec.Action,
+		&rec.ActionType,
+		&rec.Brokerage,
+		&brokerageID,
+		&rec.RatingFrom,
+		&rec.RatingTo,
+		&rec.NormalizedRatingFrom,
+		&rec.NormalizedRatingTo,
+		&rec.Time,
+	)
+	if err != nil {
+		return rec, err
+	}
+	rec.TargetFrom.Currency = rec.Currency
+	rec.TargetTo.Currency = rec.Currency
+	rec.BrokerageID = brokerageID.String
+	return rec, nil
+}
+
 // stockRepository implementa la interfaz domain.StockRepository y maneja las operaciones con la base de datos.
 type stockRepository struct {
 	db *sql.DB // Conexión a la base de datos SQL
@@ -19,22 +91,22 @@ func NewStockRepository(db *sql.DB) domain.StockRepository {
 	return &stockRepository{db: db}
 }
 
-// GetRecommendations obtiene recomendaciones paginadas desde la base de datos, con filtro opcional por ticker.
-// Recibe el contexto para control de tiempo y cancelación, el ticker para filtrar, y los parámetros de paginación (página y límite).
+// GetRecommendations obtiene recomendaciones paginadas desde la base de datos, con filtros opcionales.
+// Recibe el contexto para control de tiempo y cancelación, los filtros (ticker, calificación), y los parámetros de paginación (página y límite).
 // Devuelve la lista de recomendaciones, el total de recomendaciones para la consulta, y un error si ocurre alguno.
-func (r *stockRepository) GetRecommendations(ctx context.Context, ticker string, page, limit int) ([]domain.StockRecommendation, int, error) {
+func (r *stockRepository) GetRecommendations(ctx context.Context, filter domain.RecommendationFilter, page, limit int) ([]domain.StockRecommendation, int, error) {
 	offset := (page - 1) * limit // Calcula el offset para paginación
 
-	// Consulta SQL que selecciona las recomendaciones, filtrando por ticker si se pasa (si ticker es cadena vacía, no filtra)
-	query := `SELECT ticker, target_from, target_to, company, action, 
-              brokerage, rating_from, rating_to, time
-              FROM recommendations
-              WHERE ($1 = '' OR ticker = $1)
-              ORDER BY time DESC
-              LIMIT $2 OFFSET $3`
+	// Consulta SQL que selecciona las recomendaciones aplicando solo los filtros informados
+	where, args := buildFilter(filter)
+	query := fmt.Sprintf(`SELECT `+recommendationColumns+`
+              FROM `+servedRecommendations+` AS recommendations
+              %s
+              ORDER BY time DESC, id
+              LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
 
 	// Ejecuta la consulta con los parámetros recibidos
-	rows, err := r.db.QueryContext(ctx, query, ticker, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("error in SQL query: %v", err)
 	}
@@ -44,18 +116,7 @@ func (r *stockRepository) GetRecommendations(ctx context.Context, ticker string,
 
 	// Itera las filas obtenidas y las escanea en structs StockRecommendation
 	for rows.Next() {
-		var rec domain.StockRecommendation
-		err := rows.Scan(
-			&rec.Ticker,
-			&rec.TargetFrom,
-			&rec.TargetTo,
-			&rec.Company,
-			&rec.Action,
-			&rec.Brokerage,
-			&rec.RatingFrom,
-			&rec.RatingTo,
-			&rec.Time,
-		)
+		rec, err := scanRecommendation(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("error scanning row: %v", err)
 		}
@@ -64,8 +125,8 @@ func (r *stockRepository) GetRecommendations(ctx context.Context, ticker string,
 
 	// Consulta para contar el total de recomendaciones que cumplen el filtro (para paginación)
 	var total int
-	countQuery := `SELECT COUNT(*) FROM recommendations WHERE ($1 = '' OR ticker = $1)`
-	err = r.db.QueryRowContext(ctx, countQuery, ticker).Scan(&total)
+	countQuery := `SELECT COUNT(*) FROM ` + servedRecommendations + ` AS recommendations ` + where
+	err = r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("error counting rows: %v", err)
 	}
@@ -74,10 +135,174 @@ func (r *stockRepository) GetRecommendations(ctx context.Context, ticker string,
 	return recommendations, total, nil
 }
 
+// buildFilter construye la cláusula WHERE y sus argumentos para un RecommendationFilter.
+// Los placeholders se numeran desde $1; el llamador añade los suyos a continuación.
+func buildFilter(filter domain.RecommendationFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Ticker != "" {
+		add("ticker = $%d", filter.Ticker)
+	}
+	if filter.Rating != "" {
+		add("normalized_rating_to = $%d", string(filter.Rating))
+	}
+	if filter.Sector != "" {
+		add("ticker IN (SELECT ticker FROM securities WHERE lower(sector) = lower($%d))", filter.Sector)
+	}
+	if filter.Source != "" {
+		add("source = $%d", filter.Source)
+	}
+	if filter.Brokerage != "" {
+		add("(brokerage_id = lower($%[1]d) OR lower(brokerage) = lower($%[1]d))", filter.Brokerage)
+	}
+	if !filter.From.IsZero() {
+		add("time >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("time < $%d", filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// exportFetchSize es el número de filas que se piden al cursor en cada FETCH.
+const exportFetchSize = 1000
+
+// StreamRecommendations recorre las recomendaciones filtradas con un cursor de servidor (DECLARE/FETCH)
+// dentro de una transacción de solo lectura: cada FETCH trae un bloque de filas sobre la misma
+// instantánea, sin el coste creciente de LIMIT/OFFSET ni cargar el resultado completo en memoria.
+func (r *stockRepository) StreamRecommendations(ctx context.Context, filter domain.RecommendationFilter, fn func(domain.StockRecommendation) error) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback() // Cierra el cursor si el recorrido se interrumpe
+
+	where, args := buildFilter(filter)
+	if _, err := tx.ExecContext(ctx, `DECLARE export_cursor CURSOR FOR
+              SELECT `+recommendationColumns+`
+              FROM `+servedRecommendations+` AS recommendations
+              `+where+`
+              ORDER BY time DESC, id`, args...); err != nil {
+		return fmt.Errorf("error abriendo cursor de exportación: %v", err)
+	}
+
+	fetch := fmt.Sprintf(`FETCH %d FROM export_cursor`, exportFetchSize)
+	for {
+		fetched, err := r.fetchBlock(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < exportFetchSize {
+			break // Último bloque
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `CLOSE export_cursor`); err != nil {
+		return fmt.Errorf("error cerrando cursor de exportación: %v", err)
+	}
+	return tx.Commit()
+}
+
+// fetchBlock lee un bloque del cursor y entrega cada fila a fn. Devuelve el número de filas leídas.
+func (r *stockRepository) fetchBlock(ctx context.Context, tx *sql.Tx, fetch string, fn func(domain.StockRecommendation) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, fmt.Errorf("error leyendo cursor de exportación: %v", err)
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		rec, err := scanRecommendation(rows)
+		if err != nil {
+			return fetched, fmt.Errorf("error scanning row: %v", err)
+		}
+		fetched++
+		if err := fn(rec); err != nil {
+			return fetched, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fetched, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return fetched, nil
+}
+
+// GetRecommendation obtiene una recomendación por su ID, o domain.ErrRecommendationNotFound.
+func (r *stockRepository) GetRecommendation(ctx context.Context, id string) (*domain.StockRecommendation, error) {
+	query := `SELECT ` + recommendationColumns + `
+              FROM ` + servedRecommendations + ` AS recommendations
+              WHERE id = $1`
+
+	rec, err := scanRecommendation(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrRecommendationNotFound
+		}
+		return nil, fmt.Errorf("error escaneando recomendación: %v", err)
+	}
+	return &rec, nil
+}
+
+// GetRecommendationRevisions devuelve las versiones anteriores de una recomendación, la más reciente primero.
+func (r *stockRepository) GetRecommendationRevisions(ctx context.Context, id string) ([]domain.RecommendationRevision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT `+revisionColumns+`, id, sync_run_id, correction, revised_at
+        FROM recommendation_revisions
+        WHERE recommendation_id = $1
+        ORDER BY revised_at DESC, id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando revisiones: %v", err)
+	}
+	defer rows.Close()
+
+	revisions := []domain.RecommendationRevision{}
+	for rows.Next() {
+		var rev domain.RecommendationRevision
+		var syncRunID sql.NullString
+		rec, err := scanRecommendation(withColumns(rows, &rev.ID, &syncRunID, &rev.Correction, &rev.RevisedAt))
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando revisión: %v", err)
+		}
+		rev.Recommendation = rec
+		rev.SyncRunID = syncRunID.String
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return revisions, nil
+}
+
+// extraColumns permite escanear con scanRecommendation filas que traen columnas adicionales al final.
+type extraColumns struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (e extraColumns) Scan(dest ...interface{}) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// withColumns envuelve la fila para que las columnas que siguen a recommendationColumns se escaneen en extra.
+func withColumns(row rowScanner, extra ...interface{}) rowScanner {
+	return extraColumns{row: row, extra: extra}
+}
+
 // GetAvailableTickers devuelve una lista con los tickers distintos existentes en la tabla de recomendaciones.
-// Se usa para conocer qué símbolos están disponibles para filtrar o mostrar.
+// Se usa para calcular características; no depende del registro maestro de valores, así que un ticker sin
+// fila activa en securities sigue puntuándose. El listado público de tickers usa ListSecurities.
 func (r *stockRepository) GetAvailableTickers(ctx context.Context) ([]string, error) {
-	query := `SELECT DISTINCT ticker FROM recommendations ORDER BY ticker`
+	query := `SELECT DISTINCT ticker FROM ` + servedRecommendations + ` AS recommendations ORDER BY ticker`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -105,65 +330,341 @@ func (r *stockRepository) GetAvailableTickers(ctx context.Context) ([]string, er
 
 // InsertRecommendations inserta un lote (bulk insert) de recomendaciones en la base de datos.
 // Usa transacciones para asegurar que todas las inserciones ocurran juntas.
-// En caso de conflicto (ticker y time duplicados), actualiza los datos existentes.
-func (r *stockRepository) InsertRecommendations(ctx context.Context, recommendations []domain.StockRecommendation) error {
+// La identidad de cada recomendación es su ID estable (proveedor, ticker, firma, momento y acción): si ya existe,
+// se actualizan sus datos; si el lote la repite, prevalece la última aparición. La versión anterior de
+// cada fila que cambia se guarda en recommendation_revisions con la ejecución indicada.
+func (r *stockRepository) InsertRecommendations(ctx context.Context, recommendations []domain.StockRecommendation, syncRunID string) (domain.WriteStats, error) {
+	if err := r.finishPendingSwap(ctx, batchSources(recommendations)...); err != nil {
+		return domain.WriteStats{}, err
+	}
+	return r.insertInto(ctx, recommendationsTable, recommendations, syncRunID)
+}
+
+// InsertStagingRecommendations inserta un lote en la tabla de staging de la sincronización completa,
+// con la misma semántica de identidad que InsertRecommendations.
+func (r *stockRepository) InsertStagingRecommendations(ctx context.Context, recommendations []domain.StockRecommendation) error {
+	if err := r.finishPendingSwap(ctx, batchSources(recommendations)...); err != nil {
+		return err
+	}
+	_, err := r.insertInto(ctx, stagingTable, recommendations, "")
+	return err
+}
+
+// insertInto hace el bulk insert de un lote en la tabla indicada (recommendations o su staging)
+// y, en recommendations, registra los tickers en el maestro de valores, todo en una transacción. Las filas existentes
+// solo se reescriben si algún campo cambió, de modo que el resultado distingue nuevas, actualizadas
+// y omitidas (repetidas en el lote o sin cambios). En recommendations, las versiones que se sobrescriben
+// se guardan antes como revisiones; staging no guarda historial. Con un lease en el contexto, la
+// transacción comprueba antes de escribir que sigue siendo de este trabajo.
+func (r *stockRepository) insertInto(ctx context.Context, table string, recommendations []domain.StockRecommendation, syncRunID string) (domain.WriteStats, error) {
+	received := len(recommendations)
+	recommendations = dedupeByID(recommendations)
 	if len(recommendations) == 0 {
-		return nil // No hay nada que insertar
+		return domain.WriteStats{}, nil // No hay nada que insertar
 	}
 
 	// Inicia una transacción
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error al iniciar transacción: %v", err)
+		return domain.WriteStats{}, fmt.Errorf("error al iniciar transacción: %v", err)
 	}
 	defer tx.Rollback() // Rollback automático en caso de error
 
+	// Con un lease en el contexto, escribe solo si sigue siendo de este trabajo
+	if err := checkLeaseFence(ctx, tx); err != nil {
+		return domain.WriteStats{}, err
+	}
+
 	// Prepara la consulta dinámica con los placeholders y los valores a insertar
 	valueStrings := make([]string, 0, len(recommendations))
-	valueArgs := make([]interface{}, 0, len(recommendations)*9) // 9 columnas por fila
+	valueArgs := make([]interface{}, 0, len(recommendations)*insertColumnCount)
+	idPlaceholders := make([]string, 0, len(recommendations))
+	idArgs := make([]interface{}, 0, len(recommendations))
 
 	for i, rec := range recommendations {
-		// Crea una parte de la query con placeholders ($1, $2, ... $9)
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-			i*9+1, i*9+2, i*9+3, i*9+4, i*9+5, i*9+6, i*9+7, i*9+8, i*9+9))
+		// Crea una parte de la query con placeholders ($1, $2, ...) para esta fila
+		valueStrings = append(valueStrings, rowPlaceholders(i, insertColumnCount))
 
 		// Agrega los valores en orden para cada fila
-		valueArgs = append(valueArgs, rec.Ticker, rec.TargetFrom, rec.TargetTo,
-			rec.Company, rec.Action, rec.Brokerage, rec.RatingFrom, rec.RatingTo, rec.Time)
+		valueArgs = append(valueArgs, rec.ID, sourceOrDefault(rec.Source), rec.Ticker, rec.TargetFrom, rec.TargetTo, rec.PriceCurrency(),
+			rec.Company, rec.Action, string(rec.ActionType.OrUnknown()), rec.Brokerage, nullString(rec.BrokerageID), rec.RatingFrom, rec.RatingTo,
+			string(rec.NormalizedRatingFrom.OrUnknown()), string(rec.NormalizedRatingTo.OrUnknown()), rec.Time)
+
+		idPlaceholders = append(idPlaceholders, fmt.Sprintf("$%d", i+1))
+		idArgs = append(idArgs, rec.ID)
+	}
+
+	// Cuenta cuántas filas del lote ya existían para separar las nuevas de las actualizadas
+	var existing int
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE id IN (%s)`,
+		table, strings.Join(idPlaceholders, ", ")), idArgs...).Scan(&existing); err != nil {
+		return domain.WriteStats{}, fmt.Errorf("error contando filas existentes: %v", err)
+	}
+
+	// Guarda la versión anterior de las filas que el lote va a cambiar, antes de sobrescribirlas
+	corrected := 0
+	if table == recommendationsTable {
+		if corrected, err = saveRevisions(ctx, tx, len(recommendations), valueArgs, syncRunID); err != nil {
+			return domain.WriteStats{}, err
+		}
 	}
 
-	// Construye la consulta SQL con ON CONFLICT para actualizar filas en caso de duplicados (ticker, time)
+	// Construye la consulta SQL con ON CONFLICT para actualizar filas ya existentes con el mismo ID
+	// cuando alguno de sus campos cambió
 	stmt := fmt.Sprintf(`
-        INSERT INTO recommendations (
-            ticker, target_from, target_to, company, action, 
-            brokerage, rating_from, rating_to, time
-        ) VALUES %s
-        ON CONFLICT (ticker, time) DO UPDATE SET
-            target_from = EXCLUDED.target_from,
-            target_to = EXCLUDED.target_to,
-            company = EXCLUDED.company,
-            action = EXCLUDED.action,
-            brokerage = EXCLUDED.brokerage,
-            rating_from = EXCLUDED.rating_from,
-            rating_to = EXCLUDED.rating_to`,
-		strings.Join(valueStrings, ","))
+        INSERT INTO %s (`+recommendationColumns+`) VALUES %s
+        ON CONFLICT (id) DO UPDATE SET %s
+        WHERE %s`,
+		table, strings.Join(valueStrings, ","), updateAssignments(), changedCondition(table, "EXCLUDED"))
 
 	// Ejecuta la consulta con todos los valores
-	_, err = tx.ExecContext(ctx, stmt, valueArgs...)
+	result, err := tx.ExecContext(ctx, stmt, valueArgs...)
 	if err != nil {
-		return fmt.Errorf("error en bulk insert: %v", err)
+		return domain.WriteStats{}, fmt.Errorf("error en bulk insert: %v", err)
+	}
+	written, err := result.RowsAffected()
+	if err != nil {
+		return domain.WriteStats{}, fmt.Errorf("error en bulk insert: %v", err)
+	}
+
+	// Registra en el maestro de valores los tickers nuevos del lote. Los de staging se registran al
+	// publicarse en SwapStaging: una carga que no llega a intercambiarse no debe dar de alta valores.
+	if table == recommendationsTable {
+		if err := registerSecurities(ctx, tx, recommendations); err != nil {
+			return domain.WriteStats{}, err
+		}
 	}
 
 	// Hace commit si todo fue exitoso
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return domain.WriteStats{}, err
+	}
+
+	inserted := len(recommendations) - existing
+	updated := int(written) - inserted
+	return domain.WriteStats{Inserted: inserted, Updated: updated, Corrected: corrected, Skipped: received - inserted - updated}, nil
+}
+
+// saveRevisions copia a recommendation_revisions las filas de recommendations que difieren del lote
+// (rows filas con los valores de valueArgs en el orden de recommendationColumns) y devuelve cuántas
+// de ellas son correcciones del proveedor.
+func saveRevisions(ctx context.Context, tx *sql.Tx, rows int, valueArgs []interface{}, syncRunID string) (int, error) {
+	valueStrings := make([]string, rows)
+	for i := range valueStrings {
+		valueStrings[i] = typedRowPlaceholders(i, recommendationColumnTypes)
+	}
+	args := append(valueArgs[:len(valueArgs):len(valueArgs)], nullString(syncRunID))
+
+	result, err := tx.QueryContext(ctx, fmt.Sprintf(`
+        INSERT INTO recommendation_revisions (`+revisionColumns+`, correction, sync_run_id)
+        SELECT %s, %s, $%d::UUID
+        FROM `+recommendationsTable+` AS r
+        JOIN (VALUES %s) AS v (`+recommendationColumns+`) ON v.id = r.id
+        WHERE %s
+        RETURNING correction`,
+		qualifiedColumns("r", recommendationColumns), correctedCondition("r", "v"), len(args),
+		strings.Join(valueStrings, ","), changedCondition("r", "v")), args...)
+	if err != nil {
+		return 0, fmt.Errorf("error guardando revisiones: %v", err)
+	}
+	defer result.Close()
+
+	corrected := 0
+	for result.Next() {
+		var correction bool
+		if err := result.Scan(&correction); err != nil {
+			return 0, fmt.Errorf("error guardando revisiones: %v", err)
+		}
+		if correction {
+			corrected++
+		}
+	}
+	if err := result.Err(); err != nil {
+		return 0, fmt.Errorf("error guardando revisiones: %v", err)
+	}
+	return corrected, nil
+}
+
+// updatableColumns son las columnas que una nueva versión de la misma recomendación puede cambiar;
+// ticker y time forman parte de la identidad.
+var updatableColumns = []string{
+	"target_from", "target_to", "currency", "company", "action", "action_type", "brokerage", "brokerage_id",
+	"rating_from", "rating_to", "normalized_rating_from", "normalized_rating_to",
+}
+
+// providerColumns son las columnas actualizables que vienen del proveedor; el resto se deriva de ellas
+// con la normalización. Un cambio en alguna de ellas es una corrección del proveedor.
+var providerColumns = []string{
+	"target_from", "target_to", "currency", "company", "action", "brokerage", "rating_from", "rating_to",
+}
+
+// updateAssignments genera "col = EXCLUDED.col, ..." para las columnas actualizables.
+func updateAssignments() string {
+	assignments := make([]string, len(updatableColumns))
+	for i, col := range updatableColumns {
+		assignments[i] = fmt.Sprintf("%s = EXCLUDED.%s", col, col)
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// changedCondition genera la condición "alguna columna actualizable difiere" entre dos alias de fila.
+func changedCondition(current, incoming string) string {
+	conditions := make([]string, len(updatableColumns))
+	for i, col := range updatableColumns {
+		conditions[i] = fmt.Sprintf("%s.%s IS DISTINCT FROM %s.%s", current, col, incoming, col)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// correctedCondition genera la condición "alguna columna del proveedor difiere" entre dos alias de fila.
+func correctedCondition(current, incoming string) string {
+	conditions := make([]string, len(providerColumns))
+	for i, col := range providerColumns {
+		conditions[i] = fmt.Sprintf("%s.%s IS DISTINCT FROM %s.%s", current, col, incoming, col)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// qualifiedColumns antepone el alias de tabla a cada columna de una lista separada por comas.
+func qualifiedColumns(alias, columns string) string {
+	names := strings.Split(columns, ",")
+	for i, name := range names {
+		names[i] = alias + "." + strings.TrimSpace(name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// dedupeByID asigna el ID estable a las recomendaciones que no lo tienen y elimina las repetidas
+// dentro del lote (conservando la última aparición en la posición de la primera), ya que un mismo
+// INSERT ... ON CONFLICT no puede modificar dos veces la misma fila.
+func dedupeByID(recommendations []domain.StockRecommendation) []domain.StockRecommendation {
+	index := make(map[string]int, len(recommendations))
+	unique := make([]domain.StockRecommendation, 0, len(recommendations))
+	for _, rec := range recommendations {
+		if rec.ID == "" {
+			rec.ID = rec.IdentityID()
+		}
+		if i, ok := index[rec.ID]; ok {
+			unique[i] = rec
+			continue
+		}
+		index[rec.ID] = len(unique)
+		unique = append(unique, rec)
+	}
+	return unique
+}
+
+// registerSecurities da de alta en securities los tickers del lote que aún no existen, con el nombre
+// de empresa recibido. Los metadatos importados por CSV no se sobrescriben; solo se completa un nombre vacío.
+func registerSecurities(ctx context.Context, tx *sql.Tx, recommendations []domain.StockRecommendation) error {
+	companies := make(map[string]string)
+	var tickers []string
+	for _, rec := range recommendations {
+		if _, seen := companies[rec.Ticker]; !seen {
+			tickers = append(tickers, rec.Ticker)
+		}
+		if rec.Company != "" || companies[rec.Ticker] == "" {
+			companies[rec.Ticker] = rec.Company
+		}
+	}
+
+	valueStrings := make([]string, 0, len(tickers))
+	valueArgs := make([]interface{}, 0, len(tickers)*2)
+	for i, ticker := range tickers {
+		valueStrings = append(valueStrings, rowPlaceholders(i, 2))
+		valueArgs = append(valueArgs, ticker, companies[ticker])
+	}
+
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+        INSERT INTO securities (ticker, company_name) VALUES %s
+        ON CONFLICT (ticker) DO UPDATE SET
+            company_name = EXCLUDED.company_name,
+            updated_at = now()
+        WHERE securities.company_name = '' AND EXCLUDED.company_name <> ''`,
+		strings.Join(valueStrings, ",")), valueArgs...)
+	if err != nil {
+		return fmt.Errorf("error registrando valores: %v", err)
+	}
+	return nil
+}
+
+// registerStagedSecurities da de alta los tickers de staging del proveedor ($1) que aún no existen en
+// securities, con las mismas reglas que registerSecurities; para cada ticker se usa el nombre de empresa
+// no vacío más reciente.
+const registerStagedSecurities = `
+        INSERT INTO securities (ticker, company_name)
+        SELECT DISTINCT ON (ticker) ticker, COALESCE(company, '')
+        FROM ` + stagingTable + `
+        WHERE source = $1
+        ORDER BY ticker, COALESCE(company, '') = '', time DESC
+        ON CONFLICT (ticker) DO UPDATE SET
+            company_name = EXCLUDED.company_name,
+            updated_at = now()
+        WHERE securities.company_name = '' AND EXCLUDED.company_name <> ''`
+
+// insertColumnCount es el número de columnas que InsertRecommendations escribe por fila.
+const insertColumnCount = 16
+
+// sourceOrDefault devuelve el proveedor de la recomendación, o domain.DefaultSource si no se indicó.
+func sourceOrDefault(source string) string {
+	if source == "" {
+		return domain.DefaultSource
+	}
+	return source
+}
+
+// nullString convierte una cadena vacía en NULL para columnas opcionales.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// purgeBatchSize es el máximo de filas que borra cada sentencia de deleteInBatches.
+const purgeBatchSize = 1000
+
+// deleteInBatches ejecuta un DELETE con "LIMIT" al final (completado con purgeBatchSize) hasta que no
+// borra nada, para no abrir una transacción enorme al purgar historial. Devuelve el total de filas borradas.
+func deleteInBatches(ctx context.Context, db *sql.DB, query string, args ...interface{}) (int64, error) {
+	var total int64
+	for {
+		res, err := db.ExecContext(ctx, fmt.Sprintf("%s LIMIT %d", query, purgeBatchSize), args...)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// typedRowPlaceholders genera los placeholders de la fila `row` con el tipo de cada columna
+// ("($n::STRING, ...)"), necesarios cuando los valores no van a una tabla que fije su tipo.
+func typedRowPlaceholders(row int, types []string) string {
+	placeholders := make([]string, len(types))
+	for c, typ := range types {
+		placeholders[c] = fmt.Sprintf("$%d::%s", row*len(types)+c+1, typ)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
+
+// rowPlaceholders genera los placeholders "($n, $n+1, ...)" de la fila `row` en un insert con `columns` columnas.
+func rowPlaceholders(row, columns int) string {
+	placeholders := make([]string, columns)
+	for c := 0; c < columns; c++ {
+		placeholders[c] = fmt.Sprintf("$%d", row*columns+c+1)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
 }
 
 // GetRecentRecommendations obtiene recomendaciones con fecha mayor a un intervalo de tiempo dado (desde ahora menos el intervalo).
 // Útil para obtener recomendaciones recientes.
 func (r *stockRepository) GetRecentRecommendations(ctx context.Context, since time.Duration) ([]domain.StockRecommendation, error) {
-	query := `SELECT ticker, target_from, target_to, company, action, 
-              brokerage, rating_from, rating_to, time
-              FROM recommendations
+	query := `SELECT ` + recommendationColumns + `
+              FROM ` + servedRecommendations + ` AS recommendations
               WHERE time > $1
               ORDER BY time DESC`
 
@@ -178,18 +679,7 @@ func (r *stockRepository) GetRecentRecommendations(ctx context.Context, since ti
 
 	// Escanea cada fila en una recomendación
 	for rows.Next() {
-		var rec domain.StockRecommendation
-		err := rows.Scan(
-			&rec.Ticker,
-			&rec.TargetFrom,
-			&rec.TargetTo,
-			&rec.Company,
-			&rec.Action,
-			&rec.Brokerage,
-			&rec.RatingFrom,
-			&rec.RatingTo,
-			&rec.Time,
-		)
+		rec, err := scanRecommendation(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error escaneando fila: %v", err)
 		}
@@ -199,32 +689,19 @@ func (r *stockRepository) GetRecentRecommendations(ctx context.Context, since ti
 	return recommendations, nil
 }
 
-// GetLatestRecommendation obtiene la recomendación más reciente ordenada por fecha descendente.
+// GetLatestRecommendation obtiene la recomendación más reciente del proveedor ordenada por fecha descendente.
 // Retorna nil si no hay recomendaciones.
-func (r *stockRepository) GetLatestRecommendation(ctx context.Context) (*domain.StockRecommendation, error) {
-	query := `SELECT 
-        ticker, target_from, target_to, company, action, 
-        brokerage, rating_from, rating_to, time
-        FROM recommendations
+func (r *stockRepository) GetLatestRecommendation(ctx context.Context, source string) (*domain.StockRecommendation, error) {
+	query := `SELECT ` + recommendationColumns + `
+        FROM ` + servedRecommendations + ` AS recommendations
+        WHERE source = $1
         ORDER BY time DESC
         LIMIT 1`
 
-	row := r.db.QueryRowContext(ctx, query)
-
-	var rec domain.StockRecommendation
+	row := r.db.QueryRowContext(ctx, query, source)
 
 	// Escanea la fila resultante
-	err := row.Scan(
-		&rec.Ticker,
-		&rec.TargetFrom,
-		&rec.TargetTo,
-		&rec.Company,
-		&rec.Action,
-		&rec.Brokerage,
-		&rec.RatingFrom,
-		&rec.RatingTo,
-		&rec.Time,
-	)
+	rec, err := scanRecommendation(row)
 
 	// Manejo de errores
 	if err != nil {
@@ -237,40 +714,282 @@ func (r *stockRepository) GetLatestRecommendation(ctx context.Context) (*domain.
 	return &rec, nil
 }
 
-// DeleteAllRecommendations elimina todas las recomendaciones de la tabla.
-func (r *stockRepository) DeleteAllRecommendations(ctx context.Context) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM recommendations")
+// ResetStaging vacía las filas de staging del proveedor, por lotes para no abrir una transacción enorme.
+// Se usa antes de cargar una sincronización completa y después de publicarla; si el proveedor tiene un
+// intercambio a medias, primero lo termina, porque las consultas aún leen su staging.
+func (r *stockRepository) ResetStaging(ctx context.Context, source string) error {
+	if err := r.finishPendingSwap(ctx, source); err != nil {
+		return err
+	}
+	if err := r.deleteFenced(ctx, "DELETE FROM "+stagingTable+" WHERE source = $1", source); err != nil {
+		return fmt.Errorf("error vaciando staging: %w", err)
+	}
+	return nil
+}
+
+// CountRecommendations devuelve el número de filas del proveedor en recommendations.
+func (r *stockRepository) CountRecommendations(ctx context.Context, source string) (int, error) {
+	return r.count(ctx, recommendationsTable, source)
+}
+
+// CountStagingRecommendations devuelve el número de filas del proveedor cargadas en staging.
+func (r *stockRepository) CountStagingRecommendations(ctx context.Context, source string) (int, error) {
+	return r.count(ctx, stagingTable, source)
+}
+
+// count devuelve el número de filas de un proveedor en una tabla de recomendaciones.
+func (r *stockRepository) count(ctx context.Context, table, source string) (int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+" WHERE source = $1", source).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error contando filas de %s: %v", table, err)
+	}
+	return total, nil
+}
+
+// swapBatchSize es el número de filas de staging que publishStaging publica por transacción.
+const swapBatchSize = 1000
+
+// SwapStaging reemplaza las recomendaciones del proveedor por sus filas de staging. Los demás proveedores
+// no se tocan. Antes del intercambio compara ambas tablas para informar filas nuevas, cambiadas, corregidas,
+// sin cambios y eliminadas.
+//
+// El cambio es atómico para los lectores: primero se registra el intercambio en recommendation_swaps, con lo
+// que las consultas (servedRecommendations) pasan a leer las filas del proveedor de staging, que ya tiene
+// el dataset nuevo completo. Después se publica staging en recommendations por lotes (publishStaging), porque
+// una sola transacción con todo el dataset superaría el límite de tamaño de CockroachDB, y al terminar se
+// borra el registro y las consultas vuelven a recommendations, que ya tiene el mismo contenido. Con un lease
+// en el contexto, cada transacción comprueba antes de escribir que sigue siendo de este trabajo.
+//
+// Si falla a medias, las consultas siguen leyendo staging, y volver a llamarlo (o cualquier escritura del
+// proveedor, ver finishPendingSwap) termina el intercambio: publicar una fila sin cambios no la escribe.
+// staging no se vacía aquí (ver ResetStaging).
+func (r *stockRepository) SwapStaging(ctx context.Context, source, syncRunID string) (domain.WriteStats, error) {
+	var stats domain.WriteStats
+	var staged int
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`
+        SELECT
+            (SELECT COUNT(*) FROM %[1]s WHERE source = $1),
+            (SELECT COUNT(*) FROM %[1]s AS s WHERE s.source = $1 AND NOT EXISTS (SELECT 1 FROM %[2]s AS r WHERE r.id = s.id)),
+            (SELECT COUNT(*) FROM %[1]s AS s JOIN %[2]s AS r ON r.id = s.id WHERE s.source = $1 AND %[3]s),
+            (SELECT COUNT(*) FROM %[1]s AS s JOIN %[2]s AS r ON r.id = s.id WHERE s.source = $1 AND %[4]s),
+            (SELECT COUNT(*) FROM %[2]s AS r WHERE r.source = $1 AND NOT EXISTS (SELECT 1 FROM %[1]s AS s WHERE s.source = $1 AND s.id = r.id))`,
+		stagingTable, recommendationsTable, changedCondition("r", "s"), correctedCondition("r", "s")), source).
+		Scan(&staged, &stats.Inserted, &stats.Updated, &stats.Corrected, &stats.Deleted)
+	if err != nil {
+		return domain.WriteStats{}, fmt.Errorf("error comparando staging: %v", err)
+	}
+	stats.Skipped = staged - stats.Inserted - stats.Updated
+
+	// Los valores se registran antes de que se lean sus filas
+	if _, err := r.db.ExecContext(ctx, registerStagedSecurities, source); err != nil {
+		return domain.WriteStats{}, fmt.Errorf("error registrando valores: %v", err)
+	}
+
+	// Desde aquí las consultas leen el proveedor de staging
+	err = r.execFenced(ctx, `INSERT INTO recommendation_swaps (source, sync_run_id) VALUES ($1, $2)
+        ON CONFLICT (source) DO NOTHING`, source, nullString(syncRunID))
 	if err != nil {
-		return fmt.Errorf("error eliminando recomendaciones: %v", err)
+		return domain.WriteStats{}, fmt.Errorf("error registrando intercambio: %w", err)
+	}
+
+	if err := r.publishStaging(ctx, source, syncRunID); err != nil {
+		return domain.WriteStats{}, err
+	}
+	return stats, nil
+}
+
+// publishStaging copia por lotes las filas de staging del proveedor a recommendations, borra las que ya no
+// están en staging y termina el intercambio registrado en recommendation_swaps, con lo que las consultas
+// vuelven a leer recommendations.
+func (r *stockRepository) publishStaging(ctx context.Context, source, syncRunID string) error {
+	after := ""
+	for {
+		last, err := r.publishStagedBatch(ctx, source, syncRunID, after)
+		if err != nil {
+			return err
+		}
+		if last == "" {
+			break
+		}
+		after = last
+	}
+
+	err := r.deleteFenced(ctx, fmt.Sprintf(`DELETE FROM %[1]s
+        WHERE source = $1 AND NOT EXISTS (SELECT 1 FROM %[2]s AS s WHERE s.source = $1 AND s.id = %[1]s.id)`,
+		recommendationsTable, stagingTable), source)
+	if err != nil {
+		return fmt.Errorf("error borrando filas que ya no están en staging: %w", err)
+	}
+
+	if err := r.execFenced(ctx, `DELETE FROM recommendation_swaps WHERE source = $1`, source); err != nil {
+		return fmt.Errorf("error terminando intercambio: %w", err)
+	}
+	return nil
+}
+
+// finishPendingSwap termina el intercambio de staging de los proveedores indicados que quedó a medias
+// (su fila en recommendation_swaps sigue ahí). Se llama antes de escribir recomendaciones o staging de un
+// proveedor, porque mientras dura el intercambio las consultas leen su staging y no deben ver esas escrituras
+// ni un staging a medio cargar.
+func (r *stockRepository) finishPendingSwap(ctx context.Context, sources ...string) error {
+	for _, source := range sources {
+		var syncRunID sql.NullString
+		err := r.db.QueryRowContext(ctx, `SELECT sync_run_id::STRING FROM recommendation_swaps WHERE source = $1`, source).
+			Scan(&syncRunID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error consultando intercambios pendientes: %v", err)
+		}
+		if err := r.publishStaging(ctx, source, syncRunID.String); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// batchSources devuelve los proveedores distintos de un lote de recomendaciones.
+func batchSources(recommendations []domain.StockRecommendation) []string {
+	seen := make(map[string]bool)
+	var sources []string
+	for _, rec := range recommendations {
+		source := sourceOrDefault(rec.Source)
+		if !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// publishStagedBatch publica en una transacción las siguientes swapBatchSize filas de staging del proveedor
+// con ID mayor que after: guarda como revisiones la versión anterior de las que cambian y las escribe en
+// recommendations. Devuelve el último ID del lote, o "" si no quedaban filas.
+func (r *stockRepository) publishStagedBatch(ctx context.Context, source, syncRunID, after string) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback() // Rollback automático en caso de error
+
+	if err := checkLeaseFence(ctx, tx); err != nil {
+		return "", err
+	}
+
+	var last sql.NullString
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT max(id) FROM (
+            SELECT id FROM %s WHERE source = $1 AND id > $2 ORDER BY id LIMIT $3
+        ) AS batch`, stagingTable), source, after, swapBatchSize).Scan(&last)
+	if err != nil {
+		return "", fmt.Errorf("error leyendo staging: %v", err)
+	}
+	if !last.Valid {
+		return "", nil
+	}
+
+	// Filas de staging del lote: source = $1 y $2 < id <= $3
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+        INSERT INTO recommendation_revisions (`+revisionColumns+`, correction, sync_run_id)
+        SELECT %s, %s, $4::UUID
+        FROM %s AS s JOIN %s AS r ON r.id = s.id
+        WHERE s.source = $1 AND s.id > $2 AND s.id <= $3 AND %s`,
+		qualifiedColumns("r", recommendationColumns), correctedCondition("r", "s"),
+		stagingTable, recommendationsTable, changedCondition("r", "s")), source, after, last.String, nullString(syncRunID)); err != nil {
+		return "", fmt.Errorf("error guardando revisiones: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+        INSERT INTO %s (`+recommendationColumns+`)
+        SELECT `+recommendationColumns+` FROM %s WHERE source = $1 AND id > $2 AND id <= $3
+        ON CONFLICT (id) DO UPDATE SET %s
+        WHERE %s`,
+		recommendationsTable, stagingTable, updateAssignments(), changedCondition(recommendationsTable, "EXCLUDED")),
+		source, after, last.String); err != nil {
+		return "", fmt.Errorf("error intercambiando staging: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return last.String, nil
+}
+
+// deleteFenced ejecuta un DELETE por lotes como deleteInBatches, pero cada lote en su transacción y, con
+// un lease en el contexto, solo mientras siga siendo de este trabajo.
+func (r *stockRepository) deleteFenced(ctx context.Context, query string, args ...interface{}) error {
+	for {
+		deleted, err := r.deleteFencedBatch(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if deleted < purgeBatchSize {
+			return nil
+		}
+	}
+}
+
+// execFenced ejecuta una sentencia en su transacción y, con un lease en el contexto, solo mientras siga
+// siendo de este trabajo.
+func (r *stockRepository) execFenced(ctx context.Context, query string, args ...interface{}) error {
+	return inFencedTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
+
+// deleteFencedBatch borra en una transacción un lote de deleteFenced y devuelve cuántas filas borró.
+func (r *stockRepository) deleteFencedBatch(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback() // Rollback automático en caso de error
+
+	if err := checkLeaseFence(ctx, tx); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, fmt.Sprintf("%s LIMIT %d", query, purgeBatchSize), args...)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
 // GetStockFeatures calcula características agregadas de las recomendaciones para un ticker dado.
 // Devuelve un mapa con métricas numéricas para análisis o modelo de recomendaciones.
 func (r *stockRepository) GetStockFeatures(ctx context.Context, ticker string) (map[string]float64, error) {
 	query := `
     SELECT 
         COUNT(*) as total, -- Total de recomendaciones para el ticker
-        AVG(CAST(REPLACE(target_to, '$', '') AS FLOAT8) - CAST(REPLACE(target_from, '$', '') AS FLOAT8)) as target_range, -- Promedio del rango objetivo (diferencia target_to - target_from)
-        AVG(CASE WHEN action LIKE '%aumentado%' THEN 1 ELSE 0 END) as upgrade_prob, -- Probabilidad promedio de recomendaciones con acción "aumentado"
-        AVG(CASE WHEN action LIKE '%bajado%' THEN 1 ELSE 0 END) as downgrade_prob, -- Probabilidad promedio de recomendaciones con acción "bajado"
-        AVG(CASE WHEN rating_to LIKE '%comprar%' THEN 1 ELSE 0 END) as buy_rating, -- Promedio de recomendaciones con rating para "comprar"
-        AVG(CASE WHEN rating_to LIKE '%vender%' THEN 1 ELSE 0 END) as sell_rating, -- Promedio de recomendaciones con rating para "vender"
-        STDDEV(CAST(REPLACE(target_to, '$', '') AS FLOAT8) - CAST(REPLACE(target_from, '$', '') AS FLOAT8)) as target_volatility, -- Volatilidad (desviación estándar) del rango objetivo
-        COUNT(DISTINCT brokerage) as unique_brokers -- Número de brokers distintos que han hecho recomendaciones
-    FROM recommendations
+        AVG(target_to - target_from)::FLOAT8 as target_range, -- Promedio del rango objetivo (diferencia target_to - target_from)
+        AVG(CASE WHEN action_type = 'upgrade' THEN 1 ELSE 0 END) as upgrade_prob, -- Proporción de mejoras de calificación
+        AVG(CASE WHEN action_type = 'downgrade' THEN 1 ELSE 0 END) as downgrade_prob, -- Proporción de rebajas de calificación
+        AVG(CASE WHEN action_type = 'target_raised' THEN 1 ELSE 0 END) as target_raised_prob, -- Proporción de subidas de precio objetivo
+        AVG(CASE WHEN action_type = 'target_lowered' THEN 1 ELSE 0 END) as target_lowered_prob, -- Proporción de bajadas de precio objetivo
+        AVG(CASE WHEN normalized_rating_to IN ('buy', 'strong_buy') THEN 1 ELSE 0 END) as buy_rating, -- Proporción de calificaciones de compra
+        AVG(CASE WHEN normalized_rating_to IN ('sell', 'strong_sell') THEN 1 ELSE 0 END) as sell_rating, -- Proporción de calificaciones de venta
+        STDDEV(target_to - target_from)::FLOAT8 as target_volatility, -- Volatilidad (desviación estándar) del rango objetivo
+        COUNT(DISTINCT COALESCE(brokerage_id, brokerage)) as unique_brokers -- Número de brokers distintos (por ID canónico si se resolvió)
+    FROM ` + servedRecommendations + ` AS recommendations
     WHERE ticker = $1`
 
 	row := r.db.QueryRowContext(ctx, query, ticker)
 
 	features := make(map[string]float64)
 	var total int
-	var targetRange, upgradeProb, downgradeProb, buyRating, sellRating, targetVolatility *float64
+	var targetRange, upgradeProb, downgradeProb, targetRaisedProb, targetLoweredProb, buyRating, sellRating, targetVolatility *float64
 	var uniqueBrokers int
 
 	// Escanea los resultados en variables
-	err := row.Scan(&total, &targetRange, &upgradeProb, &downgradeProb, &buyRating, &sellRating, &targetVolatility, &uniqueBrokers)
+	err := row.Scan(&total, &targetRange, &upgradeProb, &downgradeProb, &targetRaisedProb, &targetLoweredProb, &buyRating, &sellRating, &targetVolatility, &uniqueBrokers)
 	if err != nil {
 		return nil, err
 	}
@@ -280,6 +999,8 @@ func (r *stockRepository) GetStockFeatures(ctx context.Context, ticker string) (
 	features["target_range"] = safeFloat(targetRange)
 	features["upgrade_probability"] = safeFloat(upgradeProb)
 	features["downgrade_probability"] = safeFloat(downgradeProb)
+	features["target_raised_probability"] = safeFloat(targetRaisedProb)
+	features["target_lowered_probability"] = safeFloat(targetLoweredProb)
 	features["buy_rating"] = safeFloat(buyRating)
 	features["sell_rating"] = safeFloat(sellRating)
 	features["target_volatility"] = safeFloat(targetVolatility)
@@ -326,36 +1047,6 @@ func (r *stockRepository) Ping(ctx context.Context) error {
 	return r.db.PingContext(ctx)
 }
 
-// RunMigrations crea la tabla recommendations y los índices necesarios si no existen.
-// Esto asegura que la base de datos tenga la estructura mínima para almacenar datos.
-func RunMigrations(db *sql.DB) error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS recommendations (
-			ticker VARCHAR(10),
-			target_from VARCHAR(20),
-			target_to VARCHAR(20),
-			company VARCHAR(100),
-			action VARCHAR(50),
-			brokerage VARCHAR(100),
-			rating_from VARCHAR(50),
-			rating_to VARCHAR(50),
-			time TIMESTAMP,
-			PRIMARY KEY (ticker, time)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_recommendations_ticker ON recommendations (ticker)`,
-		`CREATE INDEX IF NOT EXISTS idx_recommendations_time ON recommendations (time)`,
-	}
-
-	// Ejecuta cada query de migración
-	for _, query := range queries {
-		_, err := db.Exec(query)
-		if err != nil {
-			return fmt.Errorf("error executing migration: %v", err)
-		}
-	}
-	return nil
-}
-
 // safeFloat es una función auxiliar que devuelve 0 si el puntero es nil, o el valor apuntado si no.
 func safeFloat(f *float64) float64 {
 	if f == nil {
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// syncRunColumns es la lista de columnas que se leen en cada consulta de ejecuciones, en el orden de scanSyncRun.
+const syncRunColumns = `id, type, source, status, started_at, finished_at, pages, inserted, updated, corrected, skipped, deleted, quarantined, error, watermark`
+
+// syncRunRepository implementa domain.SyncRunRepository sobre la tabla sync_runs.
+type syncRunRepository struct {
+	db *sql.DB // Conexión a la base de datos SQL
+}
+
+// NewSyncRunRepository crea el repositorio del historial de sincronizaciones.
+func NewSyncRunRepository(db *sql.DB) domain.SyncRunRepository {
+	return &syncRunRepository{db: db}
+}
+
+// StartSyncRun registra una ejecución en curso y completa su ID y fecha de inicio. Con un lease en el
+// contexto, solo la registra mientras siga siendo de este trabajo.
+func (r *syncRunRepository) StartSyncRun(ctx context.Context, run *domain.SyncRun) error {
+	err := inFencedTx(ctx, r.db, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, `
+        INSERT INTO sync_runs (type, source, status, watermark) VALUES ($1, $2, $3, $4)
+        RETURNING id, started_at`,
+			string(run.Type), run.Source, string(domain.SyncStatusRunning), run.Watermark).
+			Scan(&run.ID, &run.StartedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("error registrando ejecución de sincronización: %w", err)
+	}
+	run.Status = domain.SyncStatusRunning
+	return nil
+}
+
+// UpdateSyncRun guarda los contadores de una ejecución en curso sin cambiar su estado, con la misma
+// comprobación del lease que StartSyncRun.
+func (r *syncRunRepository) UpdateSyncRun(ctx context.Context, run domain.SyncRun) error {
+	err := inFencedTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+        UPDATE sync_runs SET
+            pages = $2, inserted = $3, updated = $4, corrected = $5,
+            skipped = $6, deleted = $7, quarantined = $8, watermark = $9
+        WHERE id = $1 AND status = $10`,
+			run.ID, run.Pages, run.Inserted, run.Updated, run.Corrected,
+			run.Skipped, run.Deleted, run.Quarantined, run.Watermark, string(domain.SyncStatusRunning))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando progreso de sincronización: %w", err)
+	}
+	return nil
+}
+
+// FinishSyncRun guarda el estado final, los contadores y el error de la ejecución. No comprueba el lease:
+// la fila es solo de esta ejecución, y la de un proceso que perdió el lease debe poder cerrarse como fallida.
+func (r *syncRunRepository) FinishSyncRun(ctx context.Context, run domain.SyncRun) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE sync_runs SET
+            status = $2, finished_at = $3, pages = $4, inserted = $5, updated = $6, corrected = $7,
+            skipped = $8, deleted = $9, quarantined = $10, error = $11, watermark = $12
+        WHERE id = $1`,
+		run.ID, string(run.Status), run.FinishedAt, run.Pages, run.Inserted, run.Updated, run.Corrected,
+		run.Skipped, run.Deleted, run.Quarantined, nullString(run.Error), run.Watermark)
+	if err != nil {
+		return fmt.Errorf("error finalizando ejecución de sincronización: %v", err)
+	}
+	return nil
+}
+
+// ListSyncRuns devuelve las ejecuciones más recientes primero.
+func (r *syncRunRepository) ListSyncRuns(ctx context.Context, limit int) ([]domain.SyncRun, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT `+syncRunColumns+`
+        FROM sync_runs
+        ORDER BY started_at DESC, id
+        LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando ejecuciones de sincronización: %v", err)
+	}
+	defer rows.Close()
+
+	runs := []domain.SyncRun{}
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error después de iterar filas: %v", err)
+	}
+	return runs, nil
+}
+
+// GetSyncRun obtiene una ejecución por ID, o domain.ErrSyncRunNotFound.
+func (r *syncRunRepository) GetSyncRun(ctx context.Context, id string) (*domain.SyncRun, error) {
+	run, err := scanSyncRun(r.db.QueryRowContext(ctx, `
+        SELECT `+syncRunColumns+` FROM sync_runs WHERE id::STRING = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrSyncRunNotFound
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FullSyncStart devuelve el inicio del primer intento de la última sincronización completa que terminó
+// bien: los intentos fallidos posteriores a la anterior que terminó bien son los que esta reanudó desde
+// su checkpoint, y sus páginas forman parte del mismo dataset.
+func (r *syncRunRepository) FullSyncStart(ctx context.Context, source string) (*time.Time, error) {
+	var start sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+        WITH last AS (
+            SELECT started_at FROM sync_runs
+            WHERE source = $1 AND type = $2 AND status = $3
+            ORDER BY started_at DESC LIMIT 1
+        ), previous AS (
+            SELECT max(r.started_at) AS started_at FROM sync_runs AS r, last
+            WHERE r.source = $1 AND r.type = $2 AND r.status = $3 AND r.started_at < last.started_at
+        )
+        SELECT min(r.started_at) FROM sync_runs AS r, last, previous
+        WHERE r.source = $1 AND r.type = $2 AND r.started_at <= last.started_at
+            AND (previous.started_at IS NULL OR r.started_at > previous.started_at)`,
+		source, string(domain.SyncTypeFull), string(domain.SyncStatusSucceeded)).Scan(&start)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando la última sincronización completa: %v", err)
+	}
+	if !start.Valid {
+		return nil, nil
+	}
+	return &start.Time, nil
+}
+
+// DeleteSyncRunsBefore elimina las ejecuciones terminadas del proveedor que empezaron antes de before.
+func (r *syncRunRepository) DeleteSyncRunsBefore(ctx context.Context, source string, before time.Time) (int64, error) {
+	deleted, err := deleteInBatches(ctx, r.db, `
+        DELETE FROM sync_runs WHERE source = $1 AND started_at < $2 AND status != $3`,
+		source, before, string(domain.SyncStatusRunning))
+	if err != nil {
+		return deleted, fmt.Errorf("error eliminando ejecuciones de sincronización: %v", err)
+	}
+	return deleted, nil
+}
+
+// scanSyncRun escanea una fila con las columnas de syncRunColumns.
+func scanSyncRun(row rowScanner) (domain.SyncRun, error) {
+	var run domain.SyncRun
+	var finishedAt, watermark sql.NullTime
+	var runError sql.NullString
+	err := row.Scan(&run.ID, &run.Type, &run.Source, &run.Status, &run.StartedAt, &finishedAt, &run.Pages,
+		&run.Inserted, &run.Updated, &run.Corrected, &run.Skipped, &run.Deleted, &run.Quarantined, &runError, &watermark)
+	if err == sql.ErrNoRows {
+		return run, err
+	}
+	if err != nil {
+		return run, fmt.Errorf("error escaneando ejecución de sincronización: %v", err)
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	if watermark.Valid {
+		run.Watermark = &watermark.Time
+	}
+	run.Error = runError.String
+	return run, nil
+}
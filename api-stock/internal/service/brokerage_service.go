@@ -0,0 +1,93 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// brokerageService implementa domain.BrokerageService.
+// Valida los datos del admin antes de delegar en el repositorio.
+type brokerageService struct {
+	repo domain.BrokerageRepository
+}
+
+// NewBrokerageService crea el servicio de administración de firmas de corretaje.
+func NewBrokerageService(repo domain.BrokerageRepository) domain.BrokerageService {
+	return &brokerageService{repo: repo}
+}
+
+// ListBrokerages lista todas las firmas con sus alias.
+func (s *brokerageService) ListBrokerages(ctx context.Context) ([]domain.Brokerage, error) {
+	return s.repo.ListBrokerages(ctx)
+}
+
+// GetBrokerage obtiene una firma por ID.
+func (s *brokerageService) GetBrokerage(ctx context.Context, id string) (*domain.Brokerage, error) {
+	return s.repo.GetBrokerage(ctx, id)
+}
+
+// SaveBrokerage crea o actualiza una firma. Al crearla, su nombre se registra también como alias
+// (si no pertenece ya a otra firma) para que la ingesta la reconozca de inmediato.
+func (s *brokerageService) SaveBrokerage(ctx context.Context, brokerage domain.Brokerage) (*domain.Brokerage, error) {
+	brokerage.ID = strings.TrimSpace(brokerage.ID)
+	brokerage.Name = strings.TrimSpace(brokerage.Name)
+	if !domain.ValidBrokerageID(brokerage.ID) {
+		return nil, fmt.Errorf("%w: el ID debe usar minúsculas, dígitos y guiones bajos (máximo 64)", domain.ErrInvalidBrokerage)
+	}
+	if brokerage.Name == "" {
+		return nil, fmt.Errorf("%w: el nombre es obligatorio", domain.ErrInvalidBrokerage)
+	}
+
+	_, err := s.repo.GetBrokerage(ctx, brokerage.ID)
+	isNew := errors.Is(err, domain.ErrBrokerageNotFound)
+	if err != nil && !isNew {
+		return nil, err
+	}
+
+	if err := s.repo.SaveBrokerage(ctx, brokerage); err != nil {
+		return nil, err
+	}
+	if isNew {
+		if err := s.repo.AddAlias(ctx, brokerage.ID, brokerage.Name); err != nil && !errors.Is(err, domain.ErrAliasConflict) {
+			return nil, err
+		}
+	}
+	return s.repo.GetBrokerage(ctx, brokerage.ID)
+}
+
+// AddAlias agrega un alias a una firma existente.
+func (s *brokerageService) AddAlias(ctx context.Context, id, alias string) (*domain.Brokerage, error) {
+	alias = strings.TrimSpace(alias)
+	if domain.BrokerageKey(alias) == "" {
+		return nil, fmt.Errorf("%w: el alias no puede estar vacío", domain.ErrInvalidBrokerage)
+	}
+	if _, err := s.repo.GetBrokerage(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddAlias(ctx, id, alias); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBrokerage(ctx, id)
+}
+
+// RemoveAlias quita un alias de una firma existente.
+func (s *brokerageService) RemoveAlias(ctx context.Context, id, alias string) (*domain.Brokerage, error) {
+	if _, err := s.repo.GetBrokerage(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RemoveAlias(ctx, id, alias); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBrokerage(ctx, id)
+}
+
+// ListUnresolved lista los nombres de firmas no resueltos, limitando el resultado a 1..500.
+func (s *brokerageService) ListUnresolved(ctx context.Context, limit int) ([]domain.UnresolvedBrokerage, error) {
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListUnresolved(ctx, limit)
+}
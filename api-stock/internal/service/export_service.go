@@ -0,0 +1,213 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// exportFlushEvery es cada cuántas filas se vacía el buffer de CSV y NDJSON hacia el destino,
+// para que un cliente HTTP reciba el archivo de forma progresiva.
+const exportFlushEvery = 1000
+
+// parquetRowGroupSize limita el tamaño de cada row group de Parquet, que se mantiene en memoria hasta escribirse.
+const parquetRowGroupSize = 8 * 1024 * 1024
+
+// exportService implementa domain.ExportService sobre el cursor del repositorio.
+type exportService struct {
+	repo    domain.StockRepository
+	ratings *domain.RatingNormalizer // traduce el filtro de calificación a su valor canónico
+}
+
+// NewExportService crea el servicio de exportación masiva de recomendaciones.
+func NewExportService(repo domain.StockRepository, ratings *domain.RatingNormalizer) domain.ExportService {
+	return &exportService{repo: repo, ratings: ratings}
+}
+
+// ExportRecommendations normaliza el filtro y escribe las recomendaciones fila a fila a medida que
+// llegan del cursor. Si falla a mitad de camino, lo ya escrito queda en w y se devuelve el error.
+func (s *exportService) ExportRecommendations(ctx context.Context, w io.Writer, format domain.ExportFormat, filter domain.RecommendationFilter) (int, error) {
+	rating, err := ratingFilter(s.ratings, string(filter.Rating))
+	if err != nil {
+		return 0, err
+	}
+	filter.Rating = rating
+	filter.Ticker = strings.ToUpper(strings.TrimSpace(filter.Ticker))
+	filter.Source = strings.ToLower(strings.TrimSpace(filter.Source))
+	filter.Brokerage = strings.TrimSpace(filter.Brokerage)
+	filter.Sector = strings.TrimSpace(filter.Sector)
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return 0, fmt.Errorf("%w: el inicio del rango debe ser anterior al final", domain.ErrInvalidExport)
+	}
+
+	out, err := newRecommendationWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	err = s.repo.StreamRecommendations(ctx, filter, func(rec domain.StockRecommendation) error {
+		if err := out.Write(rec); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			return out.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+	return written, out.Close()
+}
+
+// recommendationWriter serializa recomendaciones en un formato de exportación.
+type recommendationWriter interface {
+	Write(rec domain.StockRecommendation) error
+	Flush() error // Envía al destino lo que haya en buffer
+	Close() error // Completa el archivo (pie de Parquet) y vacía el buffer
+}
+
+// newRecommendationWriter crea el escritor para el formato indicado.
+func newRecommendationWriter(w io.Writer, format domain.ExportFormat) (recommendationWriter, error) {
+	switch format {
+	case domain.ExportCSV:
+		return newCSVWriter(w)
+	case domain.ExportNDJSON:
+		buffered := bufio.NewWriter(w)
+		return &ndjsonWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	case domain.ExportParquet:
+		return newParquetWriter(w)
+	default:
+		return nil, fmt.Errorf("%w: formato desconocido %q", domain.ErrInvalidExport, format)
+	}
+}
+
+// csvWriter escribe un CSV con las columnas de domain.ExportColumns.
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(domain.ExportColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (c *csvWriter) Write(rec domain.StockRecommendation) error {
+	return c.writer.Write(domain.ExportRow(rec))
+}
+
+func (c *csvWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+// ndjsonWriter escribe un objeto JSON por línea con el mismo formato que la API.
+type ndjsonWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(rec domain.StockRecommendation) error {
+	return n.encoder.Encode(rec)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.buffered.Flush()
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.Flush()
+}
+
+// parquetRecommendation es el esquema de las filas Parquet. Los precios son DECIMAL(18,2) en centavos
+// y nulos si el proveedor no los envió; el momento es un TIMESTAMP en milisegundos UTC.
+type parquetRecommendation struct {
+	ID                   string `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Source               string `parquet:"name=source, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Ticker               string `parquet:"name=ticker, type=BYTE_ARRAY, convertedtype=UTF8"`
+	TargetFrom           *int64 `parquet:"name=target_from, type=INT64, convertedtype=DECIMAL, scale=2, precision=18, repetitiontype=OPTIONAL"`
+	TargetTo             *int64 `parquet:"name=target_to, type=INT64, convertedtype=DECIMAL, scale=2, precision=18, repetitiontype=OPTIONAL"`
+	Currency             string `parquet:"name=currency, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Company              string `parquet:"name=company, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Action               string `parquet:"name=action, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ActionType           string `parquet:"name=action_type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Brokerage            string `parquet:"name=brokerage, type=BYTE_ARRAY, convertedtype=UTF8"`
+	BrokerageID          string `parquet:"name=brokerage_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	RatingFrom           string `parquet:"name=rating_from, type=BYTE_ARRAY, convertedtype=UTF8"`
+	RatingTo             string `parquet:"name=rating_to, type=BYTE_ARRAY, convertedtype=UTF8"`
+	NormalizedRatingFrom string `parquet:"name=normalized_rating_from, type=BYTE_ARRAY, convertedtype=UTF8"`
+	NormalizedRatingTo   string `parquet:"name=normalized_rating_to, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Time                 int64  `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+}
+
+// parquetWriter escribe un archivo Parquet con compresión Snappy.
+type parquetWriter struct {
+	writer *writer.ParquetWriter
+}
+
+func newParquetWriter(w io.Writer) (*parquetWriter, error) {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(parquetRecommendation), 1)
+	if err != nil {
+		return nil, fmt.Errorf("error creando escritor Parquet: %v", err)
+	}
+	pw.RowGroupSize = parquetRowGroupSize
+	return &parquetWriter{writer: pw}, nil
+}
+
+func (p *parquetWriter) Write(rec domain.StockRecommendation) error {
+	return p.writer.Write(parquetRecommendation{
+		ID:                   rec.ID,
+		Source:               rec.Source,
+		Ticker:               rec.Ticker,
+		TargetFrom:           moneyCents(rec.TargetFrom),
+		TargetTo:             moneyCents(rec.TargetTo),
+		Currency:             rec.Currency,
+		Company:              rec.Company,
+		Action:               rec.Action,
+		ActionType:           string(rec.ActionType),
+		Brokerage:            rec.Brokerage,
+		BrokerageID:          rec.BrokerageID,
+		RatingFrom:           rec.RatingFrom,
+		RatingTo:             rec.RatingTo,
+		NormalizedRatingFrom: string(rec.NormalizedRatingFrom),
+		NormalizedRatingTo:   string(rec.NormalizedRatingTo),
+		Time:                 rec.Time.UnixMilli(),
+	})
+}
+
+// Flush no hace nada: Parquet escribe por row groups completos.
+func (p *parquetWriter) Flush() error {
+	return nil
+}
+
+func (p *parquetWriter) Close() error {
+	if err := p.writer.WriteStop(); err != nil {
+		return fmt.Errorf("error completando archivo Parquet: %v", err)
+	}
+	return nil
+}
+
+// moneyCents devuelve los centavos del importe, o nil si no es válido.
+func moneyCents(m domain.Money) *int64 {
+	if !m.Valid {
+		return nil
+	}
+	cents := m.Cents
+	return &cents
+}
@@ -3,117 +3,598 @@ package service
 import (
 	"api-stock/internal/domain"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"sync"
 	"time"
 )
 
+// SyncConfig agrupa los parámetros de la sincronización con la API externa.
+type SyncConfig struct {
+	BatchSize        int           // Registros por lote de inserción
+	MinRowRatio      float64       // Filas nuevas / filas actuales mínimas para aplicar una sincronización completa (0 desactiva)
+	Prefetch         int           // Páginas recibidas que pueden esperar a guardarse mientras se piden las siguientes (0 no pide por adelantado)
+	CheckpointMaxAge time.Duration // Antigüedad máxima de un checkpoint para reanudarlo; más viejo se reinicia (0 nunca expira)
+	WatermarkOverlap time.Duration // Ventana que la sincronización incremental vuelve a leer por debajo de la marca de agua
+	LookbackPages    int           // Páginas que la sincronización incremental sigue leyendo tras quedar por debajo de la marca de agua
+	FullSyncMaxAge   time.Duration // Antigüedad de la última sincronización completa a partir de la cual la incremental hace una completa (0 nunca)
+}
+
+// DefaultSyncConfig devuelve la configuración de sincronización por defecto.
+func DefaultSyncConfig() SyncConfig {
+	return SyncConfig{
+		BatchSize:        100,
+		MinRowRatio:      0.5,
+		Prefetch:         2,
+		CheckpointMaxAge: 24 * time.Hour,
+		WatermarkOverlap: 24 * time.Hour,
+		LookbackPages:    2,
+		FullSyncMaxAge:   24 * time.Hour,
+	}
+}
+
 // externalAPIService implementa la interfaz domain.ExternalAPIService.
 // Se encarga de sincronizar recomendaciones bursátiles entre la API externa y el repositorio local.
 type externalAPIService struct {
-	client     domain.ExternalAPI     // Cliente para consumir la API externa de recomendaciones
-	repo       domain.StockRepository // Repositorio para almacenar y consultar recomendaciones en la base de datos
-	maxRetries int                    // Número máximo de reintentos para llamadas fallidas (no usado en este código pero reservado)
+	client      domain.ExternalAPI          // Cliente del proveedor de recomendaciones que sincroniza este servicio
+	source      string                      // Nombre del proveedor; delimita staging, checkpoint e historial
+	repo        domain.StockRepository      // Repositorio para almacenar y consultar recomendaciones en la base de datos
+	checkpoints domain.CheckpointRepository // Progreso persistido de la sincronización completa
+	runs        domain.SyncRunRepository    // Historial de ejecuciones
+	quarantine  domain.QuarantineRepository // Registros rechazados por la validación
+	archive     domain.ArchiveRepository    // Páginas del proveedor tal como se recibieron
+	normalizer  domain.Normalizer           // Normaliza los registros recibidos antes de guardarlos
+	config      SyncConfig                  // Parámetros de lotes, pausas y validación
+
+	mu         sync.Mutex
+	quotaReset time.Time // Renovación de la cuota agotada del proveedor; hasta entonces no se sincroniza
 }
 
-// NewExternalAPIService crea una nueva instancia de externalAPIService con el cliente API y repositorio dados.
-func NewExternalAPIService(client domain.ExternalAPI, repo domain.StockRepository) domain.ExternalAPIService {
+// NewExternalAPIService crea una nueva instancia de externalAPIService con el cliente API, repositorio,
+// repositorios de checkpoints, historial, cuarentena y archivo, normalizador y configuración de sincronización dados.
+func NewExternalAPIService(client domain.ExternalAPI, repo domain.StockRepository, checkpoints domain.CheckpointRepository, runs domain.SyncRunRepository, quarantine domain.QuarantineRepository, archive domain.ArchiveRepository, normalizer domain.Normalizer, config SyncConfig) domain.ExternalAPIService {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultSyncConfig().BatchSize
+	}
+	if config.WatermarkOverlap < 0 {
+		config.WatermarkOverlap = 0
+	}
+	if config.LookbackPages < 0 {
+		config.LookbackPages = 0
+	}
 	return &externalAPIService{
-		client:     client,
-		repo:       repo,
-		maxRetries: 3, // Valor por defecto para reintentos (no utilizado aquí)
+		client:      client,
+		source:      client.Name(),
+		repo:        repo,
+		checkpoints: checkpoints,
+		runs:        runs,
+		quarantine:  quarantine,
+		archive:     archive,
+		normalizer:  normalizer,
+		config:      config,
 	}
 }
 
 // SyncRecommendations sincroniza todas las recomendaciones de la API externa y las guarda en el repositorio.
-// Esto es una sincronización completa que reemplaza las recomendaciones existentes.
+// Esto es una sincronización completa que reemplaza las recomendaciones existentes: cada página se carga
+// en staging y se guarda un checkpoint con el token de la siguiente; al terminar, los datos se validan y
+// solo entonces reemplazan a los actuales. Si la sincronización se interrumpe
+// (caída del worker, SIGTERM, error del proveedor), la siguiente ejecución continúa desde el último
+// checkpoint en lugar de empezar de nuevo; las consultas ven el dataset anterior completo hasta la
+// publicación y el nuevo completo desde entonces.
+// Cada ejecución queda registrada en el historial de sincronizaciones.
 func (s *externalAPIService) SyncRecommendations(ctx context.Context) error {
-	// Llama al cliente API para obtener todas las recomendaciones actuales
-	recommendations, err := s.client.GetAllRecommendations(ctx)
+	return s.recordRun(ctx, domain.SyncTypeFull, s.fullSync)
+}
+
+// fullSync ejecuta la sincronización completa y acumula su efecto en run.
+func (s *externalAPIService) fullSync(ctx context.Context, run *domain.SyncRun) error {
+	checkpoint, resumed, err := s.startCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+	if resumed {
+		log.Printf("Resuming %s full sync started at %s: %d pages, %d records already staged",
+			s.source, checkpoint.StartedAt.Format(time.RFC3339), checkpoint.Pages, checkpoint.Records)
+	}
+
+	if !checkpoint.LoadFinished() {
+		if err := s.stagePages(ctx, run, checkpoint); err != nil {
+			return err
+		}
+	}
+
+	// Si no hay recomendaciones, solo loguea y termina sin tocar el dataset actual
+	if checkpoint.Records == 0 {
+		log.Printf("No recommendations received from %s", s.source)
+		return s.checkpoints.DeleteCheckpoint(ctx, checkpoint.Name)
+	}
+
+	staged, err := s.repo.CountStagingRecommendations(ctx, s.source)
+	if err != nil {
+		return err
+	}
+	if staged == 0 && resumed {
+		// No queda nada que publicar (staging se vació fuera de esta sincronización)
+		return s.checkpoints.DeleteCheckpoint(ctx, checkpoint.Name)
+	}
+
+	// Valida lo cargado antes de exponerlo
+	if err := s.validateStaging(ctx, staged, checkpoint.Records); err != nil {
+		return err
+	}
+	stats, err := s.repo.SwapStaging(ctx, s.source, run.ID)
 	if err != nil {
-		return err // Error al llamar la API externa
+		return err
 	}
+	stats.Skipped += checkpoint.Records - staged // Registros repetidos que staging fusionó por ID
+	run.Add(stats)
+	// staging se vacía después de borrar el checkpoint: si el proceso cae antes, al reanudar se vuelve a
+	// publicar el dataset completo (sin cambios) en lugar de uno parcial
+	if err := s.checkpoints.DeleteCheckpoint(ctx, checkpoint.Name); err != nil {
+		return err
+	}
+	return s.repo.ResetStaging(ctx, s.source)
+}
+
+// stagePages carga en staging las páginas que faltan desde el checkpoint, guardándolo después de cada una.
+// Las páginas siguientes se piden mientras se carga la actual; el checkpoint solo avanza con las cargadas,
+// así que las pedidas por adelantado que no llegaron a cargarse se vuelven a pedir al reanudar.
+func (s *externalAPIService) stagePages(ctx context.Context, run *domain.SyncRun, checkpoint *domain.SyncCheckpoint) error {
+	stream := s.client.StreamPages(ctx, checkpoint.NextPage, s.streamOptions())
+	defer stream.Close()
+
+	for !checkpoint.LoadFinished() {
+		page, err := stream.Next()
+		if err != nil {
+			return fmt.Errorf("error obteniendo página %d: %w", checkpoint.Pages+1, err) // El checkpoint queda para reanudar
+		}
+		run.Pages++
+		recommendations, err := s.acceptPage(ctx, run, page)
+		if err != nil {
+			return err
+		}
+		if err := s.stagePage(ctx, recommendations); err != nil {
+			return err
+		}
+
+		// Si el proceso cae entre la carga y el checkpoint, la página se vuelve a pedir; staging deduplica por ID
+		checkpoint.NextPage = page.NextPage
+		checkpoint.Pages++
+		checkpoint.Records += len(recommendations)
+		if err := s.checkpoints.SaveCheckpoint(ctx, *checkpoint); err != nil {
+			return err
+		}
+		s.saveProgress(ctx, run)
+	}
+	return nil
+}
+
+// streamOptions devuelve la lectura anticipada de la configuración.
+func (s *externalAPIService) streamOptions() domain.PageStreamOptions {
+	return domain.PageStreamOptions{Prefetch: s.config.Prefetch}
+}
 
-	// Si no hay recomendaciones nuevas, solo loguea y termina
+// startCheckpoint devuelve el checkpoint de la sincronización completa en curso, o empieza una nueva
+// (staging vacío) si no hay ninguno o es demasiado antiguo para que el token de página siga siendo válido.
+// El segundo valor indica si se reanuda una sincronización anterior.
+func (s *externalAPIService) startCheckpoint(ctx context.Context) (*domain.SyncCheckpoint, bool, error) {
+	checkpoint, err := s.checkpoints.GetCheckpoint(ctx, domain.FullSyncCheckpoint(s.source))
+	if err != nil {
+		return nil, false, err
+	}
+	if checkpoint != nil {
+		if s.config.CheckpointMaxAge <= 0 || time.Since(checkpoint.UpdatedAt) <= s.config.CheckpointMaxAge {
+			return checkpoint, true, nil
+		}
+		log.Printf("Discarding %s full sync checkpoint from %s: older than %s",
+			s.source, checkpoint.UpdatedAt.Format(time.RFC3339), s.config.CheckpointMaxAge)
+	}
+
+	if err := s.repo.ResetStaging(ctx, s.source); err != nil {
+		return nil, false, err
+	}
+	checkpoint = &domain.SyncCheckpoint{Name: domain.FullSyncCheckpoint(s.source), StartedAt: time.Now()}
+	if err := s.checkpoints.SaveCheckpoint(ctx, *checkpoint); err != nil {
+		return nil, false, err
+	}
+	return checkpoint, false, nil
+}
+
+// stagePage normaliza una página de recomendaciones y la carga en staging por lotes.
+func (s *externalAPIService) stagePage(ctx context.Context, recommendations []domain.StockRecommendation) error {
 	if len(recommendations) == 0 {
-		log.Println("No recommendations received from API")
 		return nil
 	}
 
-	// Borra todas las recomendaciones actuales del repositorio para reemplazarlas por las nuevas
-	if err := s.repo.DeleteAllRecommendations(ctx); err != nil {
-		return err // Error al borrar datos antiguos
+	// Normaliza los registros (calificaciones canónicas, etc.) antes de guardarlos
+	recommendations, err := s.normalizer.Normalize(ctx, recommendations)
+	if err != nil {
+		return err
 	}
 
-	batchSize := 100 // Tamaño de lote para inserción en bulk para evitar querys demasiado grandes
-
-	// Inserta las recomendaciones en lotes para evitar saturar la base o la API
-	for i := 0; i < len(recommendations); i += batchSize {
-		end := i + batchSize
+	for i := 0; i < len(recommendations); i += s.config.BatchSize {
+		end := i + s.config.BatchSize
 		if end > len(recommendations) {
 			end = len(recommendations) // Ajusta el índice final si queda menos de un batch completo
 		}
-
-		// Inserta el lote actual en el repositorio
-		if err := s.repo.InsertRecommendations(ctx, recommendations[i:end]); err != nil {
-			return err // Error al insertar el lote
+		if err := s.repo.InsertStagingRecommendations(ctx, recommendations[i:end]); err != nil {
+			return err // Error al insertar el lote; recommendations no se ha tocado
 		}
-		// Pausa para no saturar la base de datos o API, evitando bloqueos o limitaciones
-		time.Sleep(500 * time.Millisecond)
+	}
+	return nil
+}
+
+// validateStaging comprueba que staging contiene las recomendaciones recibidas (a lo sumo tantas como
+// registros, ya que los duplicados se fusionan por ID) y que el nuevo dataset no es sospechosamente
+// menor que el actual (respuesta truncada del proveedor).
+func (s *externalAPIService) validateStaging(ctx context.Context, staged, received int) error {
+	if staged == 0 || staged > received {
+		return fmt.Errorf("%w: staging tiene %d filas para %d registros recibidos", domain.ErrSyncValidation, staged, received)
 	}
 
-	return nil // Todo fue exitoso
+	current, err := s.repo.CountRecommendations(ctx, s.source)
+	if err != nil {
+		return err
+	}
+	if current > 0 && float64(staged) < s.config.MinRowRatio*float64(current) {
+		return fmt.Errorf("%w: %d filas nuevas frente a %d actuales (mínimo %.0f%%)",
+			domain.ErrSyncValidation, staged, current, s.config.MinRowRatio*100)
+	}
+	return nil
 }
 
-// IncrementalSync sincroniza solo las recomendaciones nuevas desde la última actualización guardada.
-// Esto es útil para actualizar gradualmente sin borrar todo.
+// sleepContext espera la duración indicada o hasta que se cancele el contexto.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IncrementalSync sincroniza las recomendaciones nuevas o modificadas desde la última actualización guardada.
+// Esto es útil para actualizar gradualmente sin borrar todo. La marca de agua es la recomendación más
+// reciente guardada (nunca posterior a ahora, para que una fila con fecha futura no bloquee la ingesta)
+// menos WatermarkOverlap. Cada página leída se guarda completa con upserts deduplicados por ID, de modo
+// que releer registros no tiene efecto y los modificados se actualizan. La lectura termina después de
+// LookbackPages páginas consecutivas sin registros por encima de la marca de agua, lo que recoge los
+// registros tardíos o con fecha atrasada que el proveedor intercala entre los recientes. Para eso el
+// proveedor debe entregar las páginas de la más reciente a la más antigua: si una página trae un registro
+// posterior (más allá de WatermarkOverlap) al más reciente de las anteriores, falla con domain.ErrPageOrder.
+//
+// La sincronización incremental no detecta los registros que el proveedor eliminó: solo la completa los
+// borra. Por eso, si la última sincronización completa que terminó bien tiene más de FullSyncMaxAge (o no
+// hubo ninguna), se ejecuta una completa en su lugar.
+// Cada ejecución queda registrada en el historial de sincronizaciones con la marca de agua usada.
 func (s *externalAPIService) IncrementalSync(ctx context.Context) error {
-	// Obtiene la recomendación más reciente guardada para saber desde cuándo pedir novedades
-	latestRec, err := s.repo.GetLatestRecommendation(ctx)
+	due, err := s.fullSyncDue(ctx)
+	if err != nil {
+		return err
+	}
+	if due {
+		log.Printf("Last %s full sync is older than %s: running a full sync to reconcile deletions", s.source, s.config.FullSyncMaxAge)
+		return s.recordRun(ctx, domain.SyncTypeFull, s.fullSync)
+	}
+	return s.recordRun(ctx, domain.SyncTypeIncremental, s.incrementalSync)
+}
+
+// fullSyncDue indica si la última sincronización completa que terminó bien es más antigua que FullSyncMaxAge.
+func (s *externalAPIService) fullSyncDue(ctx context.Context) (bool, error) {
+	if s.config.FullSyncMaxAge <= 0 {
+		return false, nil
+	}
+	last, err := s.runs.FullSyncStart(ctx, s.source)
+	if err != nil {
+		return false, err
+	}
+	return last == nil || time.Since(*last) > s.config.FullSyncMaxAge, nil
+}
+
+// incrementalSync ejecuta la sincronización incremental y acumula su efecto en run.
+func (s *externalAPIService) incrementalSync(ctx context.Context, run *domain.SyncRun) error {
+	watermark, err := s.incrementalWatermark(ctx)
 	if err != nil {
 		return err
 	}
+	run.Watermark = watermark // nil: no hay datos y se lee todo
+
+	pagesBelow := 0      // Páginas consecutivas sin registros por encima de la marca de agua
+	var newest time.Time // Registro más reciente de las páginas ya leídas
 
-	var newRecommendations []domain.StockRecommendation // Acumula nuevas recomendaciones para insertar
-	nextPage := ""                                      // Para paginación de la API externa
+	// Las páginas siguientes se piden mientras se guarda la actual; al salir se descartan las que sobren
+	stream := s.client.StreamPages(ctx, "", s.streamOptions())
+	defer stream.Close()
 
-	// Loop para obtener páginas de recomendaciones desde la API externa
 	for {
-		// Llama a la API para obtener recomendaciones y la siguiente página (si hay)
-		recommendations, page, err := s.client.GetRecommendations(ctx, nextPage)
+		page, err := stream.Next()
+		if err == io.EOF {
+			break // No hay más páginas
+		}
 		if err != nil {
 			return err // Error llamando API
 		}
+		run.Pages++
+		recommendations, err := s.acceptPage(ctx, run, page)
+		if err != nil {
+			return err
+		}
 
-		// Recorre cada recomendación recibida
-		for _, rec := range recommendations {
-			// Si ya llegamos a una recomendación anterior o igual a la última guardada, detenemos la inserción
-			if latestRec != nil && !rec.Time.After(latestRec.Time) {
-				// Inserta todas las recomendaciones nuevas acumuladas hasta ahora
-				if err := s.repo.InsertRecommendations(ctx, newRecommendations); err != nil {
-					return err // Error al insertar nuevas recomendaciones
-				}
-				return nil // Sincronización incremental terminada exitosamente
+		// Guarda la página completa; los registros ya conocidos y sin cambios se omiten
+		if err := s.upsertPage(ctx, run, recommendations); err != nil {
+			return err
+		}
+		s.saveProgress(ctx, run)
+
+		// Sin orden de más reciente a más antiguo, dejar de leer por debajo de la marca de agua perdería registros
+		if newest, err = checkNewestFirst(recommendations, newest, s.config.WatermarkOverlap); err != nil {
+			return fmt.Errorf("%w: página %d de %s: %v", domain.ErrPageOrder, run.Pages, s.source, err)
+		}
+
+		if watermark != nil {
+			if hasRecordsAfter(recommendations, *watermark) {
+				pagesBelow = 0
+			} else if pagesBelow++; pagesBelow > s.config.LookbackPages {
+				break // Ya se releyeron las páginas de margen por debajo de la marca de agua
 			}
-			// Si la recomendación es más reciente, la agregamos al batch para insertar
-			newRecommendations = append(newRecommendations, rec)
 		}
+	}
+	return nil
+}
 
-		// Si no hay más páginas, salimos del loop
-		if page == "" {
-			break
+// checkNewestFirst comprueba que ningún registro de la página es posterior en más de tolerance al más
+// reciente de las páginas anteriores (newest, cero en la primera) y devuelve el nuevo más reciente.
+// La tolerancia admite registros intercalados con fechas algo desordenadas.
+func checkNewestFirst(recommendations []domain.StockRecommendation, newest time.Time, tolerance time.Duration) (time.Time, error) {
+	latest := newest
+	for _, rec := range recommendations {
+		if !newest.IsZero() && rec.Time.After(newest.Add(tolerance)) {
+			return newest, fmt.Errorf("el registro de %s (%s) es posterior al más reciente de las páginas anteriores (%s)",
+				rec.Ticker, rec.Time.Format(time.RFC3339), newest.Format(time.RFC3339))
+		}
+		if rec.Time.After(latest) {
+			latest = rec.Time
 		}
-		// Actualiza el token o parámetro para la siguiente página
-		nextPage = page
+	}
+	return latest, nil
+}
+
+// incrementalWatermark calcula la marca de agua de la sincronización incremental: la fecha de la
+// recomendación más reciente, acotada a ahora, menos WatermarkOverlap. Devuelve nil si no hay datos.
+func (s *externalAPIService) incrementalWatermark(ctx context.Context) (*time.Time, error) {
+	latestRec, err := s.repo.GetLatestRecommendation(ctx, s.source)
+	if err != nil || latestRec == nil {
+		return nil, err
+	}
+	latest := latestRec.Time
+	if now := time.Now(); latest.After(now) {
+		latest = now // Una fila con fecha futura no debe dejar fuera todo lo que llegue hasta entonces
+	}
+	watermark := latest.Add(-s.config.WatermarkOverlap)
+	return &watermark, nil
+}
 
-		// Pausa para respetar límites o evitar saturar la API externa
-		time.Sleep(1 * time.Second)
+// hasRecordsAfter indica si alguna recomendación de la página es posterior a la marca de agua.
+func hasRecordsAfter(recommendations []domain.StockRecommendation, watermark time.Time) bool {
+	for _, rec := range recommendations {
+		if rec.Time.After(watermark) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptPage archiva la respuesta original de la página, envía a cuarentena los registros rechazados y
+// devuelve las recomendaciones válidas. Los rechazados que un administrador ya corrigió y reingresó se
+// sustituyen por su versión corregida, para que la corrección sobreviva a las sincronizaciones
+// siguientes aunque el proveedor siga enviando el registro original.
+func (s *externalAPIService) acceptPage(ctx context.Context, run *domain.SyncRun, page *domain.APIResponse) ([]domain.StockRecommendation, error) {
+	if page.Raw != nil {
+		// Sin archivo no se podría reconstruir la página: su fallo hace fallar la página como el de la base de datos
+		page.Raw.SyncRunID = run.ID
+		if err := s.archive.ArchivePage(ctx, *page.Raw); err != nil {
+			return nil, err
+		}
+	}
+	if len(page.Rejected) == 0 {
+		return page.Items, nil
+	}
+	for i := range page.Rejected {
+		page.Rejected[i].SyncRunID = run.ID
+	}
+	run.Quarantined += len(page.Rejected)
+
+	reingested, err := s.quarantine.QuarantineRecords(ctx, page.Rejected)
+	if err != nil {
+		return nil, err
+	}
+	recommendations := page.Items
+	for _, fixed := range reingested {
+		rec, err := s.client.DecodeRecord(fixed.Payload)
+		if err != nil {
+			log.Printf("Reingested quarantine record %s from %s no longer decodes: %v", fixed.ID, s.source, err)
+			continue
+		}
+		recommendations = append(recommendations, rec)
+	}
+	return recommendations, nil
+}
+
+// ReprocessArchive reconstruye las recomendaciones del proveedor a partir de las páginas archivadas,
+// decodificándolas con el mapeo de campos y las reglas de validación y normalización actuales.
+// Se usan las páginas recibidas desde el inicio de la última sincronización completa que terminó bien
+// (o todo el archivo si nunca hubo una), en el orden en que se recibieron: un registro repetido queda con
+// su última versión y los que el proveedor eliminó antes de esa sincronización no reaparecen.
+// Como una sincronización completa, el resultado se carga en staging, se valida y reemplaza a los
+// datos actuales; los rechazados pasan por la cuarentena. Cada ejecución queda
+// registrada en el historial con el tipo reprocess y el inicio de las páginas usadas como marca de agua.
+func (s *externalAPIService) ReprocessArchive(ctx context.Context) error {
+	return s.recordRun(ctx, domain.SyncTypeReprocess, s.reprocess)
+}
+
+// reprocess ejecuta la reconstrucción desde el archivo y acumula su efecto en run.
+func (s *externalAPIService) reprocess(ctx context.Context, run *domain.SyncRun) error {
+	// Staging es el de la sincronización completa: no se pisa una que está en curso o puede reanudarse
+	checkpoint, err := s.checkpoints.GetCheckpoint(ctx, domain.FullSyncCheckpoint(s.source))
+	if err != nil {
+		return err
+	}
+	if checkpoint != nil && (s.config.CheckpointMaxAge <= 0 || time.Since(checkpoint.UpdatedAt) <= s.config.CheckpointMaxAge) {
+		return fmt.Errorf("la sincronización completa de %s iniciada el %s no ha terminado; reprocese cuando termine",
+			s.source, checkpoint.StartedAt.Format(time.RFC3339))
+	}
+
+	since, err := s.runs.FullSyncStart(ctx, s.source)
+	if err != nil {
+		return err
+	}
+	var from time.Time
+	if since != nil {
+		from = *since
+		run.Watermark = since
+	}
+
+	if err := s.repo.ResetStaging(ctx, s.source); err != nil {
+		return err
+	}
+	received := 0
+	err = s.archive.StreamArchivedPages(ctx, s.source, from, func(archived domain.ArchivedPage) error {
+		page, err := s.client.DecodePage(archived)
+		if err != nil {
+			return fmt.Errorf("error decodificando la página archivada %s: %v", archived.ID, err)
+		}
+		run.Pages++
+		recommendations, err := s.acceptPage(ctx, run, page)
+		if err != nil {
+			return err
+		}
+		received += len(recommendations)
+		if err := s.stagePage(ctx, recommendations); err != nil {
+			return err
+		}
+		s.saveProgress(ctx, run)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if run.Pages == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrArchiveEmpty, s.source)
 	}
 
-	// Si quedaron recomendaciones nuevas sin insertar después de todas las páginas, las insertamos
-	if len(newRecommendations) > 0 {
-		return s.repo.InsertRecommendations(ctx, newRecommendations)
+	staged, err := s.repo.CountStagingRecommendations(ctx, s.source)
+	if err != nil {
+		return err
+	}
+	if err := s.validateStaging(ctx, staged, received); err != nil {
+		return err
+	}
+	stats, err := s.repo.SwapStaging(ctx, s.source, run.ID)
+	if err != nil {
+		return err
 	}
+	stats.Skipped += received - staged // Registros repetidos que staging fusionó por ID
+	run.Add(stats)
+	if err := s.repo.ResetStaging(ctx, s.source); err != nil {
+		return err
+	}
+	log.Printf("Reprocessed %d archived pages from %s: %d inserted, %d updated (%d corrected), %d deleted, %d quarantined",
+		run.Pages, s.source, run.Inserted, run.Updated, run.Corrected, run.Deleted, run.Quarantined)
+	return nil
+}
 
-	// Si no hay nuevas recomendaciones, simplemente termina sin error
+// upsertPage normaliza una página de recomendaciones, la guarda en el repositorio por lotes y acumula su efecto en run.
+func (s *externalAPIService) upsertPage(ctx context.Context, run *domain.SyncRun, recommendations []domain.StockRecommendation) error {
+	if len(recommendations) == 0 {
+		return nil
+	}
+	normalized, err := s.normalizer.Normalize(ctx, recommendations)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(normalized); i += s.config.BatchSize {
+		end := i + s.config.BatchSize
+		if end > len(normalized) {
+			end = len(normalized) // Ajusta el índice final si queda menos de un batch completo
+		}
+		stats, err := s.repo.InsertRecommendations(ctx, normalized[i:end], run.ID)
+		if err != nil {
+			return err
+		}
+		run.Add(stats)
+	}
 	return nil
 }
+
+// saveProgress guarda los contadores de la ejecución en curso para que se pueda seguir su avance.
+// Un fallo solo se loguea: el progreso se vuelve a guardar en la página siguiente y al terminar.
+func (s *externalAPIService) saveProgress(ctx context.Context, run *domain.SyncRun) {
+	if err := s.runs.UpdateSyncRun(ctx, *run); err != nil && ctx.Err() == nil {
+		log.Printf("Failed to record progress of %s sync run %s for %s: %v", run.Type, run.ID, run.Source, err)
+	}
+}
+
+// recordRun registra en el historial una ejecución del tipo indicado alrededor de sync, con su
+// resultado, contadores y error. Un fallo al cerrar el registro solo se loguea: no cambia el resultado.
+// Si la ejecución se detiene por la cuota diaria (domain.ErrQuotaExceeded), queda con estado
+// quota_exceeded y las siguientes fallan con ese error sin pedir nada al proveedor hasta que la cuota
+// se renueva; la completa conserva su checkpoint y se reanuda entonces.
+func (s *externalAPIService) recordRun(ctx context.Context, syncType domain.SyncType, sync func(context.Context, *domain.SyncRun) error) error {
+	if reset := s.quotaPausedUntil(); !reset.IsZero() {
+		log.Printf("Skipping %s %s sync: daily quota exhausted until %s", s.source, syncType, reset.Format(time.RFC3339))
+		return fmt.Errorf("%w: %s no se sincroniza hasta el %s", domain.ErrQuotaExceeded, s.source, reset.Format(time.RFC3339))
+	}
+
+	run := &domain.SyncRun{Type: syncType, Source: s.source}
+	if err := s.runs.StartSyncRun(ctx, run); err != nil {
+		return err
+	}
+
+	syncErr := sync(ctx, run)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = domain.SyncStatusSucceeded
+	switch {
+	case errors.Is(syncErr, domain.ErrQuotaExceeded):
+		run.Status = domain.SyncStatusQuota
+		run.Error = syncErr.Error()
+		s.pauseUntilQuotaReset(finishedAt)
+	case syncErr != nil:
+		run.Status = domain.SyncStatusFailed
+		run.Error = syncErr.Error()
+	}
+
+	// El registro se cierra aunque el contexto de la sincronización se haya cancelado (SIGTERM)
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := s.runs.FinishSyncRun(finishCtx, *run); err != nil {
+		log.Printf("Failed to record %s sync run %s for %s: %v", run.Type, run.ID, run.Source, err)
+	}
+	return syncErr
+}
+
+// quotaPausedUntil devuelve la renovación de la cuota agotada del proveedor, o cero si se puede sincronizar.
+func (s *externalAPIService) quotaPausedUntil() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.quotaReset.IsZero() && !time.Now().Before(s.quotaReset) {
+		s.quotaReset = time.Time{}
+	}
+	return s.quotaReset
+}
+
+// pauseUntilQuotaReset deja de sincronizar el proveedor hasta que se renueve la cuota agotada en t.
+func (s *externalAPIService) pauseUntilQuotaReset(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotaReset = domain.QuotaResetAt(t)
+}
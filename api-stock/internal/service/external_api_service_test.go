@@ -0,0 +1,47 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestCheckNewestFirst(t *testing.T) {
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	page := func(offsets ...time.Duration) []domain.StockRecommendation {
+		recs := make([]domain.StockRecommendation, len(offsets))
+		for i, offset := range offsets {
+			recs[i] = domain.StockRecommendation{Ticker: "AAPL", Time: base.Add(offset)}
+		}
+		return recs
+	}
+
+	tests := []struct {
+		name       string
+		page       []domain.StockRecommendation
+		newest     time.Time
+		tolerance  time.Duration
+		wantNewest time.Time
+		wantErr    bool
+	}{
+		{"primera página en cualquier orden", page(-time.Hour, time.Hour, 0), time.Time{}, 0, base.Add(time.Hour), false},
+		{"página más antigua", page(-time.Hour, -2*time.Hour), base, 0, base, false},
+		{"registro igual al más reciente", page(0, -time.Hour), base, 0, base, false},
+		{"registro posterior dentro de la tolerancia", page(30 * time.Minute), base, time.Hour, base.Add(30 * time.Minute), false},
+		{"registro posterior fuera de la tolerancia", page(-time.Hour, 2*time.Hour), base, time.Hour, base, true},
+		{"registro posterior sin tolerancia", page(time.Second), base, 0, base, true},
+		{"página vacía", nil, base, 0, base, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newest, err := checkNewestFirst(tt.page, tt.newest, tt.tolerance)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkNewestFirst() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !newest.Equal(tt.wantNewest) {
+				t.Errorf("checkNewestFirst() newest = %s, want %s", newest, tt.wantNewest)
+			}
+		})
+	}
+}
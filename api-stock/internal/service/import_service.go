@@ -0,0 +1,137 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ImportSource es el proveedor con el que se guardan los archivos importados si no se indica otro.
+// Usar un proveedor propio evita que la siguiente sincronización completa de la API reemplace el histórico.
+const ImportSource = "import"
+
+// importService implementa domain.ImportService.
+type importService struct {
+	repo       domain.StockRepository
+	normalizer domain.Normalizer
+}
+
+// NewImportService crea el servicio de importación de archivos de recomendaciones.
+func NewImportService(repo domain.StockRepository, normalizer domain.Normalizer) domain.ImportService {
+	return &importService{repo: repo, normalizer: normalizer}
+}
+
+// ImportRecommendations lee el archivo registro a registro, aplica el mapeo de columnas y guarda los
+// registros válidos en lotes con la misma normalización e inserción que la sincronización. Los registros
+// ilegibles o que no superan domain.ValidateRecommendation se rechazan sin detener la importación; un
+// error de lectura del archivo o de la base de datos la detiene y devuelve el resumen parcial. En modo
+// dry-run no se escribe nada (tampoco el informe de firmas no resueltas que genera la normalización).
+func (s *importService) ImportRecommendations(ctx context.Context, r io.Reader, opts domain.ImportOptions) (*domain.ImportReport, error) {
+	if opts.Source == "" {
+		opts.Source = ImportSource
+	}
+	if !domain.ValidSource(opts.Source) {
+		return nil, fmt.Errorf("%w: proveedor %q inválido", domain.ErrInvalidImport, opts.Source)
+	}
+	if err := opts.Mapping.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultSyncConfig().BatchSize
+	}
+
+	reader, err := domain.NewRecordReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ImportReport{}
+	batch := make([]domain.StockRecommendation, 0, opts.BatchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		row, record, raw, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		report.Read++
+		var recordErr *domain.RecordError
+		if errors.As(err, &recordErr) {
+			report.Rejected = append(report.Rejected, domain.RejectedRow{Row: row, Reason: recordErr.Error(), Raw: raw})
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+
+		rec, err := decodeImportRecord(record, opts)
+		if err != nil {
+			report.Rejected = append(report.Rejected, domain.RejectedRow{Row: row, Reason: err.Error(), Raw: raw})
+			continue
+		}
+		report.Accepted++
+		if opts.DryRun {
+			continue
+		}
+
+		batch = append(batch, rec)
+		if len(batch) == opts.BatchSize {
+			if err := s.saveBatch(ctx, report, batch); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := s.saveBatch(ctx, report, batch); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// decodeImportRecord construye la recomendación de un registro y le aplica las reglas de validación de la ingesta.
+func decodeImportRecord(record map[string]interface{}, opts domain.ImportOptions) (domain.StockRecommendation, error) {
+	rec, err := domain.DecodeRecommendation(record, opts.Mapping, opts.TimeLayout)
+	if err != nil {
+		return rec, err
+	}
+	rec.Ticker = strings.ToUpper(strings.TrimSpace(rec.Ticker))
+	rec.Source = opts.Source
+	return rec, domain.ValidateRecommendation(rec)
+}
+
+// saveBatch normaliza y guarda un lote. Si el archivo repite una recomendación dentro del lote,
+// prevalece la última aparición, igual que en una reimportación.
+func (s *importService) saveBatch(ctx context.Context, report *domain.ImportReport, batch []domain.StockRecommendation) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	normalized, err := s.normalizer.Normalize(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	unique := make([]domain.StockRecommendation, 0, len(normalized))
+	index := make(map[string]int, len(normalized))
+	for _, rec := range normalized {
+		if i, ok := index[rec.ID]; ok {
+			unique[i] = rec
+			report.Stats.Skipped++
+			continue
+		}
+		index[rec.ID] = len(unique)
+		unique = append(unique, rec)
+	}
+
+	stats, err := s.repo.InsertRecommendations(ctx, unique, "")
+	if err != nil {
+		return err
+	}
+	report.Stats.Add(stats)
+	return nil
+}
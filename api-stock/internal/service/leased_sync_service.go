@@ -0,0 +1,210 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// SyncLeaseName es el lease que comparten todas las sincronizaciones: completa, incremental y reprocesamiento,
+// de todos los proveedores y de todos los procesos.
+const SyncLeaseName = "sync"
+
+// LeaseConfig agrupa los parámetros del lease de sincronización.
+type LeaseConfig struct {
+	TTL  time.Duration // Vigencia del lease sin renovar; se renueva cada TTL/3 y, si el proceso cae, otro lo toma al vencer
+	Wait time.Duration // Espera máxima a que otro proceso libere el lease antes de omitir el trabajo (0 no espera)
+}
+
+// DefaultLeaseConfig devuelve la configuración del lease por defecto.
+func DefaultLeaseConfig() LeaseConfig {
+	return LeaseConfig{TTL: time.Minute}
+}
+
+// leaseRetryInterval es la pausa entre intentos de tomar un lease ocupado.
+const leaseRetryInterval = 2 * time.Second
+
+// leasedSyncService implementa domain.ExternalAPIService envolviendo otro servicio de sincronización:
+// cada trabajo se ejecuta solo si este proceso toma el lease de sincronización, lo renueva mientras dura
+// y lo libera al terminar. Si el lease se pierde a mitad de camino, el trabajo se cancela; la
+// sincronización completa se reanuda después desde su checkpoint.
+type leasedSyncService struct {
+	inner domain.ExternalAPIService // Servicio que ejecuta las sincronizaciones
+	lease *leaseRunner              // Lease de sincronización
+}
+
+// NewLeasedSyncService envuelve el servicio de sincronización para que un solo proceso sincronice a la vez.
+func NewLeasedSyncService(inner domain.ExternalAPIService, leases domain.LeaseRepository, config LeaseConfig) domain.ExternalAPIService {
+	return &leasedSyncService{inner: inner, lease: newLeaseRunner(leases, config)}
+}
+
+// SyncRecommendations ejecuta la sincronización completa con el lease tomado.
+func (s *leasedSyncService) SyncRecommendations(ctx context.Context) error {
+	return s.lease.run(ctx, s.inner.SyncRecommendations)
+}
+
+// IncrementalSync ejecuta la sincronización incremental con el lease tomado.
+func (s *leasedSyncService) IncrementalSync(ctx context.Context) error {
+	return s.lease.run(ctx, s.inner.IncrementalSync)
+}
+
+// ReprocessArchive ejecuta el reprocesamiento del archivo con el lease tomado.
+func (s *leasedSyncService) ReprocessArchive(ctx context.Context) error {
+	return s.lease.run(ctx, s.inner.ReprocessArchive)
+}
+
+// leasedQuarantineService implementa domain.QuarantineService envolviendo otro servicio de cuarentena:
+// el reingreso escribe en recommendations, así que se ejecuta con el lease de sincronización tomado para
+// no mezclarse con una sincronización en curso. Las demás operaciones solo tocan la cuarentena.
+type leasedQuarantineService struct {
+	domain.QuarantineService              // Servicio que revisa la cuarentena
+	lease                    *leaseRunner // Lease de sincronización
+}
+
+// NewLeasedQuarantineService envuelve el servicio de cuarentena para que los reingresos tomen el lease de
+// sincronización. Si una sincronización lo tiene, el reingreso devuelve domain.ErrLeaseHeld.
+func NewLeasedQuarantineService(inner domain.QuarantineService, leases domain.LeaseRepository, config LeaseConfig) domain.QuarantineService {
+	return &leasedQuarantineService{QuarantineService: inner, lease: newLeaseRunner(leases, config)}
+}
+
+// ReingestQuarantined reingresa el registro con el lease tomado.
+func (s *leasedQuarantineService) ReingestQuarantined(ctx context.Context, id string) (*domain.StockRecommendation, error) {
+	var recommendation *domain.StockRecommendation
+	err := s.lease.run(ctx, func(ctx context.Context) error {
+		var err error
+		recommendation, err = s.QuarantineService.ReingestQuarantined(ctx, id)
+		return err
+	})
+	return recommendation, err
+}
+
+// leaseRunner ejecuta trabajos con el lease de sincronización tomado.
+type leaseRunner struct {
+	leases domain.LeaseRepository // Leases en la base de datos
+	config LeaseConfig            // Vigencia y espera
+}
+
+// newLeaseRunner crea el ejecutor con la configuración normalizada.
+func newLeaseRunner(leases domain.LeaseRepository, config LeaseConfig) *leaseRunner {
+	if config.TTL <= 0 {
+		config.TTL = DefaultLeaseConfig().TTL
+	}
+	if config.Wait < 0 {
+		config.Wait = 0
+	}
+	return &leaseRunner{leases: leases, config: config}
+}
+
+// run toma el lease (esperando hasta Wait), ejecuta job y libera el lease al terminar. Devuelve
+// domain.ErrLeaseHeld si otro proceso lo tiene. El contexto de job se cancela si el lease se pierde y
+// lleva la toma del lease (domain.WithLeaseFence), para que los repositorios rechacen dentro de sus
+// transacciones las escrituras que lleguen después de que otro proceso lo tome.
+func (s *leaseRunner) run(ctx context.Context, job func(context.Context) error) error {
+	owner := leaseOwner() // Un dueño por trabajo: dos trabajos del mismo proceso también se excluyen
+	lease, err := s.acquire(ctx, owner)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// El lease se libera aunque ctx se haya cancelado (SIGTERM), para que otro proceso no espere a que venza
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := s.leases.ReleaseLease(releaseCtx, SyncLeaseName, owner); err != nil {
+			log.Printf("Failed to release sync lease: %v", err)
+		}
+	}()
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		s.heartbeat(jobCtx, owner, cancel)
+	}()
+
+	err = job(domain.WithLeaseFence(jobCtx, domain.LeaseFence{Name: SyncLeaseName, Owner: owner, Token: lease.Token}))
+	cancel(nil)
+	<-heartbeatDone
+	if cause := context.Cause(jobCtx); errors.Is(cause, domain.ErrLeaseLost) {
+		return cause // El trabajo se interrumpió por perder el lease; su error es consecuencia de la cancelación
+	}
+	return err
+}
+
+// acquire intenta tomar el lease hasta obtenerlo o agotar la espera configurada.
+func (s *leaseRunner) acquire(ctx context.Context, owner string) (*domain.Lease, error) {
+	deadline := time.Now().Add(s.config.Wait)
+	for {
+		lease, err := s.leases.AcquireLease(ctx, SyncLeaseName, owner, s.config.TTL)
+		if err != nil {
+			return nil, err
+		}
+		if lease != nil {
+			return lease, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, s.heldError(ctx)
+		}
+		if err := sleepContext(ctx, min(remaining, leaseRetryInterval)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// heldError describe quién tiene el lease, si sigue vigente.
+func (s *leaseRunner) heldError(ctx context.Context) error {
+	lease, err := s.leases.GetLease(ctx, SyncLeaseName)
+	if err != nil || lease == nil {
+		return domain.ErrLeaseHeld
+	}
+	return fmt.Errorf("%w: %s lo tiene desde %s (vence %s)", domain.ErrLeaseHeld,
+		lease.Owner, lease.AcquiredAt.Format(time.RFC3339), lease.ExpiresAt.Format(time.RFC3339))
+}
+
+// heartbeat renueva el lease cada TTL/3 hasta que termine el trabajo. Si otro proceso lo tomó, o no se
+// pudo renovar durante un TTL completo (y otro proceso pudo tomarlo), cancela el trabajo con domain.ErrLeaseLost.
+func (s *leaseRunner) heartbeat(ctx context.Context, owner string, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(s.config.TTL / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		held, err := s.leases.RenewLease(ctx, SyncLeaseName, owner, s.config.TTL)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return // El trabajo terminó durante la renovación
+		case err != nil:
+			log.Printf("Failed to renew sync lease: %v", err)
+			if time.Since(renewed) >= s.config.TTL {
+				cancel(fmt.Errorf("%w: sin renovar desde %s", domain.ErrLeaseLost, renewed.Format(time.RFC3339)))
+				return
+			}
+		case !held:
+			cancel(fmt.Errorf("%w: otro proceso lo tomó", domain.ErrLeaseLost))
+			return
+		default:
+			renewed = time.Now()
+		}
+	}
+}
+
+// leaseOwner genera un identificador único del trabajo para registrar quién tiene el lease.
+func leaseOwner() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLeases es un domain.LeaseRepository en memoria con un solo lease.
+type fakeLeases struct {
+	mu       sync.Mutex
+	owner    string
+	token    int64
+	held     bool // Otro proceso tiene el lease
+	stolen   bool // Otro proceso lo toma en la próxima renovación
+	released bool
+}
+
+func (f *fakeLeases) AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (*domain.Lease, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.held {
+		return nil, nil
+	}
+	f.owner = owner
+	f.token++
+	return &domain.Lease{Name: name, Owner: owner, Token: f.token}, nil
+}
+
+func (f *fakeLeases) RenewLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stolen {
+		f.owner = "otro"
+		f.token++
+	}
+	return f.owner == owner, nil
+}
+
+func (f *fakeLeases) ReleaseLease(ctx context.Context, name, owner string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.owner == owner {
+		f.released = true
+	}
+	return nil
+}
+
+func (f *fakeLeases) GetLease(ctx context.Context, name string) (*domain.Lease, error) {
+	return nil, nil
+}
+
+func TestLeaseRunnerRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		leases      *fakeLeases
+		job         func(ctx context.Context) error
+		wantErr     error
+		wantRan     bool
+		wantRelease bool
+	}{
+		{
+			name:        "el trabajo termina con el lease tomado",
+			leases:      &fakeLeases{token: 6},
+			job:         func(ctx context.Context) error { return nil },
+			wantRan:     true,
+			wantRelease: true,
+		},
+		{
+			name:    "otro proceso tiene el lease",
+			leases:  &fakeLeases{held: true},
+			job:     func(ctx context.Context) error { return nil },
+			wantErr: domain.ErrLeaseHeld,
+		},
+		{
+			name:   "perder el lease cancela el trabajo",
+			leases: &fakeLeases{stolen: true},
+			job: func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return errors.New("el trabajo no se canceló")
+				}
+			},
+			wantErr: domain.ErrLeaseLost,
+			wantRan: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newLeaseRunner(tt.leases, LeaseConfig{TTL: 30 * time.Millisecond})
+			ran := false
+			wantToken := tt.leases.token + 1
+			err := runner.run(context.Background(), func(ctx context.Context) error {
+				ran = true
+				fence, ok := domain.LeaseFenceFrom(ctx)
+				if !ok || fence.Name != SyncLeaseName || fence.Owner == "" || fence.Token != wantToken {
+					t.Errorf("LeaseFenceFrom() = %+v, %v, want the lease %s with token %d", fence, ok, SyncLeaseName, wantToken)
+				}
+				return tt.job(ctx)
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("run() error = %v, want %v", err, tt.wantErr)
+			}
+			if ran != tt.wantRan {
+				t.Errorf("job ran = %v, want %v", ran, tt.wantRan)
+			}
+			if tt.leases.released != tt.wantRelease {
+				t.Errorf("released = %v, want %v", tt.leases.released, tt.wantRelease)
+			}
+		})
+	}
+}
+
+// fakeQuarantine es un domain.QuarantineService que solo implementa el reingreso.
+type fakeQuarantine struct {
+	domain.QuarantineService
+	fenced bool // El reingreso recibió la toma del lease
+}
+
+func (f *fakeQuarantine) ReingestQuarantined(ctx context.Context, id string) (*domain.StockRecommendation, error) {
+	_, f.fenced = domain.LeaseFenceFrom(ctx)
+	return &domain.StockRecommendation{ID: id}, nil
+}
+
+func TestLeasedQuarantineServiceReingest(t *testing.T) {
+	tests := []struct {
+		name       string
+		held       bool
+		wantErr    error
+		wantFenced bool
+	}{
+		{name: "sin sincronización en curso", wantFenced: true},
+		{name: "con una sincronización en curso", held: true, wantErr: domain.ErrLeaseHeld},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &fakeQuarantine{}
+			svc := NewLeasedQuarantineService(inner, &fakeLeases{held: tt.held}, LeaseConfig{TTL: time.Minute})
+			rec, err := svc.ReingestQuarantined(context.Background(), "q1")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("ReingestQuarantined() error = %v, want %v", err, tt.wantErr)
+			}
+			if inner.fenced != tt.wantFenced {
+				t.Errorf("reingest ran with the lease = %v, want %v", inner.fenced, tt.wantFenced)
+			}
+			if tt.wantErr == nil && (rec == nil || rec.ID != "q1") {
+				t.Errorf("ReingestQuarantined() = %+v, want the reingested record", rec)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// normalizer implementa domain.Normalizer.
+// Traduce los campos de texto libre del proveedor a valores canónicos antes de guardarlos.
+type normalizer struct {
+	ratings    *domain.RatingNormalizer   // diccionario de alias de calificaciones
+	brokerages domain.BrokerageRepository // registro maestro de firmas y sus alias
+}
+
+// NewNormalizer crea el normalizador de ingesta con el diccionario de calificaciones y el registro de firmas.
+func NewNormalizer(ratings *domain.RatingNormalizer, brokerages domain.BrokerageRepository) domain.Normalizer {
+	return &normalizer{ratings: ratings, brokerages: brokerages}
+}
+
+// Normalize completa el ID estable, las calificaciones canónicas, el tipo de acción y el ID de la firma
+// de cada recomendación, conservando el texto original. Los nombres de firma sin alias se registran
+// para revisión en el informe de no resueltos.
+func (n *normalizer) Normalize(ctx context.Context, recommendations []domain.StockRecommendation) ([]domain.StockRecommendation, error) {
+	// Los alias se leen en cada lote para aplicar los cambios del admin sin reiniciar
+	brokerages, err := n.brokerages.ListBrokerages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error cargando firmas de corretaje: %v", err)
+	}
+	resolver := domain.NewBrokerageResolver(brokerages)
+	unresolved := make(map[string]int)
+
+	for i := range recommendations {
+		rec := &recommendations[i]
+		rec.NormalizedRatingFrom = n.ratings.Normalize(rec.RatingFrom)
+		rec.NormalizedRatingTo = n.ratings.Normalize(rec.RatingTo)
+		rec.ActionType = domain.ClassifyAction(rec.Action, rec.NormalizedRatingFrom, rec.NormalizedRatingTo, rec.TargetFrom, rec.TargetTo)
+		if rec.Source == "" {
+			rec.Source = domain.DefaultSource
+		}
+		rec.ID = rec.IdentityID()
+
+		if id, ok := resolver.Resolve(rec.Brokerage); ok {
+			rec.BrokerageID = id
+		} else if name := strings.TrimSpace(rec.Brokerage); name != "" {
+			rec.BrokerageID = ""
+			unresolved[name]++
+		}
+	}
+
+	if err := n.brokerages.RecordUnresolved(ctx, unresolved); err != nil {
+		return nil, err
+	}
+	return recommendations, nil
+}
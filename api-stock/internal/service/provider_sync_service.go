@@ -0,0 +1,57 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// providerSyncService implementa domain.ExternalAPIService sincronizando cada proveedor registrado
+// con su propio externalAPIService: staging, checkpoint, marca de agua e historial son por proveedor.
+type providerSyncService struct {
+	providers []domain.ExternalAPIService // Un servicio de sincronización por proveedor, en orden de registro
+	names     []string                    // Nombre de cada proveedor, para los errores
+}
+
+// NewProviderSyncService crea el servicio de sincronización de todos los proveedores dados,
+// compartiendo repositorios, normalizador y configuración.
+func NewProviderSyncService(providers []domain.ExternalAPI, repo domain.StockRepository, checkpoints domain.CheckpointRepository, runs domain.SyncRunRepository, quarantine domain.QuarantineRepository, archive domain.ArchiveRepository, normalizer domain.Normalizer, config SyncConfig) domain.ExternalAPIService {
+	s := &providerSyncService{}
+	for _, provider := range providers {
+		s.providers = append(s.providers, NewExternalAPIService(provider, repo, checkpoints, runs, quarantine, archive, normalizer, config))
+		s.names = append(s.names, provider.Name())
+	}
+	return s
+}
+
+// SyncRecommendations ejecuta la sincronización completa de cada proveedor. El fallo de uno no
+// detiene a los demás; se devuelven todos los errores juntos.
+func (s *providerSyncService) SyncRecommendations(ctx context.Context) error {
+	return s.each(ctx, domain.ExternalAPIService.SyncRecommendations)
+}
+
+// IncrementalSync ejecuta la sincronización incremental de cada proveedor, con la misma política de errores.
+func (s *providerSyncService) IncrementalSync(ctx context.Context) error {
+	return s.each(ctx, domain.ExternalAPIService.IncrementalSync)
+}
+
+// ReprocessArchive reconstruye las recomendaciones de cada proveedor desde su archivo, con la misma política de errores.
+func (s *providerSyncService) ReprocessArchive(ctx context.Context) error {
+	return s.each(ctx, domain.ExternalAPIService.ReprocessArchive)
+}
+
+// each aplica sync a cada proveedor en orden y acumula los errores con el nombre del proveedor.
+func (s *providerSyncService) each(ctx context.Context, sync func(domain.ExternalAPIService, context.Context) error) error {
+	var errs []error
+	for i, provider := range s.providers {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		if err := sync(provider, ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.names[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// quarantineService implementa domain.QuarantineService.
+type quarantineService struct {
+	repo       domain.QuarantineRepository
+	stocks     domain.StockRepository
+	normalizer domain.Normalizer
+	providers  map[string]domain.ExternalAPI // Proveedor por nombre, para decodificar con su mapeo de campos
+}
+
+// NewQuarantineService crea el servicio de revisión de la cuarentena con los proveedores configurados.
+func NewQuarantineService(repo domain.QuarantineRepository, stocks domain.StockRepository, normalizer domain.Normalizer, providers []domain.ExternalAPI) domain.QuarantineService {
+	byName := make(map[string]domain.ExternalAPI, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &quarantineService{repo: repo, stocks: stocks, normalizer: normalizer, providers: byName}
+}
+
+// ListQuarantine lista los registros en cuarentena, limitando el resultado a 1..500.
+func (s *quarantineService) ListQuarantine(ctx context.Context, filter domain.QuarantineFilter, limit int) ([]domain.QuarantinedRecord, error) {
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListQuarantine(ctx, filter, limit)
+}
+
+// GetQuarantined obtiene un registro en cuarentena por ID.
+func (s *quarantineService) GetQuarantined(ctx context.Context, id string) (*domain.QuarantinedRecord, error) {
+	return s.repo.GetQuarantined(ctx, id)
+}
+
+// FixQuarantined reemplaza el payload de un registro pendiente. El motivo pasa a ser el resultado de
+// decodificar y validar el nuevo payload: vacío si ya es válido y puede reingresarse.
+func (s *quarantineService) FixQuarantined(ctx context.Context, id string, payload json.RawMessage) (*domain.QuarantinedRecord, error) {
+	rec, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, payload); err != nil {
+		return nil, fmt.Errorf("%w: el payload no es JSON válido: %v", domain.ErrInvalidRecommendation, err)
+	}
+	rec.Payload = compact.Bytes()
+	rec.Reason = ""
+	if _, err := s.decode(*rec); err != nil {
+		rec.Reason = err.Error()
+	}
+
+	if err := s.repo.UpdateQuarantined(ctx, *rec); err != nil {
+		return nil, err
+	}
+	return s.repo.GetQuarantined(ctx, id)
+}
+
+// ReingestQuarantined decodifica el payload con el mapeo del proveedor, lo valida y lo guarda como
+// recomendación con la misma normalización que la sincronización. Si sigue siendo inválido, el motivo
+// se actualiza y se devuelve un error que envuelve domain.ErrInvalidRecommendation.
+func (s *quarantineService) ReingestQuarantined(ctx context.Context, id string) (*domain.StockRecommendation, error) {
+	rec, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendation, err := s.decode(*rec)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRecommendation) {
+			rec.Reason = err.Error()
+			if updateErr := s.repo.UpdateQuarantined(ctx, *rec); updateErr != nil {
+				return nil, updateErr
+			}
+		}
+		return nil, err
+	}
+
+	normalized, err := s.normalizer.Normalize(ctx, []domain.StockRecommendation{recommendation})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.stocks.InsertRecommendations(ctx, normalized, ""); err != nil {
+		return nil, err
+	}
+
+	rec.Reason = ""
+	rec.Status = domain.QuarantineReingested
+	if err := s.repo.UpdateQuarantined(ctx, *rec); err != nil {
+		return nil, err
+	}
+	return &normalized[0], nil
+}
+
+// DiscardQuarantined marca un registro pendiente como descartado.
+func (s *quarantineService) DiscardQuarantined(ctx context.Context, id string) (*domain.QuarantinedRecord, error) {
+	rec, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rec.Status = domain.QuarantineDiscarded
+	if err := s.repo.UpdateQuarantined(ctx, *rec); err != nil {
+		return nil, err
+	}
+	return s.repo.GetQuarantined(ctx, id)
+}
+
+// pending obtiene un registro y comprueba que sigue pendiente (domain.ErrQuarantineResolved si no).
+func (s *quarantineService) pending(ctx context.Context, id string) (*domain.QuarantinedRecord, error) {
+	rec, err := s.repo.GetQuarantined(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status != domain.QuarantinePending {
+		return nil, fmt.Errorf("%w: estado %s", domain.ErrQuarantineResolved, rec.Status)
+	}
+	return rec, nil
+}
+
+// decode decodifica y valida el payload con el proveedor que envió el registro.
+func (s *quarantineService) decode(rec domain.QuarantinedRecord) (domain.StockRecommendation, error) {
+	provider, ok := s.providers[rec.Source]
+	if !ok {
+		return domain.StockRecommendation{}, fmt.Errorf("%w: el proveedor %s no está configurado", domain.ErrInvalidRecommendation, rec.Source)
+	}
+	return provider.DecodeRecord(rec.Payload)
+}
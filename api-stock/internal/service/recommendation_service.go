@@ -5,15 +5,18 @@ import (
 	"context"
 	"math"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 )
 
+// scoreWindow es la antigüedad máxima de las recomendaciones que cuentan para el score de un ticker.
+const scoreWindow = 30 * 24 * time.Hour
+
 // recommendationService implementa la lógica para obtener recomendaciones de acciones.
 // Mantiene un repositorio para acceder a datos, pesos para el modelo, cachés y sincronización.
 type recommendationService struct {
 	repo         domain.StockRepository           // interfaz para acceder a la base de datos
+	brokerages   domain.BrokerageRepository       // registro maestro de firmas (pesos de reputación)
 	modelWeights domain.ModelWeights              // pesos usados para calcular scores de recomendaciones
 	cache        map[string][]domain.SimilarStock // caché para resultados de acciones similares
 	cacheMutex   sync.RWMutex                     // mutex para proteger acceso a cache
@@ -24,43 +27,30 @@ type recommendationService struct {
 	bestStocksCacheTTL   time.Duration                // tiempo de vida del caché para mejores acciones
 }
 
-// Constructor que inicializa el servicio con un repositorio y pesos predefinidos
-func NewRecommendationService(repo domain.StockRepository) domain.RecommendationService {
+// Constructor que inicializa el servicio con los repositorios y pesos predefinidos
+func NewRecommendationService(repo domain.StockRepository, brokerages domain.BrokerageRepository) domain.RecommendationService {
 	return &recommendationService{
-		repo: repo,
+		repo:       repo,
+		brokerages: brokerages,
 		modelWeights: domain.ModelWeights{
-			// pesos para diferentes tipos de acción
-			ActionWeights: map[string]float64{
-				"initiated":      2.5,
-				"target raised":  3.2,
-				"target lowered": -1.5,
-				"reiterated":     1.8,
-				"updated":        2.0,
-				"maintained":     1.0,
-				// se pueden agregar más casos aquí
-			},
-			// pesos para diferentes ratings
-			RatingWeights: map[string]float64{
-				"buy":          3.0,
-				"comprar":      3.0,
-				"outperform":   2.7,
-				"superar":      2.8,
-				"neutral":      1.0,
-				"market":       0.5,
-				"underperform": -1.5,
-				"sell":         -2.5,
-				"vender":       -2.5,
+			// pesos para cada tipo de acción canónico
+			ActionWeights: map[domain.ActionType]float64{
+				domain.ActionUpgrade:       3.5,
+				domain.ActionTargetRaised:  3.2,
+				domain.ActionInitiate:      2.5,
+				domain.ActionReiterate:     1.8,
+				domain.ActionMaintain:      1.0,
+				domain.ActionDropCoverage:  -0.5,
+				domain.ActionTargetLowered: -1.5,
+				domain.ActionDowngrade:     -2.5,
 			},
-			// pesos para brokers
-			BrokerageWeights: map[string]float64{
-				"goldman":        1.3,
-				"morgan":         1.2,
-				"jp":             1.2,
-				"morgan stanley": 1.2,
-				"jpmorgan":       1.2,
-				"bmo":            1.1,
-				"oppenheimer":    1.0,
-				"mizuho":         0.9,
+			// pesos para cada calificación canónica
+			RatingWeights: map[domain.Rating]float64{
+				domain.RatingStrongBuy:  3.5,
+				domain.RatingBuy:        3.0,
+				domain.RatingHold:       1.0,
+				domain.RatingSell:       -2.0,
+				domain.RatingStrongSell: -2.5,
 			},
 			RecentnessWeight: 0.1, // peso para la recencia temporal de la recomendación
 		},
@@ -89,14 +79,20 @@ func (s *recommendationService) GetBestStocks(ctx context.Context, limit int) ([
 	}
 	s.bestStocksCacheMutex.RUnlock()
 
-	// Si no está en caché o expiró, consulta las recomendaciones recientes (últimos 30 días)
-	recentRecs, err := s.repo.GetRecentRecommendations(ctx, 30*24*time.Hour)
+	// Si no está en caché o expiró, consulta las recomendaciones recientes
+	recentRecs, err := s.repo.GetRecentRecommendations(ctx, scoreWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pesos de reputación vigentes de cada firma (se leen en cada recálculo para reflejar cambios del admin)
+	brokerageWeights, err := s.brokerageWeights(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Calcula scores para cada ticker basado en las recomendaciones
-	scores := s.calculateScores(recentRecs)
+	scores := s.calculateScores(recentRecs, brokerageWeights)
 	// Ordena los tickers por score descendente
 	sorted := s.sortByScore(scores)
 
@@ -115,15 +111,44 @@ func (s *recommendationService) GetBestStocks(ctx context.Context, limit int) ([
 	return best, nil
 }
 
+// ScoreTickers calcula el score de cada ticker con recomendaciones en scoreWindow, como GetBestStocks pero
+// sin caché y con todos los tickers, ordenados del mayor score al menor (a igual score, por ticker).
+func (s *recommendationService) ScoreTickers(ctx context.Context) ([]domain.TickerScore, error) {
+	recentRecs, err := s.repo.GetRecentRecommendations(ctx, scoreWindow)
+	if err != nil {
+		return nil, err
+	}
+	brokerageWeights, err := s.brokerageWeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, rec := range recentRecs {
+		counts[rec.Ticker]++
+	}
+	scores := make([]domain.TickerScore, 0, len(counts))
+	for ticker, score := range s.calculateScores(recentRecs, brokerageWeights) {
+		scores = append(scores, domain.TickerScore{Ticker: ticker, Score: score, Recommendations: counts[ticker]})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Ticker < scores[j].Ticker
+	})
+	return scores, nil
+}
+
 // calculateScores calcula un mapa ticker -> score promedio basado en recomendaciones
-func (s *recommendationService) calculateScores(recommendations []domain.StockRecommendation) map[string]float64 {
+func (s *recommendationService) calculateScores(recommendations []domain.StockRecommendation, brokerageWeights map[string]float64) map[string]float64 {
 	scores := make(map[string]float64) // acumuladores de score por ticker
 	counts := make(map[string]int)     // cantidad de recomendaciones por ticker
 
 	for _, rec := range recommendations {
-		score := s.calculateScore(rec) // score individual para esta recomendación
-		scores[rec.Ticker] += score    // acumula
-		counts[rec.Ticker]++           // cuenta
+		score := s.calculateScore(rec, brokerageWeights) // score individual para esta recomendación
+		scores[rec.Ticker] += score                      // acumula
+		counts[rec.Ticker]++                             // cuenta
 	}
 
 	// Divide acumulado entre número de recomendaciones para promedio
@@ -135,12 +160,12 @@ func (s *recommendationService) calculateScores(recommendations []domain.StockRe
 }
 
 // calculateScore calcula el score para una recomendación individual basado en sus características
-func (s *recommendationService) calculateScore(rec domain.StockRecommendation) float64 {
+func (s *recommendationService) calculateScore(rec domain.StockRecommendation, brokerageWeights map[string]float64) float64 {
 	// Extrae scores parciales para las diferentes características
 	features := map[string]float64{
-		"action":    s.getActionScore(rec.Action),
-		"rating":    s.getRatingScore(rec.RatingTo),
-		"brokerage": s.getBrokerageScore(rec.Brokerage),
+		"action":    s.getActionScore(rec.ActionType),
+		"rating":    s.getRatingScore(rec.NormalizedRatingTo),
+		"brokerage": brokerageWeights[rec.BrokerageID], // 0 si la firma no está resuelta
 		"recency":   s.getRecencyScore(rec.Time),
 	}
 
@@ -153,42 +178,27 @@ func (s *recommendationService) calculateScore(rec domain.StockRecommendation) f
 	return score / float64(len(features))
 }
 
-// normalize convierte un string a minúsculas y quita espacios en los extremos
-func normalize(s string) string {
-	return strings.ToLower(strings.TrimSpace(s))
+// getActionScore obtiene el peso para un tipo de acción canónico según el modelo (0 si es desconocido)
+func (s *recommendationService) getActionScore(action domain.ActionType) float64 {
+	return s.modelWeights.ActionWeights[action]
 }
 
-// getActionScore obtiene el peso para una acción dada según el modelo
-func (s *recommendationService) getActionScore(action string) float64 {
-	actionNorm := normalize(action)
-	for key, val := range s.modelWeights.ActionWeights {
-		if strings.Contains(actionNorm, key) {
-			return val
-		}
-	}
-	return 0.0
+// getRatingScore obtiene el peso para una calificación canónica según el modelo (0 si es desconocida)
+func (s *recommendationService) getRatingScore(rating domain.Rating) float64 {
+	return s.modelWeights.RatingWeights[rating]
 }
 
-// getRatingScore obtiene el peso para un rating dado según el modelo
-func (s *recommendationService) getRatingScore(rating string) float64 {
-	ratingNorm := normalize(rating)
-	for key, val := range s.modelWeights.RatingWeights {
-		if strings.Contains(ratingNorm, key) {
-			return val
-		}
+// brokerageWeights devuelve el peso de reputación de cada firma indexado por su ID canónico
+func (s *recommendationService) brokerageWeights(ctx context.Context) (map[string]float64, error) {
+	brokerages, err := s.brokerages.ListBrokerages(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return 0.0
-}
-
-// getBrokerageScore obtiene el peso para un broker dado según el modelo
-func (s *recommendationService) getBrokerageScore(brokerage string) float64 {
-	brokerNorm := normalize(brokerage)
-	for key, val := range s.modelWeights.BrokerageWeights {
-		if strings.Contains(brokerNorm, key) {
-			return val
-		}
+	weights := make(map[string]float64, len(brokerages))
+	for _, b := range brokerages {
+		weights[b.ID] = b.ReputationWeight
 	}
-	return 0.0
+	return weights, nil
 }
 
 // getRecencyScore calcula el peso según la recencia temporal de la recomendación usando decaimiento exponencial
@@ -235,7 +245,7 @@ func (s *recommendationService) getTopRecommendations(ctx context.Context, sorte
 			break
 		}
 		// Obtiene recomendaciones para un ticker, pide solo la primera para mostrar
-		recs, _, err := s.repo.GetRecommendations(ctx, item.Ticker, 1, limit)
+		recs, _, err := s.repo.GetRecommendations(ctx, domain.RecommendationFilter{Ticker: item.Ticker}, 1, limit)
 		if err != nil {
 			return nil, err
 		}
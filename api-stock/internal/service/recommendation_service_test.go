@@ -0,0 +1,61 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeRecentStocks es un domain.StockRepository que solo devuelve recomendaciones recientes.
+type fakeRecentStocks struct {
+	domain.StockRepository
+	recent []domain.StockRecommendation
+}
+
+func (f *fakeRecentStocks) GetRecentRecommendations(ctx context.Context, since time.Duration) ([]domain.StockRecommendation, error) {
+	return f.recent, nil
+}
+
+// fakeBrokerages es un domain.BrokerageRepository sin firmas.
+type fakeBrokerages struct {
+	domain.BrokerageRepository
+}
+
+func (fakeBrokerages) ListBrokerages(ctx context.Context) ([]domain.Brokerage, error) {
+	return nil, nil
+}
+
+func TestScoreTickers(t *testing.T) {
+	at := time.Now().Add(-24 * 365 * time.Hour) // Sin peso de recencia
+	rec := func(ticker string, action domain.ActionType, rating domain.Rating) domain.StockRecommendation {
+		return domain.StockRecommendation{Ticker: ticker, ActionType: action, NormalizedRatingTo: rating, Time: at}
+	}
+	repo := &fakeRecentStocks{recent: []domain.StockRecommendation{
+		rec("AAA", domain.ActionDowngrade, domain.RatingSell),
+		rec("BBB", domain.ActionUpgrade, domain.RatingBuy),
+		rec("BBB", domain.ActionMaintain, domain.RatingHold),
+		rec("CCC", domain.ActionUpgrade, domain.RatingBuy),
+		rec("DDD", domain.ActionUpgrade, domain.RatingBuy),
+	}}
+
+	scores, err := NewRecommendationService(repo, fakeBrokerages{}).ScoreTickers(context.Background())
+	if err != nil {
+		t.Fatalf("ScoreTickers() error: %v", err)
+	}
+
+	// CCC y DDD empatan y se ordenan por ticker
+	var tickers []string
+	counts := map[string]int{}
+	for _, score := range scores {
+		tickers = append(tickers, score.Ticker)
+		counts[score.Ticker] = score.Recommendations
+	}
+	if want := []string{"CCC", "DDD", "BBB", "AAA"}; !reflect.DeepEqual(tickers, want) {
+		t.Errorf("ScoreTickers() order = %v, want %v", tickers, want)
+	}
+	if want := map[string]int{"AAA": 1, "BBB": 2, "CCC": 1, "DDD": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("ScoreTickers() recommendations = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// retentionService implementa domain.RetentionService sobre los repositorios del historial.
+type retentionService struct {
+	sources    []string                    // Proveedores configurados
+	runs       domain.SyncRunRepository    // Historial de sincronizaciones
+	jobRuns    domain.JobRunRepository     // Historial de trabajos del worker
+	quarantine domain.QuarantineRepository // Registros en cuarentena
+	archive    domain.ArchiveRepository    // Páginas archivadas
+	maxAge     time.Duration               // Antigüedad a partir de la que se elimina el historial
+}
+
+// NewRetentionService crea el servicio que purga el historial más antiguo que maxAge.
+func NewRetentionService(providers []domain.ExternalAPI, runs domain.SyncRunRepository, jobRuns domain.JobRunRepository, quarantine domain.QuarantineRepository, archive domain.ArchiveRepository, maxAge time.Duration) domain.RetentionService {
+	s := &retentionService{runs: runs, jobRuns: jobRuns, quarantine: quarantine, archive: archive, maxAge: maxAge}
+	for _, provider := range providers {
+		s.sources = append(s.sources, provider.Name())
+	}
+	return s
+}
+
+// Purge elimina el historial más antiguo que la retención. Por proveedor, las ejecuciones y las páginas
+// archivadas se conservan desde la última sincronización completa que terminó bien aunque sean más antiguas:
+// el reprocesamiento las necesita y FullSyncStart sigue calculando la misma ventana sin las anteriores.
+// Un proveedor que nunca completó una sincronización no se purga.
+func (s *retentionService) Purge(ctx context.Context) error {
+	cutoff := time.Now().Add(-s.maxAge)
+	var errs []error
+
+	for _, source := range s.sources {
+		start, err := s.runs.FullSyncStart(ctx, source)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+			continue
+		}
+		if start == nil {
+			continue
+		}
+		before := cutoff
+		if start.Before(before) {
+			before = *start
+		}
+
+		pages, err := s.archive.DeleteArchivedPagesBefore(ctx, source, before)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+			continue
+		}
+		runs, err := s.runs.DeleteSyncRunsBefore(ctx, source, before)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+			continue
+		}
+		log.Printf("Purged %d archived pages and %d sync runs of %s older than %s",
+			pages, runs, source, before.Format(time.RFC3339))
+	}
+
+	quarantined, err := s.quarantine.DeleteResolvedBefore(ctx, cutoff)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	jobRuns, err := s.jobRuns.DeleteJobRunsBefore(ctx, cutoff)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	log.Printf("Purged %d resolved quarantined records and %d job runs older than %s",
+		quarantined, jobRuns, cutoff.Format(time.RFC3339))
+	return errors.Join(errs...)
+}
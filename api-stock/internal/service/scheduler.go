@@ -0,0 +1,421 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"api-stock/pkg/metrics"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// errSchedulerStopped indica que el scheduler no está en marcha (no arrancó o se está apagando).
+var errSchedulerStopped = errors.New("el worker no acepta ejecuciones")
+
+// RetentionJobKind es el tipo de trabajo que purga el historial antiguo (domain.RetentionService).
+const RetentionJobKind = "retention"
+
+// ScoreSnapshotJobKind es el tipo de trabajo que guarda la foto diaria de los scores (domain.ScoreSnapshotService).
+const ScoreSnapshotJobKind = "score_snapshot"
+
+// JobFunc ejecuta un tipo de trabajo del worker.
+type JobFunc func(ctx context.Context) error
+
+// JobDefinition describe un trabajo programado del worker.
+type JobDefinition struct {
+	Name       string               // Nombre único; se usa en el endpoint de administración y en job_runs
+	Kind       string               // Tipo de trabajo (full, incremental, reprocess, retention, score_snapshot)
+	Schedule   string               // Expresión cron de 5 campos, @daily, @every 15m o con prefijo CRON_TZ=; vacía: solo manual o al arrancar
+	Timeout    time.Duration        // Tiempo máximo de una ejecución; 0 no la limita
+	Jitter     time.Duration        // Retraso aleatorio máximo sobre la hora programada, para repartir la carga entre réplicas
+	Overlap    domain.OverlapPolicy // Qué hacer si llega la hora y la ejecución anterior sigue en curso (por defecto skip)
+	RunOnStart bool                 // Ejecuta el trabajo al arrancar el worker
+}
+
+// SyncJobKinds devuelve los tipos de trabajo de sincronización: full, incremental y reprocess.
+func SyncJobKinds(syncService domain.ExternalAPIService) map[string]JobFunc {
+	return map[string]JobFunc{
+		string(domain.SyncTypeFull):        syncService.SyncRecommendations,
+		string(domain.SyncTypeIncremental): syncService.IncrementalSync,
+		string(domain.SyncTypeReprocess):   syncService.ReprocessArchive,
+	}
+}
+
+// DefaultJobs devuelve los trabajos del worker cuando no se configuran: sincronización completa al arrancar,
+// incremental cada interval y reprocesamiento solo manual.
+func DefaultJobs(interval time.Duration) []JobDefinition {
+	return []JobDefinition{
+		{Name: "full", Kind: string(domain.SyncTypeFull), RunOnStart: true},
+		{Name: "incremental", Kind: string(domain.SyncTypeIncremental), Schedule: "@every " + interval.String()},
+		{Name: "reprocess", Kind: string(domain.SyncTypeReprocess)},
+	}
+}
+
+// runningJob es una ejecución en curso y la clave con la que el tracker asocia sus sincronizaciones.
+type runningJob struct {
+	key int
+	run domain.JobRun
+}
+
+// scheduledJob es un trabajo del scheduler con su estado; los campos mutables se protegen con Scheduler.mu.
+type scheduledJob struct {
+	def      JobDefinition
+	run      JobFunc
+	schedule cron.Schedule // nil si el trabajo no tiene expresión cron
+
+	nextRun        time.Time
+	running        []runningJob
+	pending        bool // Hay una ejecución encolada (política queue)
+	pendingTrigger domain.JobTrigger
+	last           *domain.JobRun
+}
+
+// Scheduler implementa domain.WorkerService: ejecuta cada trabajo según su expresión cron, con su
+// tiempo máximo, retraso aleatorio y política de solapamiento, y registra cada ejecución en job_runs.
+// Los trabajos distintos corren en paralelo; las sincronizaciones se excluyen entre sí con el lease.
+type Scheduler struct {
+	jobs    []*scheduledJob
+	byName  map[string]*scheduledJob
+	runs    *SyncRunTracker         // Progreso de las sincronizaciones de este proceso
+	jobRuns domain.JobRunRepository // Historial de ejecuciones
+
+	ctx context.Context // Contexto de Start; se cancela al apagar el worker
+	wg  sync.WaitGroup  // Bucles de programación y ejecuciones en curso
+
+	mu      sync.Mutex
+	paused  bool
+	stopped bool
+	seq     int // Clave de la última ejecución para el tracker
+}
+
+// NewScheduler valida los trabajos contra los tipos disponibles y crea el scheduler. runs debe ser el
+// tracker del repositorio de ejecuciones que usan los servicios de sincronización.
+func NewScheduler(defs []JobDefinition, kinds map[string]JobFunc, runs *SyncRunTracker, jobRuns domain.JobRunRepository) (*Scheduler, error) {
+	s := &Scheduler{byName: make(map[string]*scheduledJob), runs: runs, jobRuns: jobRuns}
+	for _, def := range defs {
+		if def.Name == "" || len(def.Name) > 50 {
+			return nil, fmt.Errorf("nombre de trabajo inválido %q", def.Name)
+		}
+		if _, ok := s.byName[def.Name]; ok {
+			return nil, fmt.Errorf("trabajo %s duplicado", def.Name)
+		}
+		run, ok := kinds[def.Kind]
+		if !ok {
+			return nil, fmt.Errorf("trabajo %s: tipo %q desconocido", def.Name, def.Kind)
+		}
+		if def.Overlap == "" {
+			def.Overlap = domain.OverlapSkip
+		}
+		if !def.Overlap.Valid() {
+			return nil, fmt.Errorf("trabajo %s: política de solapamiento %q inválida", def.Name, def.Overlap)
+		}
+		if def.Timeout < 0 || def.Jitter < 0 {
+			return nil, fmt.Errorf("trabajo %s: timeout y jitter no pueden ser negativos", def.Name)
+		}
+
+		job := &scheduledJob{def: def, run: run}
+		if def.Schedule != "" {
+			schedule, err := cron.ParseStandard(def.Schedule)
+			if err != nil {
+				return nil, fmt.Errorf("trabajo %s: expresión cron %q inválida: %v", def.Name, def.Schedule, err)
+			}
+			job.schedule = schedule
+		}
+		s.jobs = append(s.jobs, job)
+		s.byName[def.Name] = job
+	}
+	return s, nil
+}
+
+// Start lanza los trabajos de arranque y la programación de cada trabajo. Las ejecuciones reciben ctx:
+// al cancelarlo (SIGTERM) se interrumpen, y Wait vuelve cuando todas terminaron.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.def.RunOnStart {
+			if err := s.fire(job, domain.JobTriggerStartup); err != nil {
+				log.Printf("Failed to start job %s: %v", job.def.Name, err)
+			}
+		}
+		if job.schedule != nil {
+			s.wg.Add(1)
+			go s.loop(job)
+		}
+	}
+}
+
+// Wait deja de aceptar ejecuciones y espera a que terminen los bucles y las ejecuciones en curso.
+// Debe llamarse después de cancelar el contexto de Start.
+func (s *Scheduler) Wait() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Trigger ejecuta el trabajo ahora, según su política de solapamiento si ya está en curso.
+func (s *Scheduler) Trigger(name string) error {
+	job, ok := s.byName[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", domain.ErrUnknownJob, name)
+	}
+	return s.fire(job, domain.JobTriggerManual)
+}
+
+// Pause pausa la programación de todos los trabajos.
+func (s *Scheduler) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		log.Println("Job schedule paused")
+	}
+	s.paused = true
+}
+
+// Resume reanuda la programación de todos los trabajos.
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		log.Println("Job schedule resumed")
+	}
+	s.paused = false
+}
+
+// Status devuelve el estado de cada trabajo con el progreso de sus ejecuciones en curso.
+func (s *Scheduler) Status() domain.WorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := domain.WorkerStatus{Paused: s.paused, Jobs: make([]domain.ScheduledJob, 0, len(s.jobs))}
+	for _, job := range s.jobs {
+		scheduled := domain.ScheduledJob{
+			Name:     job.def.Name,
+			Kind:     job.def.Kind,
+			Schedule: job.def.Schedule,
+			Overlap:  job.def.Overlap,
+			Pending:  job.pending,
+			Running:  make([]domain.JobRun, 0, len(job.running)),
+			Last:     job.last,
+		}
+		if job.def.Timeout > 0 {
+			scheduled.Timeout = job.def.Timeout.String()
+		}
+		if !s.paused && !job.nextRun.IsZero() {
+			next := job.nextRun
+			scheduled.NextRun = &next
+		}
+		for _, running := range job.running {
+			run := running.run
+			run.SyncRuns = s.runs.runsFor(running.key)
+			scheduled.Running = append(scheduled.Running, run)
+		}
+		status.Jobs = append(status.Jobs, scheduled)
+	}
+	return status
+}
+
+// ListJobRuns lista las ejecuciones registradas más recientes primero, limitando el resultado a 1..500.
+func (s *Scheduler) ListJobRuns(ctx context.Context, job string, limit int) ([]domain.JobRun, error) {
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+	if job != "" {
+		if _, ok := s.byName[job]; !ok {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownJob, job)
+		}
+	}
+	return s.jobRuns.ListJobRuns(ctx, job, limit)
+}
+
+// loop espera cada hora programada del trabajo (más el retraso aleatorio) y lo ejecuta, salvo pausa.
+func (s *Scheduler) loop(job *scheduledJob) {
+	defer s.wg.Done()
+	for {
+		next := job.schedule.Next(time.Now())
+		s.mu.Lock()
+		job.nextRun = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next) + jitter(job.def.Jitter))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if s.isPaused() {
+			log.Printf("Scheduled job %s skipped: schedule is paused", job.def.Name)
+			continue
+		}
+		if err := s.fire(job, domain.JobTriggerSchedule); errors.Is(err, domain.ErrJobRunning) {
+			s.recordSkipped(job, domain.JobTriggerSchedule, err)
+		} else if err != nil && !errors.Is(err, errSchedulerStopped) {
+			log.Printf("Failed to start job %s: %v", job.def.Name, err)
+		}
+	}
+}
+
+// fire lanza una ejecución del trabajo, o la encola o rechaza con domain.ErrJobRunning si ya hay una
+// en curso, según la política de solapamiento.
+func (s *Scheduler) fire(job *scheduledJob, trigger domain.JobTrigger) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped || s.ctx == nil || s.ctx.Err() != nil {
+		return errSchedulerStopped
+	}
+
+	if len(job.running) > 0 {
+		switch job.def.Overlap {
+		case domain.OverlapSkip:
+			return fmt.Errorf("%w: %s desde %s", domain.ErrJobRunning, job.def.Name, job.running[0].run.StartedAt.Format(time.RFC3339))
+		case domain.OverlapQueue:
+			if job.pending {
+				return fmt.Errorf("%w: %s ya tiene una ejecución encolada", domain.ErrJobRunning, job.def.Name)
+			}
+			job.pending = true
+			job.pendingTrigger = trigger
+			log.Printf("Job %s queued until the current run finishes", job.def.Name)
+			return nil
+		}
+	}
+
+	s.wg.Add(1)
+	go s.execute(job, s.begin(job, trigger))
+	return nil
+}
+
+// begin publica una ejecución en curso del trabajo, antes de lanzarla, para que la política de
+// solapamiento la vea desde ya. Debe llamarse con s.mu tomado.
+func (s *Scheduler) begin(job *scheduledJob, trigger domain.JobTrigger) runningJob {
+	s.seq++
+	running := runningJob{key: s.seq, run: domain.JobRun{
+		Job: job.def.Name, Kind: job.def.Kind, Trigger: trigger, Status: domain.JobRunRunning, StartedAt: time.Now()}}
+	job.running = append(job.running, running)
+	return running
+}
+
+// execute ejecuta el trabajo y, con la política queue, la ejecución encolada mientras tanto.
+func (s *Scheduler) execute(job *scheduledJob, running runningJob) {
+	defer s.wg.Done()
+	for next := &running; next != nil; {
+		next = s.runOnce(job, *next)
+	}
+}
+
+// runOnce ejecuta una ejecución ya publicada con begin y registra su resultado. Devuelve la ejecución
+// encolada que debe seguir, ya publicada, o nil.
+func (s *Scheduler) runOnce(job *scheduledJob, running runningJob) *runningJob {
+	run := running.run
+	if err := s.jobRuns.StartJobRun(s.ctx, &run); err != nil {
+		log.Printf("Failed to record job %s run: %v", job.def.Name, err) // El trabajo se ejecuta igual
+	} else {
+		s.mu.Lock()
+		for i := range job.running {
+			if job.running[i].key == running.key {
+				job.running[i].run.ID = run.ID
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	ctx := withTrackedJob(s.ctx, running.key)
+	if job.def.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.def.Timeout)
+		defer cancel()
+	}
+
+	log.Printf("Starting job %s (%s)...", job.def.Name, run.Trigger)
+	err := job.run(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("se superó el tiempo máximo de %s: %w", job.def.Timeout, err)
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = domain.JobRunSucceeded
+	switch {
+	case errors.Is(err, domain.ErrLeaseHeld):
+		run.Status = domain.JobRunSkipped
+		run.Error = err.Error()
+		log.Printf("Job %s skipped: %v", job.def.Name, err)
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		run.Status = domain.JobRunQuota
+		run.Error = err.Error()
+		log.Printf("Job %s stopped by the daily quota: %v", job.def.Name, err)
+	case err != nil:
+		run.Status = domain.JobRunFailed
+		run.Error = err.Error()
+		log.Printf("Job %s failed: %v", job.def.Name, err)
+	default:
+		log.Printf("Job %s completed successfully", job.def.Name)
+	}
+	metrics.ObserveJobRun(job.def.Name, string(run.Status), finishedAt.Sub(run.StartedAt))
+
+	// El registro se cierra aunque el contexto del worker se haya cancelado (SIGTERM)
+	if run.ID != "" {
+		finishCtx, cancel := context.WithTimeout(context.WithoutCancel(s.ctx), 10*time.Second)
+		defer cancel()
+		if err := s.jobRuns.FinishJobRun(finishCtx, run); err != nil {
+			log.Printf("Failed to record job %s run %s: %v", job.def.Name, run.ID, err)
+		}
+	}
+
+	// Retira la ejecución y, en el mismo paso, publica la encolada: entre ambas no cabe otra
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, other := range job.running {
+		if other.key == running.key {
+			job.running = append(job.running[:i], job.running[i+1:]...)
+			break
+		}
+	}
+	run.SyncRuns = s.runs.runsFor(running.key)
+	job.last = &run
+
+	if !job.pending {
+		return nil
+	}
+	job.pending = false
+	if s.stopped || s.ctx.Err() != nil {
+		return nil
+	}
+	next := s.begin(job, job.pendingTrigger)
+	return &next
+}
+
+// recordSkipped registra una ejecución programada que no se lanzó porque la anterior sigue en curso.
+func (s *Scheduler) recordSkipped(job *scheduledJob, trigger domain.JobTrigger, reason error) {
+	log.Printf("Scheduled job %s skipped: %v", job.def.Name, reason)
+	now := time.Now()
+	run := domain.JobRun{Job: job.def.Name, Kind: job.def.Kind, Trigger: trigger, Status: domain.JobRunSkipped,
+		StartedAt: now, FinishedAt: &now, Error: reason.Error()}
+	if err := s.jobRuns.StartJobRun(s.ctx, &run); err != nil {
+		log.Printf("Failed to record job %s run: %v", job.def.Name, err)
+	}
+	metrics.ObserveJobRun(job.def.Name, string(run.Status), 0)
+}
+
+// isPaused indica si la programación está pausada.
+func (s *Scheduler) isPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// jitter devuelve un retraso aleatorio entre 0 y max.
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"testing"
+	"time"
+	_ "time/tzdata" // CRON_TZ no depende de la base de zonas del sistema
+)
+
+func TestNewSchedulerSchedule(t *testing.T) {
+	// Viernes 7 de marzo de 2025, 16:50 UTC (11:50 en Nueva York)
+	from := time.Date(2025, 3, 7, 16, 50, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule string
+		want     time.Time // Cero si el trabajo no tiene programación
+		wantErr  bool
+	}{
+		{name: "sin programación", schedule: ""},
+		{name: "cada 15 minutos en horario de mercado", schedule: "*/15 9-16 * * MON-FRI", want: time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)},
+		{name: "minuto siguiente dentro del horario", schedule: "*/15 9-17 * * MON-FRI", want: time.Date(2025, 3, 7, 17, 0, 0, 0, time.UTC)},
+		{name: "@daily", schedule: "@daily", want: time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{name: "@every", schedule: "@every 15m", want: from.Add(15 * time.Minute)},
+		{name: "zona horaria", schedule: "CRON_TZ=America/New_York 0 12 * * *", want: time.Date(2025, 3, 7, 17, 0, 0, 0, time.UTC)},
+		{name: "zona horaria con cambio de hora", schedule: "CRON_TZ=America/New_York 0 12 * * MON", want: time.Date(2025, 3, 10, 16, 0, 0, 0, time.UTC)},
+		{name: "campo fuera de rango", schedule: "61 * * * *", wantErr: true},
+		{name: "seis campos", schedule: "0 */15 * * * *", wantErr: true},
+		{name: "zona horaria desconocida", schedule: "CRON_TZ=Mars/Olympus 0 12 * * *", wantErr: true},
+		{name: "@every sin duración", schedule: "@every", wantErr: true},
+		{name: "texto libre", schedule: "every monday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewScheduler([]JobDefinition{{Name: "job", Kind: "noop", Schedule: tt.schedule}}, noopKinds(), nil, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewScheduler(%q) did not fail", tt.schedule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewScheduler(%q) error: %v", tt.schedule, err)
+			}
+
+			schedule := s.byName["job"].schedule
+			if tt.want.IsZero() {
+				if schedule != nil {
+					t.Errorf("NewScheduler(%q) scheduled the job, want manual only", tt.schedule)
+				}
+				return
+			}
+			if schedule == nil {
+				t.Fatalf("NewScheduler(%q) did not schedule the job", tt.schedule)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", from, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSchedulerRejectsInvalidJobs(t *testing.T) {
+	tests := []struct {
+		name string
+		defs []JobDefinition
+	}{
+		{"jitter negativo", []JobDefinition{{Name: "job", Kind: "noop", Schedule: "@hourly", Jitter: -time.Second}}},
+		{"timeout negativo", []JobDefinition{{Name: "job", Kind: "noop", Timeout: -time.Second}}},
+		{"tipo desconocido", []JobDefinition{{Name: "job", Kind: "backup"}}},
+		{"política de solapamiento desconocida", []JobDefinition{{Name: "job", Kind: "noop", Overlap: "replace"}}},
+		{"nombre vacío", []JobDefinition{{Kind: "noop"}}},
+		{"nombre repetido", []JobDefinition{{Name: "job", Kind: "noop"}, {Name: "job", Kind: "noop"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewScheduler(tt.defs, noopKinds(), nil, nil); err == nil {
+				t.Errorf("NewScheduler(%+v) did not fail", tt.defs)
+			}
+		})
+	}
+}
+
+func TestNewSchedulerDefaultsOverlap(t *testing.T) {
+	s, err := NewScheduler([]JobDefinition{{Name: "job", Kind: "noop", Jitter: time.Minute}}, noopKinds(), nil, nil)
+	if err != nil {
+		t.Fatalf("NewScheduler error: %v", err)
+	}
+	if got := s.byName["job"].def.Overlap; got != domain.OverlapSkip {
+		t.Errorf("Overlap = %q, want %q", got, domain.OverlapSkip)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	tests := []struct {
+		name string
+		max  time.Duration
+	}{
+		{"sin jitter", 0},
+		{"negativo", -time.Minute},
+		{"un nanosegundo", time.Nanosecond},
+		{"un minuto", time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				got := jitter(tt.max)
+				if got < 0 || (tt.max > 0 && got >= tt.max) || (tt.max <= 0 && got != 0) {
+					t.Fatalf("jitter(%v) = %v, want a delay in [0, max)", tt.max, got)
+				}
+			}
+		})
+	}
+}
+
+// noopKinds devuelve un único tipo de trabajo que no hace nada.
+func noopKinds() map[string]JobFunc {
+	return map[string]JobFunc{"noop": func(ctx context.Context) error { return nil }}
+}
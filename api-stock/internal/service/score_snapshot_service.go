@@ -0,0 +1,34 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"log"
+	"time"
+)
+
+// scoreSnapshotService implementa domain.ScoreSnapshotService con el score del servicio de recomendaciones.
+type scoreSnapshotService struct {
+	recommendations domain.RecommendationService   // Calcula el score de cada ticker
+	snapshots       domain.ScoreSnapshotRepository // Fotos diarias guardadas
+}
+
+// NewScoreSnapshotService crea el servicio que guarda la foto diaria de los scores.
+func NewScoreSnapshotService(recommendations domain.RecommendationService, snapshots domain.ScoreSnapshotRepository) domain.ScoreSnapshotService {
+	return &scoreSnapshotService{recommendations: recommendations, snapshots: snapshots}
+}
+
+// SnapshotScores calcula los scores actuales y los guarda como la foto del día UTC en curso; si el
+// trabajo se ejecuta varias veces en el mismo día, queda la última.
+func (s *scoreSnapshotService) SnapshotScores(ctx context.Context) error {
+	scores, err := s.recommendations.ScoreTickers(ctx)
+	if err != nil {
+		return err
+	}
+	day := time.Now().UTC()
+	if err := s.snapshots.SaveScoreSnapshot(ctx, day, scores); err != nil {
+		return err
+	}
+	log.Printf("Saved score snapshot for %s: %d tickers", day.Format(time.DateOnly), len(scores))
+	return nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"io"
+)
+
+// securityService implementa domain.SecurityService.
+type securityService struct {
+	repo domain.SecurityRepository
+}
+
+// NewSecurityService crea el servicio de administración del registro maestro de valores.
+func NewSecurityService(repo domain.SecurityRepository) domain.SecurityService {
+	return &securityService{repo: repo}
+}
+
+// ListSecurities lista los valores que cumplen el filtro.
+func (s *securityService) ListSecurities(ctx context.Context, filter domain.SecurityFilter) ([]domain.Security, error) {
+	return s.repo.ListSecurities(ctx, filter)
+}
+
+// ImportCSV valida el CSV completo antes de escribir, de modo que un archivo con errores no se importa a medias.
+func (s *securityService) ImportCSV(ctx context.Context, r io.Reader) (int, error) {
+	securities, err := domain.ParseSecuritiesCSV(r)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.repo.UpsertSecurities(ctx, securities); err != nil {
+		return 0, err
+	}
+	return len(securities), nil
+}
@@ -3,23 +3,29 @@ package service
 import (
 	"api-stock/internal/domain"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 )
 
 // stockService implementa la interfaz domain.StockService
 // y actúa como capa de servicio para manejar la lógica relacionada con acciones y recomendaciones.
 type stockService struct {
-	repo domain.StockRepository
+	repo       domain.StockRepository
+	securities domain.SecurityRepository // registro maestro de valores (metadatos y sectores)
+	ratings    *domain.RatingNormalizer  // traduce el filtro de calificación a su valor canónico
 }
 
-// NewStockService es el constructor que recibe un repositorio y retorna una instancia de stockService.
-func NewStockService(repo domain.StockRepository) domain.StockService {
-	return &stockService{repo: repo}
+// NewStockService es el constructor que recibe los repositorios y el normalizador de calificaciones
+// y retorna una instancia de stockService.
+func NewStockService(repo domain.StockRepository, securities domain.SecurityRepository, ratings *domain.RatingNormalizer) domain.StockService {
+	return &stockService{repo: repo, securities: securities, ratings: ratings}
 }
 
-// GetRecommendations obtiene recomendaciones para un ticker específico,
+// GetRecommendations obtiene recomendaciones filtradas por ticker, calificación, sector y proveedor,
 // paginando resultados según page y limit.
 // Se validan los parámetros para evitar valores fuera de rango.
-func (s *stockService) GetRecommendations(ctx context.Context, ticker string, page, limit int) ([]domain.StockRecommendation, int, error) {
+func (s *stockService) GetRecommendations(ctx context.Context, ticker, rating, sector, source string, page, limit int) ([]domain.StockRecommendation, int, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -27,13 +33,73 @@ func (s *stockService) GetRecommendations(ctx context.Context, ticker string, pa
 		limit = 50
 	}
 
+	filter := domain.RecommendationFilter{
+		Ticker: ticker,
+		Sector: strings.TrimSpace(sector),
+		Source: strings.ToLower(strings.TrimSpace(source)),
+	}
+	canonical, err := ratingFilter(s.ratings, rating)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.Rating = canonical
+
 	// Delegamos la obtención de datos al repositorio
-	return s.repo.GetRecommendations(ctx, ticker, page, limit)
+	return s.repo.GetRecommendations(ctx, filter, page, limit)
+}
+
+// ratingFilter traduce el filtro de calificación a su valor canónico. Acepta tanto códigos canónicos
+// como alias ("outperform" -> buy); "unknown" filtra las no clasificadas y vacío no filtra.
+func ratingFilter(ratings *domain.RatingNormalizer, raw string) (domain.Rating, error) {
+	if raw == "" {
+		return "", nil
+	}
+	rating := ratings.Normalize(raw)
+	if rating == domain.RatingUnknown && !strings.EqualFold(raw, string(domain.RatingUnknown)) {
+		return "", fmt.Errorf("%w: %s", domain.ErrUnknownRating, raw)
+	}
+	return rating, nil
+}
+
+// GetRecommendation obtiene una recomendación por su ID.
+func (s *stockService) GetRecommendation(ctx context.Context, id string) (*domain.StockRecommendation, error) {
+	return s.repo.GetRecommendation(ctx, strings.ToLower(strings.TrimSpace(id)))
+}
+
+// GetRecommendationRevisions obtiene las versiones anteriores de una recomendación y calcula, para cada
+// una, los campos que cambiaron respecto de la versión que la reemplazó (la siguiente revisión o la actual).
+// Una recomendación eliminada conserva su historial.
+func (s *stockService) GetRecommendationRevisions(ctx context.Context, id string) ([]domain.RecommendationRevision, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	current, err := s.repo.GetRecommendation(ctx, id)
+	if err != nil && !errors.Is(err, domain.ErrRecommendationNotFound) {
+		return nil, err
+	}
+	revisions, err := s.repo.GetRecommendationRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil && len(revisions) == 0 {
+		return nil, domain.ErrRecommendationNotFound
+	}
+
+	next := current // Versión que reemplazó a la revisión en curso; nil si la recomendación ya no existe
+	for i := range revisions {
+		revisions[i].ChangedFields = []string{}
+		if next != nil {
+			revisions[i].ChangedFields = domain.ChangedFields(revisions[i].Recommendation, *next)
+		}
+		next = &revisions[i].Recommendation
+	}
+	return revisions, nil
 }
 
-// GetAvailableTickers retorna una lista con todos los tickers disponibles en el repositorio.
-func (s *stockService) GetAvailableTickers(ctx context.Context) ([]string, error) {
-	return s.repo.GetAvailableTickers(ctx)
+// GetAvailableTickers retorna los valores activos que tienen recomendaciones, con sus metadatos.
+func (s *stockService) GetAvailableTickers(ctx context.Context, sector string) ([]domain.Security, error) {
+	return s.securities.ListSecurities(ctx, domain.SecurityFilter{
+		Sector:              strings.TrimSpace(sector),
+		WithRecommendations: true,
+	})
 }
 
 // HealthCheck verifica el estado de la conexión con el repositorio.
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+)
+
+// syncRunService implementa domain.SyncRunService sobre el historial de sincronizaciones.
+type syncRunService struct {
+	repo domain.SyncRunRepository
+}
+
+// NewSyncRunService crea el servicio de consulta del historial de sincronizaciones.
+func NewSyncRunService(repo domain.SyncRunRepository) domain.SyncRunService {
+	return &syncRunService{repo: repo}
+}
+
+// ListSyncRuns lista las ejecuciones más recientes, limitando el resultado a 1..500.
+func (s *syncRunService) ListSyncRuns(ctx context.Context, limit int) ([]domain.SyncRun, error) {
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListSyncRuns(ctx, limit)
+}
+
+// GetSyncRun obtiene una ejecución por ID.
+func (s *syncRunService) GetSyncRun(ctx context.Context, id string) (*domain.SyncRun, error) {
+	return s.repo.GetSyncRun(ctx, id)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"api-stock/internal/domain"
+	"context"
+	"sync"
+)
+
+// syncRunTrackerSize es el número de ejecuciones recientes que el tracker conserva en memoria.
+const syncRunTrackerSize = 100
+
+// trackedJobKey es la clave de contexto con la que el scheduler indica a qué ejecución de trabajo
+// pertenecen las sincronizaciones.
+type trackedJobKey struct{}
+
+// withTrackedJob devuelve un contexto cuyas sincronizaciones el tracker asocia a la ejecución de trabajo key.
+func withTrackedJob(ctx context.Context, key int) context.Context {
+	return context.WithValue(ctx, trackedJobKey{}, key)
+}
+
+// trackedRun es el último estado conocido de una ejecución y la ejecución de trabajo a la que pertenece.
+type trackedRun struct {
+	job int // 0 si se inició fuera de un trabajo del scheduler
+	run domain.SyncRun
+}
+
+// SyncRunTracker implementa domain.SyncRunRepository envolviendo otro repositorio: además de persistir,
+// conserva en memoria el último estado de las ejecuciones que inicia este proceso, para mostrar el
+// progreso de los trabajos en curso sin consultar la base de datos.
+type SyncRunTracker struct {
+	domain.SyncRunRepository // Repositorio que persiste las ejecuciones
+
+	mu   sync.Mutex
+	runs []trackedRun // Ejecuciones recientes en orden de inicio
+}
+
+// NewSyncRunTracker envuelve el repositorio del historial de sincronizaciones.
+func NewSyncRunTracker(runs domain.SyncRunRepository) *SyncRunTracker {
+	return &SyncRunTracker{SyncRunRepository: runs}
+}
+
+// StartSyncRun registra la ejecución y empieza a seguirla.
+func (t *SyncRunTracker) StartSyncRun(ctx context.Context, run *domain.SyncRun) error {
+	if err := t.SyncRunRepository.StartSyncRun(ctx, run); err != nil {
+		return err
+	}
+
+	job, _ := ctx.Value(trackedJobKey{}).(int)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.runs = append(t.runs, trackedRun{job: job, run: *run})
+	if len(t.runs) > syncRunTrackerSize {
+		t.runs = t.runs[len(t.runs)-syncRunTrackerSize:]
+	}
+	return nil
+}
+
+// UpdateSyncRun actualiza el progreso en memoria y lo persiste.
+func (t *SyncRunTracker) UpdateSyncRun(ctx context.Context, run domain.SyncRun) error {
+	t.track(run)
+	return t.SyncRunRepository.UpdateSyncRun(ctx, run)
+}
+
+// FinishSyncRun actualiza el resultado en memoria y lo persiste.
+func (t *SyncRunTracker) FinishSyncRun(ctx context.Context, run domain.SyncRun) error {
+	t.track(run) // El resultado se ve aunque no se haya podido persistir
+	return t.SyncRunRepository.FinishSyncRun(ctx, run)
+}
+
+// runsFor devuelve el último estado de las ejecuciones de la ejecución de trabajo key, en orden de inicio.
+func (t *SyncRunTracker) runsFor(key int) []domain.SyncRun {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var runs []domain.SyncRun
+	for _, tracked := range t.runs {
+		if tracked.job == key {
+			runs = append(runs, tracked.run)
+		}
+	}
+	return runs
+}
+
+// track reemplaza el estado en memoria de la ejecución, si se sigue.
+func (t *SyncRunTracker) track(run domain.SyncRun) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := len(t.runs) - 1; i >= 0; i-- {
+		if t.runs[i].run.ID == run.ID {
+			t.runs[i].run = run
+			return
+		}
+	}
+}
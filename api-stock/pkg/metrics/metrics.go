@@ -6,6 +6,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -28,8 +29,129 @@ var (
 		},
 		[]string{"path"},
 	)
+
+	// Definición del contador de peticiones a la API externa, segmentado por proveedor y resultado final (success, error)
+	externalAPIRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "external_api_requests_total",
+			Help: "Total number of external API page requests by provider and final outcome",
+		},
+		[]string{"provider", "outcome"},
+	)
+
+	// Definición del contador de reintentos a la API externa, segmentado por proveedor y motivo (código HTTP o network)
+	externalAPIRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "external_api_retries_total",
+			Help: "Total number of external API request retries",
+		},
+		[]string{"provider", "reason"},
+	)
+
+	// Definición del contador del tiempo esperado por el limitador de peticiones a la API externa, segmentado por proveedor
+	externalAPIRateLimitWaitSeconds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "external_api_rate_limit_wait_seconds_total",
+			Help: "Total time spent waiting for the external API rate limiter",
+		},
+		[]string{"provider"},
+	)
+
+	// Presupuesto de peticiones de cada proveedor, leído al exponer las métricas
+	externalAPIBudgets = &budgetCollector{
+		budgets: make(map[string]func() Budget),
+		tokens: prometheus.NewDesc("external_api_rate_limit_tokens",
+			"Requests the external API rate limiter allows right now without waiting", []string{"provider"}, nil),
+		quota: prometheus.NewDesc("external_api_daily_quota_remaining",
+			"Requests left in the external API daily quota (only for providers with a quota)", []string{"provider"}, nil),
+	}
+
+	// Definición del contador de ejecuciones de los trabajos del worker, segmentado por trabajo y estado final (succeeded, failed, skipped)
+	workerJobRunsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_job_runs_total",
+			Help: "Total number of worker job runs by job and final status",
+		},
+		[]string{"job", "status"},
+	)
+
+	// Definición del histograma para la duración de las ejecuciones de los trabajos del worker, segmentado por trabajo
+	workerJobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "worker_job_duration_seconds",
+			Help:    "Duration of worker job runs",
+			Buckets: []float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600},
+		},
+		[]string{"job"},
+	)
 )
 
+// ObserveExternalAPIRequest registra el resultado final de una petición a la API externa (tras los reintentos)
+func ObserveExternalAPIRequest(provider, outcome string) {
+	externalAPIRequestsTotal.WithLabelValues(provider, outcome).Inc()
+}
+
+// ObserveExternalAPIRetry registra un reintento a la API externa y su motivo
+func ObserveExternalAPIRetry(provider, reason string) {
+	externalAPIRetriesTotal.WithLabelValues(provider, reason).Inc()
+}
+
+// ObserveRateLimitWait registra el tiempo que una petición a la API externa esperó su turno
+func ObserveRateLimitWait(provider string, wait time.Duration) {
+	externalAPIRateLimitWaitSeconds.WithLabelValues(provider).Add(wait.Seconds())
+}
+
+// Budget es el presupuesto de peticiones de un proveedor
+type Budget struct {
+	Tokens         float64 // Peticiones permitidas ya sin esperar
+	QuotaRemaining int     // Peticiones que quedan de la cuota diaria (-1 si no tiene cuota)
+}
+
+// RegisterExternalAPIBudget publica el presupuesto de peticiones de un proveedor; budget se consulta en cada lectura de las métricas
+// y debe volver enseguida, sin consultar la base de datos
+func RegisterExternalAPIBudget(provider string, budget func() Budget) {
+	externalAPIBudgets.mu.Lock()
+	defer externalAPIBudgets.mu.Unlock()
+	externalAPIBudgets.budgets[provider] = budget
+}
+
+// budgetCollector expone el presupuesto actual de cada proveedor registrado
+type budgetCollector struct {
+	mu      sync.Mutex
+	budgets map[string]func() Budget
+	tokens  *prometheus.Desc
+	quota   *prometheus.Desc
+}
+
+func (c *budgetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.tokens
+	ch <- c.quota
+}
+
+func (c *budgetCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	budgets := make(map[string]func() Budget, len(c.budgets))
+	for provider, budget := range c.budgets {
+		budgets[provider] = budget
+	}
+	c.mu.Unlock()
+
+	// Los presupuestos se leen sin el lock, para que un proveedor lento no bloquee los registros
+	for provider, budget := range budgets {
+		b := budget()
+		ch <- prometheus.MustNewConstMetric(c.tokens, prometheus.GaugeValue, b.Tokens, provider)
+		if b.QuotaRemaining >= 0 {
+			ch <- prometheus.MustNewConstMetric(c.quota, prometheus.GaugeValue, float64(b.QuotaRemaining), provider)
+		}
+	}
+}
+
+// ObserveJobRun registra el estado final y la duración de una ejecución de un trabajo del worker
+func ObserveJobRun(job, status string, duration time.Duration) {
+	workerJobRunsTotal.WithLabelValues(job, status).Inc()
+	workerJobDuration.WithLabelValues(job).Observe(duration.Seconds())
+}
+
 // Handler devuelve el manejador HTTP estándar para exponer las métricas Prometheus
 func Handler() http.Handler {
 	return promhttp.Handler()
@@ -62,4 +184,10 @@ func PrometheusMiddleware() gin.HandlerFunc {
 func Init() {
 	prometheus.MustRegister(httpRequestsTotal)
 	prometheus.MustRegister(httpRequestDuration)
+	prometheus.MustRegister(externalAPIRequestsTotal)
+	prometheus.MustRegister(externalAPIRetriesTotal)
+	prometheus.MustRegister(externalAPIRateLimitWaitSeconds)
+	prometheus.MustRegister(externalAPIBudgets)
+	prometheus.MustRegister(workerJobRunsTotal)
+	prometheus.MustRegister(workerJobDuration)
 }
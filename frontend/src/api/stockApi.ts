@@ -1,5 +1,5 @@
 // Importa el tipo `StockRecommendation` desde el archivo de tipos
-import type { StockRecommendation } from '../types/index.js'
+import type { Security, StockRecommendation } from '../types/index.js'
 
 // Define la URL base para la API, obtenida desde las variables de entorno (útil para cambiar entre local/desarrollo/producción)
 const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080/http/v1'
@@ -10,6 +10,7 @@ const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080
  * @param rating - calificación para filtrar (ej. "buy", "sell", etc.).
  * @param limit - número máximo de resultados por página.
  * @param page - número de página a solicitar.
+ * @param sector - sector del registro maestro para filtrar (opcional).
  * @returns objeto con array de recomendaciones y si hay más resultados.
  */
 export const fetchStocks = async (
@@ -17,10 +18,12 @@ export const fetchStocks = async (
   rating: string,
   limit: number,
   page: number,
+  sector = '',
 ): Promise<{ data: StockRecommendation[]; hasMore: boolean }> => {
   const params = new URLSearchParams()
   if (ticker) params.append('ticker', ticker) // Agrega el filtro por ticker si está definido
   if (rating) params.append('rating', rating) // Agrega el filtro por rating si está definido
+  if (sector) params.append('sector', sector) // Agrega el filtro por sector si está definido
   params.append('limit', limit.toString()) // Número de resultados por página
   params.append('page', page.toString()) // Número de página
 
@@ -60,11 +63,14 @@ export const fetchTopStocks = async (limit = 20): Promise<StockRecommendation[]>
 }
 
 /**
- * fetchTickers - Obtiene la lista de tickers disponibles.
- * @returns array de strings con los símbolos bursátiles.
+ * fetchTickers - Obtiene los valores disponibles con sus metadatos (empresa, bolsa, sector, industria).
+ * @param sector - sector para filtrar (opcional).
+ * @returns array de valores del registro maestro.
  */
-export const fetchTickers = async (): Promise<string[]> => {
-  const response = await fetch(`${API_BASE_URL}/recommendations/tickers`)
+export const fetchTickers = async (sector = ''): Promise<Security[]> => {
+  const params = new URLSearchParams()
+  if (sector) params.append('sector', sector)
+  const response = await fetch(`${API_BASE_URL}/recommendations/tickers?${params.toString()}`)
   if (!response.ok) {
     throw new Error('Failed to fetch tickers')
   }
@@ -1,9 +1,9 @@
 // Importamos defineStore de Pinia para crear el store
 import { defineStore } from 'pinia'
 // Importamos ref de Vue para crear variables reactivas
-import { ref } from 'vue'
+import { computed, ref } from 'vue'
 // Importamos tipos para mejorar la tipificación
-import type { StockRecommendation, StockRecommendationHistory } from '@/types'
+import type { Security, StockRecommendation, StockRecommendationHistory } from '@/types'
 // Importamos funciones API para obtener datos del backend
 import {
   fetchStocks as fetchStocksApi,
@@ -21,8 +21,12 @@ export const useStockStore = defineStore('stocks', () => {
   const currentStock = ref<StockRecommendation | null>(null)
   // Estado para las acciones top recomendadas
   const topStocks = ref<StockRecommendation[]>([])
-  // Estado para la lista de tickers (símbolos de acciones)
-  const tickers = ref<string[]>([])
+  // Estado para la lista de valores disponibles (ticker, empresa, sector...)
+  const tickers = ref<Security[]>([])
+  // Sectores distintos de los valores disponibles, ordenados alfabéticamente
+  const sectors = computed(() =>
+    [...new Set(tickers.value.map((security) => security.sector).filter(Boolean))].sort(),
+  )
   // Estado para saber si se está cargando algo
   const loading = ref(false)
   // Estado para guardar mensajes de error
@@ -38,6 +42,7 @@ export const useStockStore = defineStore('stocks', () => {
   const currentFilters = ref({
     ticker: '',
     rating: '',
+    sector: '',
     limit: 20,
   })
 
@@ -45,6 +50,7 @@ export const useStockStore = defineStore('stocks', () => {
   const fetchStocksAction = async (filter: {
     ticker: string
     rating: string
+    sector?: string
     limit: number
     page?: number
     append?: boolean // Nuevo parámetro para añadir datos o reemplazar
@@ -63,6 +69,7 @@ export const useStockStore = defineStore('stocks', () => {
         currentFilters.value = {
           ticker: filter.ticker,
           rating: filter.rating,
+          sector: filter.sector || '',
           limit: filter.limit,
         }
       } else {
@@ -71,7 +78,13 @@ export const useStockStore = defineStore('stocks', () => {
       }
 
       // Llamamos a la API para obtener las acciones
-      const response = await fetchStocksApi(filter.ticker, filter.rating, filter.limit, page)
+      const response = await fetchStocksApi(
+        filter.ticker,
+        filter.rating,
+        filter.limit,
+        page,
+        filter.sector,
+      )
 
       // Si agregamos y la página es mayor que 1, juntamos datos nuevos con existentes
       if (append && page > 1) {
@@ -123,6 +136,7 @@ export const useStockStore = defineStore('stocks', () => {
         currentFilters.value.rating,
         currentFilters.value.limit,
         nextPage,
+        currentFilters.value.sector,
       )
 
       // Añadimos los nuevos datos al array existente
@@ -141,6 +155,7 @@ export const useStockStore = defineStore('stocks', () => {
   const fetchStocksUnified = async (filter: {
     ticker: string
     rating: string
+    sector?: string
     limit: number
     page?: number
     append?: boolean
@@ -156,12 +171,19 @@ export const useStockStore = defineStore('stocks', () => {
         currentFilters.value = {
           ticker: filter.ticker,
           rating: filter.rating,
+          sector: filter.sector || '',
           limit: filter.limit,
         }
         currentPage.value = 1
       }
 
-      const response = await fetchStocksApi(filter.ticker, filter.rating, filter.limit, page)
+      const response = await fetchStocksApi(
+        filter.ticker,
+        filter.rating,
+        filter.limit,
+        page,
+        filter.sector,
+      )
 
       if (append && page > 1) {
         stocks.value = [...stocks.value, ...response.data]
@@ -228,6 +250,7 @@ export const useStockStore = defineStore('stocks', () => {
     currentStock,
     topStocks,
     tickers,
+    sectors,
     loading,
     error,
     hasMore,
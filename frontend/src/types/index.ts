@@ -1,16 +1,49 @@
+// Calificación canónica calculada por el backend a partir del texto libre del proveedor
+export type Rating = 'strong_sell' | 'sell' | 'hold' | 'buy' | 'strong_buy' | 'unknown'
+
+// Tipo de acción canónico calculado por el backend a partir del texto y de los cambios de calificación y precio
+export type ActionType =
+  | 'initiate'
+  | 'upgrade'
+  | 'downgrade'
+  | 'target_raised'
+  | 'target_lowered'
+  | 'reiterate'
+  | 'maintain'
+  | 'drop_coverage'
+  | 'unknown'
+
 // Tipo que representa una recomendación de acción
 export type StockRecommendation = {
+  id: string // Identificador estable de la recomendación (ticker, casa de bolsa, momento y acción)
+  source: string // Proveedor de datos que envió la recomendación (ejemplo: "default")
   ticker: string // Símbolo de la acción (ejemplo: "AAPL")
   company: string // Nombre de la empresa
   rating_from: string // Calificación inicial (ejemplo: "Hold")
   rating_to: string // Calificación final (ejemplo: "Buy")
+  normalized_rating_from: Rating // Calificación inicial normalizada
+  normalized_rating_to: Rating // Calificación final normalizada
   target_from: number // Precio objetivo mínimo recomendado
   target_to: number // Precio objetivo máximo recomendado
+  currency: string // Divisa de los precios objetivo (código ISO 4217, ejemplo: "USD")
   brokerage: string // Nombre de la casa de bolsa o analista que da la recomendación
+  brokerage_id?: string // ID canónico de la casa de bolsa (ausente si no se reconoció)
   action: string // Tipo de acción recomendada (ejemplo: "buy", "sell")
+  action_type: ActionType // Tipo de acción normalizado
   time: string // Fecha o momento en que se hizo la recomendación (string ISO)
 }
 
+// Valor del registro maestro (ticker, empresa y clasificación)
+export type Security = {
+  ticker: string // Símbolo de la acción
+  company: string // Nombre de la empresa
+  exchange: string // Bolsa donde cotiza (ejemplo: "NASDAQ")
+  sector: string // Sector económico
+  industry: string // Industria dentro del sector
+  active: boolean // Si el valor sigue cotizando
+  updated_at: string // Última actualización de los metadatos (string ISO)
+}
+
 // Interfaz para la respuesta de la API que devuelve recomendaciones
 export interface APIResponse {
   items: StockRecommendation[] // Lista de recomendaciones recibidas